@@ -3,16 +3,35 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/jomei/notionapi"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/r-erema/vocaboost/internal/application"
 	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/cloze"
 	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+	"github.com/r-erema/vocaboost/internal/application/service/imagefetch"
+	"github.com/r-erema/vocaboost/internal/application/service/imagequality"
 	"github.com/r-erema/vocaboost/internal/application/service/images"
+	"github.com/r-erema/vocaboost/internal/application/service/imagestorage"
+	"github.com/r-erema/vocaboost/internal/application/service/learnedsync"
+	"github.com/r-erema/vocaboost/internal/application/service/pronunciation"
+	"github.com/r-erema/vocaboost/internal/application/service/safehttp"
+	"github.com/r-erema/vocaboost/internal/application/service/scheduler"
 	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
 	"github.com/r-erema/vocaboost/internal/application/service/textparser"
+	"github.com/r-erema/vocaboost/internal/application/service/translation"
+	"github.com/r-erema/vocaboost/internal/application/service/wordfrequency"
 	"github.com/r-erema/vocaboost/internal/port"
 	"google.golang.org/api/customsearch/v1"
 	"google.golang.org/api/option"
@@ -23,15 +42,186 @@ const (
 	envVarRedisUsername = "REDIS_USERNAME"
 	envVarRedisPassword = "REDIS_PASSWORD"
 
-	envVarWordsKey = "WORDS_API_KEY"
+	envVarWordsKey     = "WORDS_API_KEY"
+	envVarWordsBaseURL = "WORDS_API_BASE_URL"
+	envVarWordsTimeout = "WORDS_API_TIMEOUT"
+
+	envVarDictionaryProviders = "DICTIONARY_PROVIDERS"
+	envVarWordNetDictPath     = "WORDNET_DICT_PATH"
 
 	envVarGoogleSearchKey      = "GOOGLE_SEARCH_API_KEY"
 	envVarGoogleSearchEngineID = "GOOGLE_SEARCH_ENGINE_ID"
+	envVarGoogleSearchQuota    = "GOOGLE_SEARCH_DAILY_QUOTA"
+	envVarGoogleSearchReserve  = "GOOGLE_SEARCH_QUOTA_RESERVE"
+	envVarGoogleSearchTimezone = "GOOGLE_SEARCH_QUOTA_TIMEZONE"
+	envVarImagesCacheTTL       = "IMAGES_CACHE_TTL"
+
+	envVarImagesProviders     = "IMAGES_PROVIDERS"
+	envVarImagesProvidersMode = "IMAGES_PROVIDERS_MODE"
+	envVarPixabayKey          = "PIXABAY_API_KEY"
+	envVarUnsplashKey         = "UNSPLASH_ACCESS_KEY"
+	envVarOpenverseToken      = "OPENVERSE_TOKEN"
+	envVarDailyQuotaSuffix    = "_DAILY_QUOTA"
+	envVarImagesQueries       = "IMAGES_QUERY_TEMPLATES"
+	envVarImagesSafeSearch    = "IMAGES_SAFE_SEARCH"
+
+	defaultGoogleSearchQuota    = 100
+	defaultGoogleSearchReserve  = 0
+	defaultGoogleSearchTimezone = "America/Los_Angeles"
+	defaultImagesCacheTTL       = 30 * 24 * time.Hour
+	imagesSearchTimeout         = 30 * time.Second
+
+	envVarTranslationProvider       = "TRANSLATION_PROVIDER"
+	envVarTranslationTargetLanguage = "TRANSLATION_TARGET_LANGUAGE"
+	envVarLibreTranslateURL         = "LIBRETRANSLATE_URL"
+	envVarLibreTranslateAPIKey      = "LIBRETRANSLATE_API_KEY"
+	envVarBilingualDictionaryPath   = "BILINGUAL_DICTIONARY_PATH"
+	libreTranslateTimeout           = 30 * time.Second
+
+	envVarTTSCommand     = "TTS_COMMAND"
+	envVarAudioStorage   = "AUDIO_STORAGE_PATH"
+	envVarPublicBaseURL  = "PUBLIC_BASE_URL"
+	defaultAudioStorage  = "./data/audio"
+	defaultPublicBaseURL = "http://localhost:8080"
+
+	envVarImageStorage      = "IMAGE_STORAGE"
+	envVarImageStoragePath  = "IMAGE_STORAGE_PATH"
+	envVarThumbnailMaxSide  = "THUMBNAIL_MAX_SIDE"
+	envVarImageMaxPixels    = "IMAGE_MAX_PIXELS"
+	envVarImageCacheSize    = "IMAGE_DOWNLOAD_CACHE_MB"
+	envVarS3Endpoint        = "S3_ENDPOINT"
+	envVarS3AccessKey       = "S3_ACCESS_KEY"
+	envVarS3SecretKey       = "S3_SECRET_KEY"
+	envVarS3Bucket          = "S3_BUCKET"
+	envVarS3UseSSL          = "S3_USE_SSL"
+	envVarS3PublicBaseURL   = "S3_PUBLIC_BASE_URL"
+	defaultImageStoragePath = "./data/images"
+	defaultThumbnailMaxSide = 800
+	defaultImageMaxPixels   = 40_000_000
+	defaultImageCacheSize   = 200
+	imageDownloadMaxBytes   = 10 << 20
+	imageDownloadTimeout    = 30 * time.Second
+	imageDownloadCacheTTL   = 2 * time.Hour
+	// offeredURLsTTL is how long the media of a look up can be picked for cards.
+	offeredURLsTTL    = 24 * time.Hour
+	imageStorageLocal = "local"
+	imageStorageS3    = "s3"
+
+	envVarImageQualityFilter     = "IMAGE_QUALITY_FILTER"
+	envVarImageMinWidth          = "IMAGE_MIN_WIDTH"
+	envVarImageMinHeight         = "IMAGE_MIN_HEIGHT"
+	envVarImageMaxAspectRatio    = "IMAGE_MAX_ASPECT_RATIO"
+	envVarImageDuplicateDistance = "IMAGE_DUPLICATE_DISTANCE"
+	defaultImageMinWidth         = 200
+	defaultImageMinHeight        = 200
+	defaultImageMaxAspectRatio   = 3.0
+	defaultImageDuplicateDist    = 10
+	imageFilterConcurrency       = 8
 
 	envVarNotionKey        = "NOTION_API_KEY"
 	envVarNotionDatabaseID = "NOTION_DATABASE_ID"
+	envVarNotionExisting   = "NOTION_EXISTING_PAGES"
+	defaultNotionExisting  = spacedrepetition.ExistingPagesSkip
+
+	envVarNotionTitleProperty         = "NOTION_PROPERTY_TITLE"
+	envVarNotionPartOfSpeechProperty  = "NOTION_PROPERTY_PART_OF_SPEECH"
+	envVarNotionSourceTextProperty    = "NOTION_PROPERTY_SOURCE_TEXT"
+	envVarNotionDateAddedProperty     = "NOTION_PROPERTY_DATE_ADDED"
+	envVarNotionFrequencyRankProperty = "NOTION_PROPERTY_FREQUENCY_RANK"
+	envVarNotionTagsProperty          = "NOTION_PROPERTY_TAGS"
+	envVarNotionStatusProperty        = "NOTION_PROPERTY_STATUS"
+	envVarNotionNewStatus             = "NOTION_NEW_STATUS"
+	envVarNotionLearnedProperty       = "NOTION_PROPERTY_LEARNED"
+	envVarNotionLearnedStatuses       = "NOTION_LEARNED_STATUSES"
+	defaultNotionTitleProperty        = "Name"
+	defaultNotionNewStatus            = "New"
+	notionSchemaValidationTimeout     = 30 * time.Second
+
+	envVarNotionRequestsPerSecond  = "NOTION_REQUESTS_PER_SECOND"
+	envVarNotionMaxRetries         = "NOTION_MAX_RETRIES"
+	defaultNotionRequestsPerSecond = 3
+	defaultNotionMaxRetries        = 5
+	notionRetryBackoff             = time.Second
+	notionRequestTimeout           = 60 * time.Second
+
+	envVarNotionLayoutPath = "NOTION_LAYOUT_PATH"
+
+	envVarNotionSyncInterval = "NOTION_SYNC_INTERVAL"
+	// commandSyncLearnedWords runs the Notion learned words sync once instead of starting the server.
+	commandSyncLearnedWords = "sync-learned-words"
+
+	envVarSpacedRepetitionTarget  = "SPACED_REPETITION_TARGET"
+	envVarExportStoragePath       = "EXPORT_STORAGE_PATH"
+	envVarAnkiDeckName            = "ANKI_DECK_NAME"
+	envVarAnkiTags                = "ANKI_TAGS"
+	envVarAnkiConnectURL          = "ANKICONNECT_URL"
+	defaultAnkiConnectURL         = "http://127.0.0.1:8765"
+	ankiConnectTimeout            = 30 * time.Second
+	defaultSpacedRepetitionTarget = spacedRepetitionTargetNotion
+	defaultExportStoragePath      = "./data/exports"
+	defaultAnkiDeckName           = "Vocaboost"
+	spacedRepetitionTargetNotion  = "notion"
+	spacedRepetitionTargetAnki    = "anki"
+	spacedRepetitionAnkiConnect   = "ankiconnect"
+	spacedRepetitionTargetFile    = "file"
+	spacedRepetitionReviews       = "reviews"
+
+	envVarReviewScheduler          = "REVIEW_SCHEDULER"
+	envVarFSRSRequestedRetention   = "FSRS_REQUESTED_RETENTION"
+	envVarReviewMatureIntervalDays = "REVIEW_MATURE_INTERVAL_DAYS"
+	reviewSchedulerSM2             = "sm2"
+	reviewSchedulerFSRS            = "fsrs"
+	defaultReviewScheduler         = reviewSchedulerSM2
+	defaultFSRSRequestedRetention  = 0.9
+	defaultReviewMatureInterval    = 21
+
+	envVarFlashcardsFilePreset         = "FLASHCARDS_FILE_PRESET"
+	envVarFlashcardsFileColumns        = "FLASHCARDS_FILE_COLUMNS"
+	envVarFlashcardsFileFieldSeparator = "FLASHCARDS_FILE_FIELD_SEPARATOR"
+	envVarFlashcardsFileRowSeparator   = "FLASHCARDS_FILE_ROW_SEPARATOR"
+	envVarFlashcardsFileMarkup         = "FLASHCARDS_FILE_MARKUP"
+	envVarFlashcardsFileHeader         = "FLASHCARDS_FILE_HEADER"
+	envVarFlashcardsFileQuote          = "FLASHCARDS_FILE_QUOTE"
+	envVarFlashcardsFileEscapeFormulas = "FLASHCARDS_FILE_ESCAPE_FORMULAS"
+	defaultFlashcardsFilePreset        = "csv"
+
+	envVarWordFrequencyListPath = "WORD_FREQUENCY_LIST_PATH"
 
-	redisWordsDB = 0
+	envVarClozeCards = "CLOZE_CARDS"
+
+	redisWordsDB  = 0
+	redisImagesDB = 1
+
+	userAgent = "vocaboost"
+
+	dictionaryProviderWordsAPI       = "wordsapi"
+	dictionaryProviderFreeDictionary = "freedictionary"
+	dictionaryProviderWordNet        = "wordnet"
+	defaultDictionaryProviders       = dictionaryProviderWordsAPI
+	freeDictionaryTimeout            = 10 * time.Second
+
+	translationProviderLibreTranslate      = "libretranslate"
+	translationProviderBilingualDictionary = "dictionary"
+
+	imagesProviderGoogle      = "google"
+	imagesProviderPixabay     = "pixabay"
+	imagesProviderUnsplash    = "unsplash"
+	imagesProviderOpenverse   = "openverse"
+	imagesProviderWikimedia   = "wikimedia"
+	defaultImagesProviders    = imagesProviderGoogle
+	imagesProvidersModeRotate = "roundrobin"
+	defaultProviderDailyQuota = 0
+	defaultImagesQueries      = "{word}:4;{word} meaning:4"
+	defaultImagesSafeSearch   = images.SafeSearchStrict
+	imagesQueriesSeparator    = ";"
+	imagesQueryQuotaSeparator = ":"
+
+	// The dictionary offers more definitions and examples than a card gets by default,
+	// so users have spare ones to pick on the preview page.
+	dictionaryMaxDefinitions = 10
+	dictionaryMaxExamples    = 10
+	cardMaxDefinitions       = 4
+	cardMaxExamples          = 4
 )
 
 type config struct {
@@ -40,12 +230,80 @@ type config struct {
 	redisPassword,
 
 	wordsAPIKey,
+	wordsAPIBaseURL,
+	wordNetDictPath,
 
 	googleSearchAPIKey,
 	googleSearchEngineID,
+	pixabayAPIKey,
+	unsplashAccessKey,
+	openverseToken,
+	imagesProvidersMode,
+
+	reviewScheduler,
+	exportStoragePath,
+	ankiDeckName,
+	ankiConnectURL,
 
 	notionAPIKey,
-	notionDatabaseID string
+	notionDatabaseID,
+
+	translationProvider,
+	translationTargetLanguage,
+	libreTranslateURL,
+	libreTranslateAPIKey,
+	bilingualDictionaryPath,
+
+	ttsCommand,
+	audioStoragePath,
+	publicBaseURL,
+
+	imageStorage,
+	imageStoragePath,
+	s3Endpoint,
+	s3AccessKey,
+	s3SecretKey,
+	s3Bucket,
+	s3PublicBaseURL string
+	s3UseSSL         bool
+	thumbnailMaxSide int64
+	imageMaxPixels   int64
+	imageCacheSize   int64
+
+	imageQualityFilter bool
+	clozeCards         bool
+	imageMinWidth,
+	imageMinHeight,
+	imageDuplicateDistance int64
+	imageMaxAspectRatio float64
+
+	dictionaryProviders,
+	imagesProviders []string
+	wordsAPITimeout time.Duration
+
+	googleSearchQuota,
+	googleSearchReserve int64
+	googleSearchLocation *time.Location
+	imagesCacheTTL       time.Duration
+	imagesQueries        []*images.QueryTemplate
+	imagesSafeSearch     images.SafeSearch
+
+	notionExistingPages     spacedrepetition.ExistingPages
+	notionProperties        *spacedrepetition.NotionProperties
+	notionLayout            *spacedrepetition.NotionLayout
+	notionRequestsPerSecond float64
+	notionMaxRetries        int64
+	notionSync              bool
+	notionSyncInterval      time.Duration
+
+	spacedRepetitionTargets []string
+	ankiTags                []string
+	flashcardsFile          *spacedrepetition.TabularFormat
+
+	fsrsRequestedRetention float64
+	reviewMatureInterval   int64
+
+	wordFrequencyListPath string
 }
 
 func main() {
@@ -54,32 +312,79 @@ func main() {
 	web := gin.Default()
 	web.LoadHTMLGlob("./html_template/*")
 	web.StaticFile("/favicon.ico", "./assets/favicon.ico")
+	web.StaticFile(port.APIv1SpecHTTPPath, "./assets/openapi.yaml")
 
 	if err := web.SetTrustedProxies(nil); err != nil {
 		log.Panicf("setting trusted proxies error: %s", err)
 	}
 
-	googleCustomSearchService, err := customsearch.NewService(context.Background(), option.WithAPIKey(cfg.googleSearchAPIKey))
-	if err != nil {
-		log.Panicf("custom search service creation error: %s", err)
+	imagesRedis := redisClient(cfg.redisHost, cfg.redisUsername, cfg.redisPassword, redisImagesDB)
+	wordsRedis := redisClient(cfg.redisHost, cfg.redisUsername, cfg.redisPassword, redisWordsDB)
+	cardsRepo := repository.NewRedisCardsRepo(wordsRedis)
+	wordsRepo := repository.NewRedisWordsRepo(wordsRedis)
+	offeredURLsRepo := repository.NewRedisOfferedURLsRepo(imagesRedis, offeredURLsTTL)
+
+	// The upload target and the learned words sync share the Notion service, so their requests are throttled together.
+	notionService := notionIfUsed(cfg)
+
+	if len(os.Args) > 1 && os.Args[1] == commandSyncLearnedWords {
+		syncLearnedWords(cfg, notionService, wordsRepo, repository.NewRedisSyncCursorsRepo(wordsRedis))
+
+		return
 	}
 
+	if cfg.notionSync && cfg.notionSyncInterval > 0 {
+		go learnedsync.NewSync(notionService, wordsRepo, repository.NewRedisSyncCursorsRepo(wordsRedis)).
+			RunPeriodically(context.Background(), cfg.notionSyncInterval)
+	}
+
+	textParser := &textparser.V1{}
+	fetcher := imageFetcher(cfg)
+	cardsScheduler := reviewScheduler(cfg)
 	httpHandler := port.NewHTTPHandler(
-		&textparser.V1{},
-		repository.NewRedisWordsRepo(
-			redisClient(cfg.redisHost, cfg.redisUsername, cfg.redisPassword, redisWordsDB),
+		application.NewCounts(wordsRepo, cardsRepo),
+		application.NewExtractUnknownWords(textParser, wordsRepo),
+		application.NewTriageWords(wordsRepo),
+		application.NewLookUpWords(
+			dictionaryChain(cfg),
+			images.NewRedisCache(imagesProviders(cfg, imagesRedis), imagesRedis, cfg.imagesCacheTTL, cfg.imagesSafeSearch),
+			cfg.imagesQueries,
+			imageFilter(cfg, fetcher),
+			textParser,
+			offeredURLsRepo,
+			cardMaxDefinitions,
+			cardMaxExamples,
 		),
-		spacedrepetition.NewNotion(
-			notionapi.NewClient(notionapi.Token(cfg.notionAPIKey)), notionapi.DatabaseID(cfg.notionDatabaseID),
+		application.NewBuildCards(
+			offeredURLsRepo,
+			translator(cfg),
+			pronunciationService(cfg),
+			wordFrequency(cfg),
+			clozeGenerator(cfg),
+			imageStorage(cfg, fetcher),
 		),
-		dictionary.NewWordsAPI(cfg.wordsAPIKey),
-		images.NewGoogleCustomSearch(googleCustomSearchService, cfg.googleSearchEngineID),
+		application.NewPublishCards(spacedRepetition(cfg, cardsRepo, notionService)),
+		application.NewNextReview(cardsRepo, cardsScheduler),
+		application.NewGradeReview(cardsRepo, cardsScheduler, int(cfg.reviewMatureInterval)),
 	)
 
+	web.Static(port.AudioHTTPPath, cfg.audioStoragePath)
+	web.Static(port.ImagesHTTPPath, cfg.imageStoragePath)
+	web.Static(port.ExportsHTTPPath, cfg.exportStoragePath)
+
 	web.GET(port.IndexHTTPPath, httpHandler.Index)
 	web.POST(port.IndexHTTPPath, httpHandler.SplitTextToWords)
 	web.POST(port.SaveWordsHTTPPath, httpHandler.SaveWords)
+	web.POST(port.PreviewWordsHTTPPath, httpHandler.PreviewWords)
 	web.POST(port.UploadSpacedRepetitionHTTPPath, httpHandler.UploadToSpacedRepetitionService)
+	web.GET(port.ReviewHTTPPath, httpHandler.Review)
+	web.POST(port.ReviewHTTPPath, httpHandler.GradeReview)
+
+	web.GET(port.APIv1CountsHTTPPath, httpHandler.APICounts)
+	web.POST(port.APIv1ExtractWordsHTTPPath, httpHandler.APIExtractWords)
+	web.POST(port.APIv1TriageWordsHTTPPath, httpHandler.APITriageWords)
+	web.POST(port.APIv1LookUpWordsHTTPPath, httpHandler.APILookUpWords)
+	web.POST(port.APIv1UploadWordsHTTPPath, httpHandler.APIUploadWords)
 
 	if err := web.Run(); err != nil {
 		log.Panicf("server runnning error: %s", err)
@@ -94,10 +399,87 @@ func configFromENVs() config {
 		redisUsername:        "",
 		redisPassword:        "",
 		wordsAPIKey:          "",
+		wordsAPIBaseURL:      os.Getenv(envVarWordsBaseURL),
+		wordNetDictPath:      os.Getenv(envVarWordNetDictPath),
 		googleSearchAPIKey:   "",
 		googleSearchEngineID: "",
-		notionAPIKey:         "",
-		notionDatabaseID:     "",
+		pixabayAPIKey:        "",
+		unsplashAccessKey:    "",
+		openverseToken:       os.Getenv(envVarOpenverseToken),
+		imagesProvidersMode:  os.Getenv(envVarImagesProvidersMode),
+		spacedRepetitionTargets: strings.Split(
+			envOrDefault(envVarSpacedRepetitionTarget, defaultSpacedRepetitionTarget), ",",
+		),
+		exportStoragePath:         envOrDefault(envVarExportStoragePath, defaultExportStoragePath),
+		ankiDeckName:              envOrDefault(envVarAnkiDeckName, defaultAnkiDeckName),
+		ankiConnectURL:            envOrDefault(envVarAnkiConnectURL, defaultAnkiConnectURL),
+		ankiTags:                  listFromENV(envVarAnkiTags),
+		flashcardsFile:            nil,
+		reviewScheduler:           envOrDefault(envVarReviewScheduler, defaultReviewScheduler),
+		fsrsRequestedRetention:    float64FromENV(envVarFSRSRequestedRetention, defaultFSRSRequestedRetention),
+		reviewMatureInterval:      int64FromENV(envVarReviewMatureIntervalDays, defaultReviewMatureInterval),
+		notionAPIKey:              "",
+		notionDatabaseID:          "",
+		translationProvider:       "",
+		translationTargetLanguage: "",
+		libreTranslateURL:         "",
+		libreTranslateAPIKey:      "",
+		bilingualDictionaryPath:   "",
+		ttsCommand:                os.Getenv(envVarTTSCommand),
+		audioStoragePath:          envOrDefault(envVarAudioStorage, defaultAudioStorage),
+		publicBaseURL:             envOrDefault(envVarPublicBaseURL, defaultPublicBaseURL),
+		imageStorage:              os.Getenv(envVarImageStorage),
+		imageStoragePath:          envOrDefault(envVarImageStoragePath, defaultImageStoragePath),
+		s3Endpoint:                "",
+		s3AccessKey:               "",
+		s3SecretKey:               "",
+		s3Bucket:                  "",
+		s3PublicBaseURL:           "",
+		s3UseSSL:                  os.Getenv(envVarS3UseSSL) == "true",
+		thumbnailMaxSide:          int64FromENV(envVarThumbnailMaxSide, defaultThumbnailMaxSide),
+		imageMaxPixels:            int64FromENV(envVarImageMaxPixels, defaultImageMaxPixels),
+		imageCacheSize:            int64FromENV(envVarImageCacheSize, defaultImageCacheSize),
+		imageQualityFilter:        envOrDefault(envVarImageQualityFilter, "true") == "true",
+		clozeCards:                envOrDefault(envVarClozeCards, "true") == "true",
+		imageMinWidth:             int64FromENV(envVarImageMinWidth, defaultImageMinWidth),
+		imageMinHeight:            int64FromENV(envVarImageMinHeight, defaultImageMinHeight),
+		imageDuplicateDistance:    int64FromENV(envVarImageDuplicateDistance, defaultImageDuplicateDist),
+		imageMaxAspectRatio:       float64FromENV(envVarImageMaxAspectRatio, defaultImageMaxAspectRatio),
+		dictionaryProviders:       nil,
+		imagesProviders:           strings.Split(envOrDefault(envVarImagesProviders, defaultImagesProviders), ","),
+		wordsAPITimeout:           durationFromENV(envVarWordsTimeout, 0),
+		googleSearchQuota:         int64FromENV(envVarGoogleSearchQuota, defaultGoogleSearchQuota),
+		googleSearchReserve:       int64FromENV(envVarGoogleSearchReserve, defaultGoogleSearchReserve),
+		googleSearchLocation:      nil,
+		imagesCacheTTL:            durationFromENV(envVarImagesCacheTTL, defaultImagesCacheTTL),
+		imagesQueries:             imagesQueryTemplates(envOrDefault(envVarImagesQueries, defaultImagesQueries)),
+		imagesSafeSearch:          "",
+		notionExistingPages:       "",
+		notionProperties: spacedrepetition.NewNotionProperties(
+			envOrDefault(envVarNotionTitleProperty, defaultNotionTitleProperty),
+			os.Getenv(envVarNotionPartOfSpeechProperty),
+			os.Getenv(envVarNotionSourceTextProperty),
+			os.Getenv(envVarNotionDateAddedProperty),
+			os.Getenv(envVarNotionFrequencyRankProperty),
+			os.Getenv(envVarNotionTagsProperty),
+			os.Getenv(envVarNotionStatusProperty),
+			envOrDefault(envVarNotionNewStatus, defaultNotionNewStatus),
+			os.Getenv(envVarNotionLearnedProperty),
+			listFromENV(envVarNotionLearnedStatuses),
+		),
+		notionLayout:            spacedrepetition.DefaultNotionLayout(),
+		notionRequestsPerSecond: float64FromENV(envVarNotionRequestsPerSecond, defaultNotionRequestsPerSecond),
+		notionMaxRetries:        int64FromENV(envVarNotionMaxRetries, defaultNotionMaxRetries),
+		notionSync:              false,
+		notionSyncInterval:      durationFromENV(envVarNotionSyncInterval, 0),
+		wordFrequencyListPath:   os.Getenv(envVarWordFrequencyListPath),
+	}
+
+	var err error
+	if cfg.googleSearchLocation, err = time.LoadLocation(
+		envOrDefault(envVarGoogleSearchTimezone, defaultGoogleSearchTimezone),
+	); err != nil {
+		log.Panicf("env var `%s` parsing error: %s", envVarGoogleSearchTimezone, err)
 	}
 
 	if cfg.redisHost, varExists = os.LookupEnv(envVarRedisHost); !varExists {
@@ -112,29 +494,659 @@ func configFromENVs() config {
 		log.Panicf("reqiured env var `%s` doesn't exist", envVarRedisPassword)
 	}
 
-	if cfg.wordsAPIKey, varExists = os.LookupEnv(envVarWordsKey); !varExists {
-		log.Panicf("reqiured env var `%s` doesn't exist", envVarWordsKey)
+	cfg.dictionaryProviders = strings.Split(envOrDefault(envVarDictionaryProviders, defaultDictionaryProviders), ",")
+
+	for _, provider := range cfg.dictionaryProviders {
+		switch strings.TrimSpace(provider) {
+		case dictionaryProviderWordsAPI:
+			if cfg.wordsAPIKey, varExists = os.LookupEnv(envVarWordsKey); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarWordsKey)
+			}
+		case dictionaryProviderWordNet:
+			if _, varExists = os.LookupEnv(envVarWordNetDictPath); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarWordNetDictPath)
+			}
+		}
+	}
+
+	for _, provider := range cfg.imagesProviders {
+		switch strings.TrimSpace(provider) {
+		case imagesProviderGoogle:
+			if cfg.googleSearchAPIKey, varExists = os.LookupEnv(envVarGoogleSearchKey); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarGoogleSearchKey)
+			}
+
+			if cfg.googleSearchEngineID, varExists = os.LookupEnv(envVarGoogleSearchEngineID); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarGoogleSearchEngineID)
+			}
+		case imagesProviderPixabay:
+			if cfg.pixabayAPIKey, varExists = os.LookupEnv(envVarPixabayKey); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarPixabayKey)
+			}
+		case imagesProviderUnsplash:
+			if cfg.unsplashAccessKey, varExists = os.LookupEnv(envVarUnsplashKey); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarUnsplashKey)
+			}
+		}
+	}
+
+	for i, target := range cfg.spacedRepetitionTargets {
+		cfg.spacedRepetitionTargets[i] = strings.TrimSpace(target)
+
+		switch cfg.spacedRepetitionTargets[i] {
+		case spacedRepetitionTargetNotion:
+			if cfg.notionAPIKey, varExists = os.LookupEnv(envVarNotionKey); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionKey)
+			}
+
+			if cfg.notionDatabaseID, varExists = os.LookupEnv(envVarNotionDatabaseID); !varExists {
+				log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionDatabaseID)
+			}
+
+			if layoutPath := os.Getenv(envVarNotionLayoutPath); layoutPath != "" {
+				if cfg.notionLayout, err = spacedrepetition.LoadNotionLayout(layoutPath); err != nil {
+					log.Panicf("env var `%s` parsing error: %s", envVarNotionLayoutPath, err)
+				}
+			}
+		case spacedRepetitionTargetAnki, spacedRepetitionAnkiConnect, spacedRepetitionReviews:
+		case spacedRepetitionTargetFile:
+			cfg.flashcardsFile = flashcardsFileFormat()
+		default:
+			log.Panicf("unknown spaced repetition target `%s` in `%s`", target, envVarSpacedRepetitionTarget)
+		}
+	}
+
+	// Learned words are synced from Notion if it's set what a learned word is there.
+	cfg.notionSync = os.Getenv(envVarNotionLearnedProperty) != "" || os.Getenv(envVarNotionLearnedStatuses) != ""
+	if cfg.notionSync {
+		if os.Getenv(envVarNotionLearnedStatuses) != "" && os.Getenv(envVarNotionStatusProperty) == "" {
+			log.Panicf("env var `%s` requires env var `%s`", envVarNotionLearnedStatuses, envVarNotionStatusProperty)
+		}
+
+		if cfg.notionAPIKey, varExists = os.LookupEnv(envVarNotionKey); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionKey)
+		}
+
+		if cfg.notionDatabaseID, varExists = os.LookupEnv(envVarNotionDatabaseID); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionDatabaseID)
+		}
+	}
+
+	switch cfg.imageStorage {
+	case imageStorageS3:
+		if cfg.s3Endpoint, varExists = os.LookupEnv(envVarS3Endpoint); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarS3Endpoint)
+		}
+
+		if cfg.s3AccessKey, varExists = os.LookupEnv(envVarS3AccessKey); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarS3AccessKey)
+		}
+
+		if cfg.s3SecretKey, varExists = os.LookupEnv(envVarS3SecretKey); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarS3SecretKey)
+		}
+
+		if cfg.s3Bucket, varExists = os.LookupEnv(envVarS3Bucket); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarS3Bucket)
+		}
+
+		if cfg.s3PublicBaseURL, varExists = os.LookupEnv(envVarS3PublicBaseURL); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarS3PublicBaseURL)
+		}
+	case imageStorageLocal, "":
+	default:
+		log.Panicf("unknown image storage `%s` in `%s`", cfg.imageStorage, envVarImageStorage)
 	}
 
-	if cfg.googleSearchAPIKey, varExists = os.LookupEnv(envVarGoogleSearchKey); !varExists {
-		log.Panicf("reqiured env var `%s` doesn't exist", envVarGoogleSearchKey)
+	switch cfg.translationProvider = os.Getenv(envVarTranslationProvider); cfg.translationProvider {
+	case translationProviderLibreTranslate:
+		if cfg.translationTargetLanguage, varExists = os.LookupEnv(envVarTranslationTargetLanguage); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarTranslationTargetLanguage)
+		}
+
+		if cfg.libreTranslateURL, varExists = os.LookupEnv(envVarLibreTranslateURL); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarLibreTranslateURL)
+		}
+
+		cfg.libreTranslateAPIKey = os.Getenv(envVarLibreTranslateAPIKey)
+	case translationProviderBilingualDictionary:
+		if cfg.bilingualDictionaryPath, varExists = os.LookupEnv(envVarBilingualDictionaryPath); !varExists {
+			log.Panicf("reqiured env var `%s` doesn't exist", envVarBilingualDictionaryPath)
+		}
+	case "":
+	default:
+		log.Panicf("unknown translation provider `%s` in `%s`", cfg.translationProvider, envVarTranslationProvider)
 	}
 
-	if cfg.googleSearchEngineID, varExists = os.LookupEnv(envVarGoogleSearchEngineID); !varExists {
-		log.Panicf("reqiured env var `%s` doesn't exist", envVarGoogleSearchEngineID)
+	if cfg.imagesSafeSearch, err = images.ParseSafeSearch(
+		envOrDefault(envVarImagesSafeSearch, string(defaultImagesSafeSearch)),
+	); err != nil {
+		log.Panicf("env var `%s` parsing error: %s", envVarImagesSafeSearch, err)
 	}
 
-	if cfg.notionAPIKey, varExists = os.LookupEnv(envVarNotionKey); !varExists {
-		log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionKey)
+	switch cfg.reviewScheduler {
+	case reviewSchedulerSM2, reviewSchedulerFSRS:
+	default:
+		log.Panicf("unknown review scheduler `%s` in `%s`", cfg.reviewScheduler, envVarReviewScheduler)
 	}
 
-	if cfg.notionDatabaseID, varExists = os.LookupEnv(envVarNotionDatabaseID); !varExists {
-		log.Panicf("reqiured env var `%s` doesn't exist", envVarNotionDatabaseID)
+	if cfg.notionExistingPages, err = spacedrepetition.ParseExistingPages(
+		envOrDefault(envVarNotionExisting, string(defaultNotionExisting)),
+	); err != nil {
+		log.Panicf("env var `%s` parsing error: %s", envVarNotionExisting, err)
 	}
 
 	return cfg
 }
 
+func envOrDefault(name, defaultValue string) string {
+	if value, varExists := os.LookupEnv(name); varExists && value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+func int64FromENV(name string, defaultValue int64) int64 {
+	value, varExists := os.LookupEnv(name)
+	if !varExists || value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Panicf("env var `%s` parsing error: %s", name, err)
+	}
+
+	return parsed
+}
+
+func float64FromENV(name string, defaultValue float64) float64 {
+	value, varExists := os.LookupEnv(name)
+	if !varExists || value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Panicf("env var `%s` parsing error: %s", name, err)
+	}
+
+	return parsed
+}
+
+// listFromENV splits the comma separated env var, blank items are left out.
+func listFromENV(name string) []string {
+	var list []string
+
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func durationFromENV(name string, defaultValue time.Duration) time.Duration {
+	value, varExists := os.LookupEnv(name)
+	if !varExists || value == "" {
+		return defaultValue
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Panicf("env var `%s` parsing error: %s", name, err)
+	}
+
+	return parsed
+}
+
+func dictionaryChain(cfg config) *dictionary.Chain {
+	providers := make([]dictionary.Interface, 0, len(cfg.dictionaryProviders))
+
+	for _, provider := range cfg.dictionaryProviders {
+		switch strings.TrimSpace(provider) {
+		case dictionaryProviderWordsAPI:
+			options := []dictionary.WordsAPIOption{dictionary.WithUserAgent(userAgent)}
+			if cfg.wordsAPIBaseURL != "" {
+				options = append(options, dictionary.WithBaseURL(cfg.wordsAPIBaseURL))
+			}
+
+			if cfg.wordsAPITimeout > 0 {
+				options = append(options, dictionary.WithTimeout(cfg.wordsAPITimeout))
+			}
+
+			providers = append(providers, dictionary.NewWordsAPI(cfg.wordsAPIKey, options...))
+		case dictionaryProviderFreeDictionary:
+			providers = append(providers, dictionary.NewFreeDictionaryAPI(
+				&http.Client{Timeout: freeDictionaryTimeout}, //nolint:exhaustruct
+			))
+		case dictionaryProviderWordNet:
+			providers = append(providers, dictionary.NewWordNet(cfg.wordNetDictPath))
+		default:
+			log.Panicf("unknown dictionary provider `%s` in `%s`", provider, envVarDictionaryProviders)
+		}
+	}
+
+	return dictionary.NewChain(dictionaryMaxDefinitions, dictionaryMaxExamples, providers...)
+}
+
+func imagesProviders(cfg config, imagesRedis *redis.Client) *images.Fallback {
+	providers := make([]images.Interface, 0, len(cfg.imagesProviders))
+	httpClient := &http.Client{Timeout: imagesSearchTimeout} //nolint:exhaustruct
+
+	for _, provider := range cfg.imagesProviders {
+		provider = strings.TrimSpace(provider)
+
+		var imagesService images.Interface
+
+		switch provider {
+		case imagesProviderGoogle:
+			googleCustomSearchService, err := customsearch.NewService(
+				context.Background(), option.WithAPIKey(cfg.googleSearchAPIKey),
+			)
+			if err != nil {
+				log.Panicf("custom search service creation error: %s", err)
+			}
+
+			providers = append(providers, images.NewQuota(
+				images.NewGoogleCustomSearch(
+					googleCustomSearchService, cfg.googleSearchEngineID, cfg.imagesSafeSearch,
+				),
+				imagesRedis,
+				provider,
+				cfg.googleSearchQuota,
+				cfg.googleSearchReserve,
+				cfg.googleSearchLocation,
+			))
+
+			continue
+		case imagesProviderPixabay:
+			imagesService = images.NewPixabay(httpClient, cfg.pixabayAPIKey, cfg.imagesSafeSearch)
+		case imagesProviderUnsplash:
+			imagesService = images.NewUnsplash(httpClient, cfg.unsplashAccessKey, cfg.imagesSafeSearch)
+		case imagesProviderOpenverse:
+			imagesService = images.NewOpenverse(httpClient, cfg.openverseToken, userAgent, cfg.imagesSafeSearch)
+		case imagesProviderWikimedia:
+			// Wikimedia Commons has no safe search, its results can't be trusted to be safe.
+			if cfg.imagesSafeSearch == images.SafeSearchStrict {
+				log.Printf("images provider `%s` is skipped, it has no strict safe search", provider)
+
+				continue
+			}
+
+			imagesService = images.NewWikimediaCommons(httpClient, userAgent)
+		default:
+			log.Panicf("unknown images provider `%s` in `%s`", provider, envVarImagesProviders)
+		}
+
+		// Quotas of other providers are optional, e.g. `PIXABAY_DAILY_QUOTA`.
+		quotaEnvVar := strings.ToUpper(provider) + envVarDailyQuotaSuffix
+		if quota := int64FromENV(quotaEnvVar, defaultProviderDailyQuota); quota > 0 {
+			imagesService = images.NewQuota(imagesService, imagesRedis, provider, quota, 0, time.UTC)
+		}
+
+		providers = append(providers, imagesService)
+	}
+
+	return images.NewFallback(cfg.imagesProvidersMode == imagesProvidersModeRotate, providers...)
+}
+
+// imagesQueryTemplates parses `template:quota` pairs separated by semicolons, e.g. `{word}:4;{word} illustration:4`.
+func imagesQueryTemplates(spec string) []*images.QueryTemplate {
+	templates := make([]*images.QueryTemplate, 0)
+
+	for _, templateSpec := range strings.Split(spec, imagesQueriesSeparator) {
+		if strings.TrimSpace(templateSpec) == "" {
+			continue
+		}
+
+		quotaIndex := strings.LastIndex(templateSpec, imagesQueryQuotaSeparator)
+		if quotaIndex == -1 {
+			log.Panicf("no quota of images query template `%s` in `%s`", templateSpec, envVarImagesQueries)
+		}
+
+		quota, err := strconv.Atoi(strings.TrimSpace(templateSpec[quotaIndex+1:]))
+		if err != nil || quota <= 0 {
+			log.Panicf("bad quota of images query template `%s` in `%s`", templateSpec, envVarImagesQueries)
+		}
+
+		templates = append(templates, images.NewQueryTemplate(strings.TrimSpace(templateSpec[:quotaIndex]), quota))
+	}
+
+	if len(templates) == 0 {
+		log.Panicf("no images query templates in `%s`", envVarImagesQueries)
+	}
+
+	return templates
+}
+
+// spacedRepetition fans the words out to all the configured targets if there are several.
+func spacedRepetition(
+	cfg config,
+	cardsRepo repository.CardsInterface,
+	notionService *spacedrepetition.Notion,
+) spacedrepetition.Interface {
+	targets := make([]spacedrepetition.Interface, len(cfg.spacedRepetitionTargets))
+	for i, target := range cfg.spacedRepetitionTargets {
+		targets[i] = spacedRepetitionTarget(cfg, target, cardsRepo, notionService)
+	}
+
+	if len(targets) == 1 {
+		return targets[0]
+	}
+
+	return spacedrepetition.NewFanout(targets...)
+}
+
+func spacedRepetitionTarget(
+	cfg config,
+	target string,
+	cardsRepo repository.CardsInterface,
+	notionService *spacedrepetition.Notion,
+) spacedrepetition.Interface {
+	switch target {
+	case spacedRepetitionReviews:
+		return spacedrepetition.NewReviews(cardsRepo)
+	case spacedRepetitionTargetAnki:
+		return anki(cfg)
+	case spacedRepetitionTargetFile:
+		tabular, err := spacedrepetition.NewTabular(
+			cfg.flashcardsFile,
+			cfg.exportStoragePath,
+			strings.TrimRight(cfg.publicBaseURL, "/")+port.ExportsHTTPPath,
+		)
+		if err != nil {
+			log.Panicf("flashcards file service creation error: %s", err)
+		}
+
+		return tabular
+	case spacedRepetitionAnkiConnect:
+		return spacedrepetition.NewAnkiConnect(
+			&http.Client{Timeout: ankiConnectTimeout}, //nolint:exhaustruct
+			cfg.ankiConnectURL,
+			cfg.ankiDeckName,
+			cfg.ankiTags,
+		)
+	default:
+		return notionService
+	}
+}
+
+func reviewScheduler(cfg config) scheduler.Interface {
+	if cfg.reviewScheduler == reviewSchedulerFSRS {
+		return scheduler.NewFSRS(cfg.fsrsRequestedRetention)
+	}
+
+	return scheduler.NewSM2()
+}
+
+// flashcardsFileFormat takes the preset format, set env vars override its parts.
+func flashcardsFileFormat() *spacedrepetition.TabularFormat {
+	preset, err := spacedrepetition.TabularPresetFormat(
+		envOrDefault(envVarFlashcardsFilePreset, defaultFlashcardsFilePreset),
+	)
+	if err != nil {
+		log.Panicf("env var `%s` parsing error: %s", envVarFlashcardsFilePreset, err)
+	}
+
+	columns := preset.Columns()
+	if spec := os.Getenv(envVarFlashcardsFileColumns); spec != "" {
+		if columns, err = spacedrepetition.ParseTabularColumns(spec); err != nil {
+			log.Panicf("env var `%s` parsing error: %s", envVarFlashcardsFileColumns, err)
+		}
+	}
+
+	markup, err := spacedrepetition.ParseTabularMarkup(
+		envOrDefault(envVarFlashcardsFileMarkup, string(preset.Markup())),
+	)
+	if err != nil {
+		log.Panicf("env var `%s` parsing error: %s", envVarFlashcardsFileMarkup, err)
+	}
+
+	// Separators may be written as escape sequences, e.g. `\t` or `\n`.
+	unescape := strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\r`, "\r").Replace
+
+	return spacedrepetition.NewTabularFormat(
+		columns,
+		unescape(envOrDefault(envVarFlashcardsFileFieldSeparator, preset.FieldSeparator())),
+		unescape(envOrDefault(envVarFlashcardsFileRowSeparator, preset.RowSeparator())),
+		markup,
+		envOrDefault(envVarFlashcardsFileHeader, strconv.FormatBool(preset.Header())) == "true",
+		envOrDefault(envVarFlashcardsFileQuote, strconv.FormatBool(preset.Quote())) == "true",
+		envOrDefault(envVarFlashcardsFileEscapeFormulas, strconv.FormatBool(preset.EscapeFormulas())) == "true",
+	)
+}
+
+func anki(cfg config) *spacedrepetition.Anki {
+	ankiService, err := spacedrepetition.NewAnki(
+		cfg.ankiDeckName,
+		cfg.ankiTags,
+		cfg.exportStoragePath,
+		strings.TrimRight(cfg.publicBaseURL, "/")+port.ExportsHTTPPath,
+		&http.Client{ //nolint:exhaustruct
+			Timeout:   imageDownloadTimeout,
+			Transport: safehttp.NewTransport(selfHostedImagesHosts(cfg)...),
+		},
+		userAgent,
+		imageDownloadMaxBytes,
+	)
+	if err != nil {
+		log.Panicf("Anki service creation error: %s", err)
+	}
+
+	return ankiService
+}
+
+// syncLearnedWords runs the Notion learned words sync once.
+func syncLearnedWords(
+	cfg config,
+	notionService *spacedrepetition.Notion,
+	wordsRepo repository.Interface,
+	cursors repository.SyncCursorsInterface,
+) {
+	if !cfg.notionSync {
+		log.Panicf("learned words sync isn't configured, set `%s` or `%s`",
+			envVarNotionLearnedProperty, envVarNotionLearnedStatuses)
+	}
+
+	count, err := learnedsync.NewSync(notionService, wordsRepo, cursors).Run(context.Background())
+	if err != nil {
+		log.Panicf("learned words sync error: %s", err)
+	}
+
+	log.Printf("%d learned words were synced from Notion", count)
+}
+
+// notionIfUsed returns nil unless Notion is a spaced repetition target or the learned words are synced from it.
+func notionIfUsed(cfg config) *spacedrepetition.Notion {
+	if cfg.notionSync {
+		return notion(cfg)
+	}
+
+	for _, target := range cfg.spacedRepetitionTargets {
+		if target == spacedRepetitionTargetNotion {
+			return notion(cfg)
+		}
+	}
+
+	return nil
+}
+
+// notion creates the Notion service and validates the database has the configured properties.
+func notion(cfg config) *spacedrepetition.Notion {
+	httpClient := &http.Client{ //nolint:exhaustruct
+		Transport: spacedrepetition.NewThrottledTransport(
+			http.DefaultTransport, cfg.notionRequestsPerSecond, int(cfg.notionMaxRetries), notionRetryBackoff,
+		),
+		Timeout: notionRequestTimeout,
+	}
+
+	notionService := spacedrepetition.NewNotion(
+		notionapi.NewClient(notionapi.Token(cfg.notionAPIKey), notionapi.WithHTTPClient(httpClient)),
+		httpClient,
+		notionapi.DatabaseID(cfg.notionDatabaseID),
+		cfg.notionProperties,
+		cfg.notionExistingPages,
+		cfg.notionLayout,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), notionSchemaValidationTimeout)
+	defer cancel()
+
+	if err := notionService.ValidateSchema(ctx); err != nil {
+		log.Panicf("Notion database schema validation error: %s", err)
+	}
+
+	return notionService
+}
+
+// wordFrequency returns nil if no frequency list is configured.
+func wordFrequency(cfg config) wordfrequency.Interface {
+	if cfg.wordFrequencyListPath == "" {
+		return nil
+	}
+
+	list, err := wordfrequency.NewList(cfg.wordFrequencyListPath)
+	if err != nil {
+		log.Panicf("word frequency list loading error: %s", err)
+	}
+
+	return list
+}
+
+// clozeGenerator returns nil if there are no cloze cards, irregular forms are taken from WordNet if it's there.
+func clozeGenerator(cfg config) cloze.Interface {
+	if !cfg.clozeCards {
+		return nil
+	}
+
+	irregularForms := make(map[string][]string)
+
+	if cfg.wordNetDictPath != "" {
+		var err error
+		if irregularForms, err = cloze.LoadWordNetIrregularForms(cfg.wordNetDictPath); err != nil {
+			log.Panicf("WordNet irregular forms loading error: %s", err)
+		}
+	}
+
+	return cloze.NewInflections(irregularForms)
+}
+
+// translator returns nil if translation isn't configured.
+func translator(cfg config) translation.Interface {
+	switch cfg.translationProvider {
+	case translationProviderLibreTranslate:
+		return translation.NewLibreTranslate(
+			&http.Client{Timeout: libreTranslateTimeout}, //nolint:exhaustruct
+			cfg.libreTranslateURL,
+			cfg.libreTranslateAPIKey,
+			cfg.translationTargetLanguage,
+		)
+	case translationProviderBilingualDictionary:
+		bilingualDictionary, err := translation.NewBilingualDictionary(cfg.bilingualDictionaryPath)
+		if err != nil {
+			log.Panicf("bilingual dictionary loading error: %s", err)
+		}
+
+		return bilingualDictionary
+	default:
+		return nil
+	}
+}
+
+// pronunciationService returns nil if no TTS command is configured.
+func pronunciationService(cfg config) pronunciation.Interface {
+	if cfg.ttsCommand == "" {
+		return nil
+	}
+
+	ttsCommand, err := pronunciation.NewTTSCommand(
+		cfg.ttsCommand, cfg.audioStoragePath, strings.TrimRight(cfg.publicBaseURL, "/")+port.AudioHTTPPath,
+	)
+	if err != nil {
+		log.Panicf("TTS command creation error: %s", err)
+	}
+
+	return ttsCommand
+}
+
+// selfHostedImagesHosts are the hosts of images stored in S3, which may be in a private network.
+// Images stored on the local disk are read from files.
+func selfHostedImagesHosts(cfg config) []string {
+	if cfg.imageStorage != imageStorageS3 {
+		return nil
+	}
+
+	publicBaseURL, err := url.Parse(cfg.s3PublicBaseURL)
+	if err != nil {
+		log.Panicf("S3 public base URL parsing error: %s", err)
+	}
+
+	return []string{publicBaseURL.Host}
+}
+
+// imageFetcher is shared by the image filter and the image storage, so images the filter has downloaded
+// aren't downloaded again when they're stored.
+func imageFetcher(cfg config) imagefetch.Interface {
+	httpClient := &http.Client{Timeout: imageDownloadTimeout, Transport: safehttp.NewTransport()} //nolint:exhaustruct
+
+	return imagefetch.NewCache(
+		imagefetch.NewHTTP(httpClient, userAgent, imageDownloadMaxBytes),
+		cfg.imageCacheSize<<20,
+		imageDownloadCacheTTL,
+	)
+}
+
+// imageStorage returns nil if images aren't self-hosted.
+func imageStorage(cfg config, fetcher imagefetch.Interface) imagestorage.Interface {
+	var storage imagestorage.Storage
+
+	switch cfg.imageStorage {
+	case imageStorageLocal:
+		localDisk, err := imagestorage.NewLocalDisk(
+			cfg.imageStoragePath, strings.TrimRight(cfg.publicBaseURL, "/")+port.ImagesHTTPPath,
+		)
+		if err != nil {
+			log.Panicf("local images storage creation error: %s", err)
+		}
+
+		storage = localDisk
+	case imageStorageS3:
+		client, err := minio.New(cfg.s3Endpoint, &minio.Options{ //nolint:exhaustruct
+			Creds:  credentials.NewStaticV4(cfg.s3AccessKey, cfg.s3SecretKey, ""),
+			Secure: cfg.s3UseSSL,
+		})
+		if err != nil {
+			log.Panicf("S3 client creation error: %s", err)
+		}
+
+		storage = imagestorage.NewS3(client, cfg.s3Bucket, cfg.s3PublicBaseURL)
+	default:
+		return nil
+	}
+
+	return imagestorage.NewThumbnails(storage, fetcher, int(cfg.thumbnailMaxSide), int(cfg.imageMaxPixels))
+}
+
+// imageFilter returns nil if found images are taken as they are.
+func imageFilter(cfg config, fetcher imagefetch.Interface) imagequality.Interface {
+	if !cfg.imageQualityFilter {
+		return nil
+	}
+
+	return imagequality.NewPerceptualFilter(
+		fetcher,
+		int(cfg.imageMaxPixels),
+		int(cfg.imageMinWidth),
+		int(cfg.imageMinHeight),
+		cfg.imageMaxAspectRatio,
+		int(cfg.imageDuplicateDistance),
+		imageFilterConcurrency,
+	)
+}
+
 func redisClient(host, username, password string, db int) *redis.Client {
 	rdb := redis.NewClient(&redis.Options{ //nolint: exhaustruct
 		Addr:     host,
@@ -0,0 +1,317 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/cloze"
+	"github.com/r-erema/vocaboost/internal/application/service/imagestorage"
+	"github.com/r-erema/vocaboost/internal/application/service/pronunciation"
+	"github.com/r-erema/vocaboost/internal/application/service/translation"
+	"github.com/r-erema/vocaboost/internal/application/service/wordfrequency"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// BuildCards turns the picked card material into cards: translates it, synthesizes missing audio, ranks the words,
+// generates clozes and stores the images. Every step runs only if its service is configured.
+type BuildCards struct {
+	offeredURLs    repository.OfferedURLsInterface
+	translator     translation.Interface
+	pronunciation  pronunciation.Interface
+	wordFrequency  wordfrequency.Interface
+	clozeGenerator cloze.Interface
+	imageStorage   imagestorage.Interface
+}
+
+// NewBuildCards creates the build, all the services but the offered URLs are optional.
+func NewBuildCards(
+	offeredURLs repository.OfferedURLsInterface,
+	translator translation.Interface,
+	pronunciationService pronunciation.Interface,
+	wordFrequency wordfrequency.Interface,
+	clozeGenerator cloze.Interface,
+	imageStorage imagestorage.Interface,
+) *BuildCards {
+	return &BuildCards{
+		offeredURLs:    offeredURLs,
+		translator:     translator,
+		pronunciation:  pronunciationService,
+		wordFrequency:  wordFrequency,
+		clozeGenerator: clozeGenerator,
+		imageStorage:   imageStorage,
+	}
+}
+
+// Run returns the cards in the order of the material. The cards are built without translations
+// if the translation fails and without synthesized audio if the synthesis fails. Audio and images
+// which weren't offered by a look up are dropped, so the service downloads only media it has found itself.
+func (b BuildCards) Run(ctx context.Context, materials []*CardMaterial) ([]*domain.Word, error) {
+	materials, err := b.keepOfferedMedia(ctx, materials)
+	if err != nil {
+		return nil, fmt.Errorf("checking offered media error: %w", err)
+	}
+
+	translations, err := b.translate(ctx, materials)
+	if err != nil {
+		log.Printf("translating words error, the cards are left untranslated: %s", err)
+
+		translations = make(map[string]string)
+	}
+
+	wordsAudio, err := b.synthesizeMissingAudio(ctx, materials)
+	if err != nil {
+		log.Printf("synthesizing words audio error, the cards are left without synthesized audio: %s", err)
+
+		wordsAudio = make(map[string]*pronunciation.WordAudioDTO)
+	}
+
+	ranks, err := b.rank(ctx, materials)
+	if err != nil {
+		return nil, fmt.Errorf("ranking words error: %w", err)
+	}
+
+	words := make([]*domain.Word, len(materials))
+
+	for i, material := range materials {
+		definitions := make([]*domain.Definition, len(material.Definitions))
+		for j, definition := range material.Definitions {
+			definitions[j] = domain.NewDefinition(
+				definition.PartOfSpeech,
+				definition.Gloss,
+				translations[definition.Gloss],
+				definition.Synonyms,
+				definition.Antonyms,
+			)
+		}
+
+		wordImages := make([]*domain.Image, len(material.Images))
+		for j := range material.Images {
+			wordImages[j] = domain.NewImage(material.Images[j], "")
+		}
+
+		words[i] = b.addClozes(domain.NewWord(
+			material.Word,
+			translations[material.Word],
+			material.SourceSentence,
+			definitions,
+			material.Examples,
+			wordImages,
+			cardPronunciation(material, wordsAudio[material.Word]),
+			ranks[material.Word],
+			material.Tags,
+		))
+	}
+
+	if words, err = b.selfHostImages(ctx, words); err != nil {
+		return nil, fmt.Errorf("self-hosting words images error: %w", err)
+	}
+
+	return words, nil
+}
+
+// keepOfferedMedia returns copies of the material without the audio and images which weren't offered.
+func (b BuildCards) keepOfferedMedia(ctx context.Context, materials []*CardMaterial) ([]*CardMaterial, error) {
+	offeredURLs, err := b.offeredURLs.FilterOfferedURLs(ctx, materialMediaURLs(materials))
+	if err != nil {
+		return nil, fmt.Errorf("filtering offered URLs error: %w", err)
+	}
+
+	offered := make(map[string]struct{}, len(offeredURLs))
+	for _, offeredURL := range offeredURLs {
+		offered[offeredURL] = struct{}{}
+	}
+
+	kept := make([]*CardMaterial, len(materials))
+
+	for i, material := range materials {
+		keptMaterial := *material
+		keptMaterial.Images = make([]string, 0, len(material.Images))
+
+		if _, isOffered := offered[material.AudioURL]; !isOffered && material.AudioURL != "" {
+			log.Printf("audio `%s` of word `%s` wasn't offered, it's dropped", material.AudioURL, material.Word)
+
+			keptMaterial.AudioURL = ""
+		}
+
+		for _, imageURL := range material.Images {
+			if _, isOffered := offered[imageURL]; !isOffered {
+				log.Printf("image `%s` of word `%s` wasn't offered, it's dropped", imageURL, material.Word)
+
+				continue
+			}
+
+			keptMaterial.Images = append(keptMaterial.Images, imageURL)
+		}
+
+		kept[i] = &keptMaterial
+	}
+
+	return kept, nil
+}
+
+func materialMediaURLs(materials []*CardMaterial) []string {
+	urls := make([]string, 0)
+
+	for _, material := range materials {
+		if material.AudioURL != "" {
+			urls = append(urls, material.AudioURL)
+		}
+
+		urls = append(urls, material.Images...)
+	}
+
+	return urls
+}
+
+// translate translates the words and their definitions, the result is keyed by the source text.
+func (b BuildCards) translate(ctx context.Context, materials []*CardMaterial) (map[string]string, error) {
+	translations := make(map[string]string)
+	if b.translator == nil {
+		return translations, nil
+	}
+
+	texts := make([]string, 0)
+
+	for _, material := range materials {
+		texts = append(texts, material.Word)
+
+		for _, definition := range material.Definitions {
+			texts = append(texts, definition.Gloss)
+		}
+	}
+
+	translatedTexts, err := b.translator.Translate(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("translation error: %w", err)
+	}
+
+	for i := range texts {
+		translations[texts[i]] = translatedTexts[i]
+	}
+
+	return translations, nil
+}
+
+// rank finds frequency ranks of the words, the result is keyed by the word.
+func (b BuildCards) rank(ctx context.Context, materials []*CardMaterial) (map[string]int, error) {
+	ranks := make(map[string]int)
+	if b.wordFrequency == nil {
+		return ranks, nil
+	}
+
+	words := make([]string, len(materials))
+	for i := range materials {
+		words[i] = materials[i].Word
+	}
+
+	wordsRanks, err := b.wordFrequency.Ranks(ctx, words)
+	if err != nil {
+		return nil, fmt.Errorf("getting frequency ranks error: %w", err)
+	}
+
+	for i := range words {
+		ranks[words[i]] = wordsRanks[i]
+	}
+
+	return ranks, nil
+}
+
+// synthesizeMissingAudio synthesizes audio for words which have no pronunciation audio,
+// the result is keyed by the word.
+func (b BuildCards) synthesizeMissingAudio(
+	ctx context.Context,
+	materials []*CardMaterial,
+) (map[string]*pronunciation.WordAudioDTO, error) {
+	wordsAudio := make(map[string]*pronunciation.WordAudioDTO)
+	if b.pronunciation == nil {
+		return wordsAudio, nil
+	}
+
+	wordsWithoutAudio := make([]string, 0)
+
+	for _, material := range materials {
+		if material.AudioURL == "" {
+			wordsWithoutAudio = append(wordsWithoutAudio, material.Word)
+		}
+	}
+
+	if len(wordsWithoutAudio) == 0 {
+		return wordsAudio, nil
+	}
+
+	audio, err := b.pronunciation.Audio(ctx, wordsWithoutAudio)
+	if err != nil {
+		return nil, fmt.Errorf("audio synthesis error: %w", err)
+	}
+
+	for _, wordAudio := range audio {
+		wordsAudio[wordAudio.Word()] = wordAudio
+	}
+
+	return wordsAudio, nil
+}
+
+// addClozes blanks the word out in its examples and source sentence, the word is inflected as the parts of speech
+// of its definitions.
+func (b BuildCards) addClozes(word *domain.Word) *domain.Word {
+	if b.clozeGenerator == nil {
+		return word
+	}
+
+	partsOfSpeech := make([]string, len(word.Definitions()))
+	for i, definition := range word.Definitions() {
+		partsOfSpeech[i] = definition.PartOfSpeech()
+	}
+
+	sentences := word.Examples()
+	if word.SourceSentence() != "" {
+		sentences = append(append(make([]string, 0, len(sentences)+1), sentences...), word.SourceSentence())
+	}
+
+	return word.WithClozes(b.clozeGenerator.Clozes(word.Word(), partsOfSpeech, sentences))
+}
+
+// selfHostImages replaces hotlinked images with the stored copies, images which can't be stored are dropped.
+func (b BuildCards) selfHostImages(ctx context.Context, words []*domain.Word) ([]*domain.Word, error) {
+	if b.imageStorage == nil {
+		return words, nil
+	}
+
+	hostedWords := make([]*domain.Word, len(words))
+
+	for i, word := range words {
+		storedImages, err := b.imageStorage.Store(ctx, word.ImageURLs())
+		if err != nil {
+			return nil, fmt.Errorf("storing images of word `%s` error: %w", word.Word(), err)
+		}
+
+		wordImages := make([]*domain.Image, len(storedImages))
+		for j := range storedImages {
+			wordImages[j] = domain.NewImage(storedImages[j].URL(), storedImages[j].FilePath())
+		}
+
+		hostedWords[i] = word.WithImages(wordImages)
+	}
+
+	return hostedWords, nil
+}
+
+// cardPronunciation prefers the picked audio to the synthesized one, a card without any pronunciation gets none.
+func cardPronunciation(material *CardMaterial, wordAudio *pronunciation.WordAudioDTO) *domain.Pronunciation {
+	var (
+		audioURL      = material.AudioURL
+		audioFilePath string
+	)
+
+	if audioURL == "" && wordAudio != nil {
+		audioURL, audioFilePath = wordAudio.URL(), wordAudio.FilePath()
+	}
+
+	if material.IPA == "" && len(material.Syllables) == 0 && audioURL == "" {
+		return nil
+	}
+
+	return domain.NewPronunciation(material.IPA, material.Syllables, audioURL, audioFilePath)
+}
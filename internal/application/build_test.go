@@ -0,0 +1,258 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/application/service/translation"
+)
+
+func testCardMaterials() []*application.CardMaterial {
+	return []*application.CardMaterial{
+		{
+			Word:           "cat",
+			IPA:            "kæt",
+			Syllables:      []string{"cat"},
+			AudioURL:       "https://audio.test/dictionary/cat.mp3",
+			SourceSentence: "The cat purrs.",
+			Tags:           []string{"pets"},
+			Definitions: []*application.Definition{
+				{PartOfSpeech: "noun", Gloss: "a small animal", Synonyms: []string{"feline"}, Antonyms: nil},
+			},
+			Examples: []string{"a black cat", "no word here"},
+			Images:   []string{"https://img.test/1.jpg", "https://img.test/broken.jpg"},
+		},
+		{
+			Word:           "purr",
+			IPA:            "",
+			Syllables:      nil,
+			AudioURL:       "",
+			SourceSentence: "",
+			Tags:           []string{"pets"},
+			Definitions:    nil,
+			Examples:       nil,
+			Images:         nil,
+		},
+	}
+}
+
+// testOfferedURLs has offered all the media of the test card material.
+func testOfferedURLs() *fakeOfferedURLs {
+	return newFakeOfferedURLs(
+		"https://audio.test/dictionary/cat.mp3", "https://img.test/1.jpg", "https://img.test/broken.jpg",
+	)
+}
+
+func TestBuildCards(t *testing.T) {
+	t.Parallel()
+
+	pronunciationService := &fakePronunciation{words: nil, err: nil}
+	build := application.NewBuildCards(
+		testOfferedURLs(),
+		fakeTranslator{err: nil},
+		pronunciationService,
+		fakeWordFrequency{ranks: map[string]int{"cat": 1200}},
+		fakeClozes{},
+		fakeImageStorage{failed: map[string]bool{"https://img.test/broken.jpg": true}},
+	)
+
+	words, err := build.Run(context.Background(), testCardMaterials())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(words) != 2 { //nolint:gomnd
+		t.Fatalf("got %d cards, want 2", len(words))
+	}
+
+	cat := words[0]
+	if cat.Translation() != "CAT" || cat.Definitions()[0].Translation() != "A SMALL ANIMAL" {
+		t.Errorf("got translations %q and %q", cat.Translation(), cat.Definitions()[0].Translation())
+	}
+
+	if cat.FrequencyRank() != 1200 || words[1].FrequencyRank() != 0 {
+		t.Errorf("got ranks %d and %d, want 1200 and 0", cat.FrequencyRank(), words[1].FrequencyRank())
+	}
+
+	if cat.Pronunciation().AudioURL() != "https://audio.test/dictionary/cat.mp3" {
+		t.Errorf("got audio %q, want the dictionary one", cat.Pronunciation().AudioURL())
+	}
+
+	if !reflect.DeepEqual(pronunciationService.words, []string{"purr"}) {
+		t.Errorf("got audio synthesized for %q, want only purr", pronunciationService.words)
+	}
+
+	if words[1].Pronunciation() == nil || words[1].Pronunciation().AudioFilePath() != "/audio/purr.mp3" {
+		t.Errorf("got pronunciation %+v, want the synthesized audio", words[1].Pronunciation())
+	}
+
+	if want := []string{"https://storage.test/1.jpg"}; !reflect.DeepEqual(cat.ImageURLs(), want) {
+		t.Errorf("got images %q, want %q", cat.ImageURLs(), want)
+	}
+
+	clozes := make([]string, len(cat.Clozes()))
+	for i, cloze := range cat.Clozes() {
+		clozes[i] = cloze.Blanked()
+	}
+
+	if want := []string{"a black _____", "The _____ purrs."}; !reflect.DeepEqual(clozes, want) {
+		t.Errorf("got clozes %q, want %q", clozes, want)
+	}
+
+	if !reflect.DeepEqual(cat.Tags(), []string{"pets"}) ||
+		!reflect.DeepEqual(cat.Examples(), testCardMaterials()[0].Examples) {
+		t.Errorf("got tags %q and examples %q", cat.Tags(), cat.Examples())
+	}
+}
+
+func TestBuildCardsWithoutServices(t *testing.T) {
+	t.Parallel()
+
+	words, err := application.NewBuildCards(testOfferedURLs(), nil, nil, nil, nil, nil).Run(
+		context.Background(), testCardMaterials(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cat, purr := words[0], words[1]
+
+	if cat.Translation() != "" || cat.FrequencyRank() != 0 || len(cat.Clozes()) != 0 {
+		t.Errorf("got enriched card %+v", cat)
+	}
+
+	if want := testCardMaterials()[0].Images; !reflect.DeepEqual(cat.ImageURLs(), want) {
+		t.Errorf("got images %q, want the hotlinked %q", cat.ImageURLs(), want)
+	}
+
+	if purr.Pronunciation() != nil {
+		t.Errorf("got pronunciation %+v, want none", purr.Pronunciation())
+	}
+}
+
+func TestBuildCardsWithFailedServices(t *testing.T) {
+	t.Parallel()
+
+	words, err := application.NewBuildCards(
+		testOfferedURLs(), fakeTranslator{err: errTest}, &fakePronunciation{words: nil, err: errTest}, nil, nil, nil,
+	).Run(context.Background(), testCardMaterials())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(words) != 2 { //nolint:gomnd
+		t.Fatalf("got %d cards, want 2", len(words))
+	}
+
+	if cat := words[0]; cat.Translation() != "" || cat.Definitions()[0].Translation() != "" {
+		t.Errorf("got translations %q and %q, want none", cat.Translation(), cat.Definitions()[0].Translation())
+	}
+
+	if purr := words[1]; purr.Pronunciation() != nil {
+		t.Errorf("got pronunciation %+v, want none", purr.Pronunciation())
+	}
+}
+
+func TestBuildCardsLeavesUntranslatedTexts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "en-de.tsv")
+	if err := os.WriteFile(path, []byte("cat\tKatze\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	bilingualDictionary, err := translation.NewBilingualDictionary(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailable.Close)
+
+	for name, testCase := range map[string]struct {
+		translator               translation.Interface
+		wantWord, wantDefinition string
+	}{
+		"missing from the dictionary": {bilingualDictionary, "Katze", ""},
+		"unavailable service": {
+			translation.NewLibreTranslate(unavailable.Client(), unavailable.URL, "", "de"), "", "",
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			words, err := application.NewBuildCards(testOfferedURLs(), testCase.translator, nil, nil, nil, nil).
+				Run(context.Background(), testCardMaterials())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if len(words) != 2 { //nolint:gomnd
+				t.Fatalf("got %d cards, want 2", len(words))
+			}
+
+			cat, purr := words[0], words[1]
+			if cat.Translation() != testCase.wantWord || cat.Definitions()[0].Translation() != testCase.wantDefinition ||
+				purr.Translation() != "" {
+				t.Errorf(
+					"got translations %q, %q and %q, want %q and %q",
+					cat.Translation(), cat.Definitions()[0].Translation(), purr.Translation(),
+					testCase.wantWord, testCase.wantDefinition,
+				)
+			}
+		})
+	}
+}
+
+func TestBuildCardsDropsMediaWhichWasNotOffered(t *testing.T) {
+	t.Parallel()
+
+	materials := testCardMaterials()
+	materials[0].AudioURL = "http://169.254.169.254/latest/meta-data"
+	materials[0].Images = []string{"http://127.0.0.1:6379/", "https://img.test/1.jpg"}
+
+	pronunciationService := &fakePronunciation{words: nil, err: nil}
+
+	words, err := application.NewBuildCards(
+		testOfferedURLs(), nil, pronunciationService, nil, nil, nil,
+	).Run(context.Background(), materials)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := []string{"https://img.test/1.jpg"}; !reflect.DeepEqual(words[0].ImageURLs(), want) {
+		t.Errorf("got images %q, want %q", words[0].ImageURLs(), want)
+	}
+
+	if !reflect.DeepEqual(pronunciationService.words, []string{"cat", "purr"}) {
+		t.Errorf("got audio synthesized for %q, want cat instead of the dropped audio and purr", pronunciationService.words)
+	}
+
+	if materials[0].AudioURL == "" || len(materials[0].Images) != 2 {
+		t.Errorf("got the material changed to %+v", materials[0])
+	}
+}
+
+func TestBuildCardsFailsWhenOfferedURLsFail(t *testing.T) {
+	t.Parallel()
+
+	offeredURLs := testOfferedURLs()
+	offeredURLs.err = errTest
+
+	if _, err := application.NewBuildCards(offeredURLs, nil, nil, nil, nil, nil).Run(
+		context.Background(), testCardMaterials(),
+	); !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+}
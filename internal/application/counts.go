@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+)
+
+// WordsCounts are the sizes of the word lists and the review queue.
+type WordsCounts struct {
+	KnownWords   int
+	IgnoredWords int
+	DueCards     int
+}
+
+// Counts sums up the progress: the known and the ignored words and the cards due for review.
+type Counts struct {
+	wordsRepo repository.Interface
+	cardsRepo repository.CardsInterface
+}
+
+func NewCounts(wordsRepo repository.Interface, cardsRepo repository.CardsInterface) *Counts {
+	return &Counts{wordsRepo: wordsRepo, cardsRepo: cardsRepo}
+}
+
+// Run returns the counts, the cards are counted as due at the time.
+func (c Counts) Run(ctx context.Context, now time.Time) (*WordsCounts, error) {
+	knownWordsCount, err := c.wordsRepo.KnownWordsCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting known words count error: %w", err)
+	}
+
+	ignoredWordsCount, err := c.wordsRepo.IgnoredWordsCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting ignored words count error: %w", err)
+	}
+
+	dueCardsCount, err := c.cardsRepo.DueCardsCount(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("getting due cards count error: %w", err)
+	}
+
+	return &WordsCounts{KnownWords: knownWordsCount, IgnoredWords: ignoredWordsCount, DueCards: dueCardsCount}, nil
+}
@@ -0,0 +1,66 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+func testCard(word string, due time.Time, intervalDays int) *domain.Card {
+	return domain.NewCard(
+		domain.NewWord(word, "", "", nil, nil, nil, nil, 0, nil),
+		domain.NewSchedule(due, time.Time{}, intervalDays, 0, 0, 0, 0, 0),
+	)
+}
+
+func TestCounts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	counts := application.NewCounts(
+		newFakeWordsRepo([]string{"cat", "dog"}, []string{"bob"}),
+		newFakeCardsRepo(
+			testCard("purr", now.Add(-time.Hour), 1),
+			testCard("meow", now, 1),
+			testCard("bark", now.Add(time.Hour), 1),
+		),
+	)
+
+	got, err := counts.Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := (&application.WordsCounts{KnownWords: 2, IgnoredWords: 1, DueCards: 2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got counts %+v, want %+v", got, want)
+	}
+}
+
+func TestCountsFails(t *testing.T) {
+	t.Parallel()
+
+	failingWordsRepo := newFakeWordsRepo(nil, nil)
+	failingWordsRepo.err = errTest
+	failingCardsRepo := newFakeCardsRepo()
+	failingCardsRepo.err = errTest
+
+	for name, counts := range map[string]*application.Counts{
+		"words": application.NewCounts(failingWordsRepo, newFakeCardsRepo()),
+		"cards": application.NewCounts(newFakeWordsRepo(nil, nil), failingCardsRepo),
+	} {
+		counts := counts
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := counts.Run(context.Background(), time.Now()); !errors.Is(err, errTest) {
+				t.Errorf("got error %v, want %v", err, errTest)
+			}
+		})
+	}
+}
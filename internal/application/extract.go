@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/textparser"
+)
+
+// ExtractUnknownWords finds the words of a text worth learning: the ones which are neither known nor ignored.
+type ExtractUnknownWords struct {
+	textParser textparser.Interface
+	wordsRepo  repository.Interface
+}
+
+func NewExtractUnknownWords(textParser textparser.Interface, wordsRepo repository.Interface) *ExtractUnknownWords {
+	return &ExtractUnknownWords{textParser: textParser, wordsRepo: wordsRepo}
+}
+
+// Run returns the unknown words of the text lowercased, in the order they're met in the text, without duplicates,
+// numbers and single letters.
+func (e ExtractUnknownWords) Run(ctx context.Context, text string) ([]string, error) {
+	extractedWords, err := e.textParser.ExtractWords(text)
+	if err != nil {
+		return nil, fmt.Errorf("extracting words error: %w", err)
+	}
+
+	words := make([]string, 0, len(extractedWords))
+	seen := make(map[string]bool, len(extractedWords))
+
+	for _, word := range extractedWords {
+		word = strings.ToLower(word)
+		if _, err = strconv.Atoi(word); err == nil || len(word) <= 1 || seen[word] {
+			continue
+		}
+
+		seen[word] = true
+		words = append(words, word)
+	}
+
+	if words, err = e.wordsRepo.FilterKnownWords(ctx, words); err != nil {
+		return nil, fmt.Errorf("filtering known words error: %w", err)
+	}
+
+	if words, err = e.wordsRepo.FilterIgnoredWords(ctx, words); err != nil {
+		return nil, fmt.Errorf("filtering ignored words error: %w", err)
+	}
+
+	if words == nil {
+		words = make([]string, 0)
+	}
+
+	return words, nil
+}
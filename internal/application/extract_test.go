@@ -0,0 +1,68 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application"
+)
+
+var errTest = errors.New("test error")
+
+func TestExtractUnknownWords(t *testing.T) {
+	t.Parallel()
+
+	extract := application.NewExtractUnknownWords(
+		fakeTextParser{err: nil},
+		newFakeWordsRepo([]string{"the"}, []string{"bob"}),
+	)
+
+	words, err := extract.Run(context.Background(), "The Cat saw 42 cats and a cat , Bob saw THE cat too")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"cat", "saw", "cats", "and", "too"}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("got words %q, want %q", words, want)
+	}
+}
+
+func TestExtractUnknownWordsReturnsEmptyList(t *testing.T) {
+	t.Parallel()
+
+	extract := application.NewExtractUnknownWords(fakeTextParser{err: nil}, newFakeWordsRepo([]string{"cat"}, nil))
+
+	words, err := extract.Run(context.Background(), "cat 1 2")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if words == nil || len(words) != 0 {
+		t.Errorf("got words %#v, want an empty list", words)
+	}
+}
+
+func TestExtractUnknownWordsFails(t *testing.T) {
+	t.Parallel()
+
+	failingRepo := newFakeWordsRepo(nil, nil)
+	failingRepo.err = errTest
+
+	for name, extract := range map[string]*application.ExtractUnknownWords{
+		"text parser": application.NewExtractUnknownWords(fakeTextParser{err: errTest}, newFakeWordsRepo(nil, nil)),
+		"words repo":  application.NewExtractUnknownWords(fakeTextParser{err: nil}, failingRepo),
+	} {
+		extract := extract
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := extract.Run(context.Background(), "cat"); !errors.Is(err, errTest) {
+				t.Errorf("got error %v, want %v", err, errTest)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package application_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+	"github.com/r-erema/vocaboost/internal/application/service/images"
+	"github.com/r-erema/vocaboost/internal/application/service/imagestorage"
+	"github.com/r-erema/vocaboost/internal/application/service/pronunciation"
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// fakeTextParser splits texts by whitespace and sentences by dots.
+type fakeTextParser struct {
+	err error
+}
+
+func (f fakeTextParser) ExtractWords(text string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return strings.Fields(text), nil
+}
+
+func (f fakeTextParser) FindSentence(text, word string) string {
+	for _, sentence := range strings.Split(text, ".") {
+		for _, sentenceWord := range strings.Fields(sentence) {
+			if strings.EqualFold(sentenceWord, word) {
+				return strings.TrimSpace(sentence) + "."
+			}
+		}
+	}
+
+	return ""
+}
+
+type fakeWordsRepo struct {
+	known, ignored map[string]bool
+	err            error
+}
+
+func newFakeWordsRepo(known, ignored []string) *fakeWordsRepo {
+	repo := &fakeWordsRepo{known: make(map[string]bool), ignored: make(map[string]bool), err: nil}
+
+	for _, word := range known {
+		repo.known[word] = true
+	}
+
+	for _, word := range ignored {
+		repo.ignored[word] = true
+	}
+
+	return repo
+}
+
+func (f *fakeWordsRepo) FilterKnownWords(_ context.Context, words []string) ([]string, error) {
+	return f.filter(words, f.known)
+}
+
+func (f *fakeWordsRepo) FilterIgnoredWords(_ context.Context, words []string) ([]string, error) {
+	return f.filter(words, f.ignored)
+}
+
+func (f *fakeWordsRepo) filter(words []string, saved map[string]bool) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	filtered := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if !saved[word] {
+			filtered = append(filtered, word)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (f *fakeWordsRepo) SaveAsKnown(_ context.Context, words []string) error {
+	return f.save(words, f.known)
+}
+
+func (f *fakeWordsRepo) SaveAsIgnored(_ context.Context, words []string) error {
+	return f.save(words, f.ignored)
+}
+
+func (f *fakeWordsRepo) save(words []string, saved map[string]bool) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, word := range words {
+		saved[word] = true
+	}
+
+	return nil
+}
+
+func (f *fakeWordsRepo) KnownWordsCount(context.Context) (int, error) {
+	return len(f.known), f.err
+}
+
+func (f *fakeWordsRepo) IgnoredWordsCount(context.Context) (int, error) {
+	return len(f.ignored), f.err
+}
+
+// fakeCardsRepo keeps the cards in memory, promoted words are recorded as known.
+type fakeCardsRepo struct {
+	cards map[string]*domain.Card
+	known map[string]bool
+	err   error
+}
+
+func newFakeCardsRepo(cards ...*domain.Card) *fakeCardsRepo {
+	repo := &fakeCardsRepo{cards: make(map[string]*domain.Card), known: make(map[string]bool), err: nil}
+
+	for _, card := range cards {
+		repo.cards[card.Word().Word()] = card
+	}
+
+	return repo
+}
+
+func (f *fakeCardsRepo) SaveCards(_ context.Context, cards []*domain.Card) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, card := range cards {
+		f.cards[card.Word().Word()] = card
+	}
+
+	return nil
+}
+
+func (f *fakeCardsRepo) Cards(_ context.Context, words []string) ([]*domain.Card, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	cards := make([]*domain.Card, len(words))
+	for i, word := range words {
+		cards[i] = f.cards[word]
+	}
+
+	return cards, nil
+}
+
+func (f *fakeCardsRepo) DueCards(_ context.Context, now time.Time, limit int) ([]*domain.Card, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	dueCards := make([]*domain.Card, 0, len(f.cards))
+
+	for _, card := range f.cards {
+		if !card.Schedule().Due().After(now) {
+			dueCards = append(dueCards, card)
+		}
+	}
+
+	sort.Slice(dueCards, func(i, j int) bool {
+		return dueCards[i].Schedule().Due().Before(dueCards[j].Schedule().Due())
+	})
+
+	if len(dueCards) > limit {
+		dueCards = dueCards[:limit]
+	}
+
+	return dueCards, nil
+}
+
+func (f *fakeCardsRepo) DueCardsCount(ctx context.Context, now time.Time) (int, error) {
+	dueCards, err := f.DueCards(ctx, now, len(f.cards))
+
+	return len(dueCards), err
+}
+
+func (f *fakeCardsRepo) DeleteCards(_ context.Context, words []string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, word := range words {
+		delete(f.cards, word)
+	}
+
+	return nil
+}
+
+func (f *fakeCardsRepo) PromoteCards(ctx context.Context, words []string) error {
+	if err := f.DeleteCards(ctx, words); err != nil {
+		return err
+	}
+
+	for _, word := range words {
+		f.known[word] = true
+	}
+
+	return nil
+}
+
+// fakeOfferedURLs remembers the saved URLs.
+type fakeOfferedURLs struct {
+	urls map[string]bool
+	err  error
+}
+
+func newFakeOfferedURLs(urls ...string) *fakeOfferedURLs {
+	repo := &fakeOfferedURLs{urls: make(map[string]bool), err: nil}
+	for _, offeredURL := range urls {
+		repo.urls[offeredURL] = true
+	}
+
+	return repo
+}
+
+func (f *fakeOfferedURLs) SaveOfferedURLs(_ context.Context, urls []string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	for _, offeredURL := range urls {
+		f.urls[offeredURL] = true
+	}
+
+	return nil
+}
+
+func (f *fakeOfferedURLs) FilterOfferedURLs(_ context.Context, urls []string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	offeredURLs := make([]string, 0, len(urls))
+
+	for _, offeredURL := range urls {
+		if f.urls[offeredURL] {
+			offeredURLs = append(offeredURLs, offeredURL)
+		}
+	}
+
+	return offeredURLs, nil
+}
+
+// fakeScheduler adds ten days per grade point above "again" to the interval.
+type fakeScheduler struct{}
+
+func (fakeScheduler) Schedule(schedule *domain.Schedule, grade domain.Grade, now time.Time) *domain.Schedule {
+	intervalDays := schedule.IntervalDays() + int(grade-domain.GradeAgain)*10 //nolint:gomnd
+
+	return domain.NewSchedule(
+		now.AddDate(0, 0, intervalDays), now, intervalDays, schedule.Repetitions()+1, schedule.Lapses(), 0, 0, 0,
+	)
+}
+
+// fakeDictionary knows the words it's given, unknown words are skipped as real dictionaries do.
+type fakeDictionary struct {
+	words map[string]*dictionary.WordInfoDTO
+	err   error
+}
+
+func (f fakeDictionary) WordsInfo(_ context.Context, words []string) ([]*dictionary.WordInfoDTO, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	wordsInfo := make([]*dictionary.WordInfoDTO, 0, len(words))
+
+	for _, word := range words {
+		if wordInfo, ok := f.words[word]; ok {
+			wordsInfo = append(wordsInfo, wordInfo)
+		}
+	}
+
+	return wordsInfo, nil
+}
+
+// fakeImages finds the images of the queries it's given and nothing for the rest.
+type fakeImages struct {
+	urls    map[string][]string
+	queries []string
+	err     error
+}
+
+func (f *fakeImages) Search(_ context.Context, queries []string) ([]*images.WordImagesDTO, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.queries = append(f.queries, queries...)
+
+	found := make([]*images.WordImagesDTO, len(queries))
+	for i, query := range queries {
+		found[i] = images.NewWordImagesDTO(query, f.urls[query])
+	}
+
+	return found, nil
+}
+
+// fakeImageFilter drops the rejected images.
+type fakeImageFilter struct {
+	rejected map[string]bool
+}
+
+func (f fakeImageFilter) Filter(_ context.Context, imageURLs []string) ([]string, error) {
+	kept := make([]string, 0, len(imageURLs))
+
+	for _, imageURL := range imageURLs {
+		if !f.rejected[imageURL] {
+			kept = append(kept, imageURL)
+		}
+	}
+
+	return kept, nil
+}
+
+// fakeTranslator translates texts into upper case.
+type fakeTranslator struct {
+	err error
+}
+
+func (f fakeTranslator) Translate(_ context.Context, texts []string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	translations := make([]string, len(texts))
+	for i, text := range texts {
+		translations[i] = strings.ToUpper(text)
+	}
+
+	return translations, nil
+}
+
+// fakePronunciation synthesizes audio files named after the words and records the words it was asked for.
+type fakePronunciation struct {
+	words []string
+	err   error
+}
+
+func (f *fakePronunciation) Audio(_ context.Context, words []string) ([]*pronunciation.WordAudioDTO, error) {
+	f.words = append(f.words, words...)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	audio := make([]*pronunciation.WordAudioDTO, len(words))
+	for i, word := range words {
+		audio[i] = pronunciation.NewWordAudioDTO(word, "https://audio.test/"+word+".mp3", "/audio/"+word+".mp3")
+	}
+
+	return audio, nil
+}
+
+type fakeWordFrequency struct {
+	ranks map[string]int
+}
+
+func (f fakeWordFrequency) Ranks(_ context.Context, words []string) ([]int, error) {
+	ranks := make([]int, len(words))
+	for i, word := range words {
+		ranks[i] = f.ranks[word]
+	}
+
+	return ranks, nil
+}
+
+// fakeClozes blanks the word out in the sentences which contain it.
+type fakeClozes struct{}
+
+func (fakeClozes) Clozes(word string, _, sentences []string) []*domain.Cloze {
+	clozes := make([]*domain.Cloze, 0, len(sentences))
+
+	for _, sentence := range sentences {
+		if parts := strings.SplitN(sentence, word, 2); len(parts) == 2 { //nolint:gomnd
+			clozes = append(clozes, domain.NewCloze([]string{parts[0], parts[1]}, []string{word}))
+		}
+	}
+
+	return clozes
+}
+
+// fakeImageStorage stores images under the storage URL, the images it fails to store are skipped.
+type fakeImageStorage struct {
+	failed map[string]bool
+}
+
+func (f fakeImageStorage) Store(_ context.Context, imageURLs []string) ([]*imagestorage.StoredImageDTO, error) {
+	stored := make([]*imagestorage.StoredImageDTO, 0, len(imageURLs))
+
+	for _, imageURL := range imageURLs {
+		if f.failed[imageURL] {
+			continue
+		}
+
+		name := imageURL[strings.LastIndex(imageURL, "/")+1:]
+		stored = append(stored, imagestorage.NewStoredImageDTO(imageURL, "https://storage.test/"+name, "/images/"+name))
+	}
+
+	return stored, nil
+}
+
+// fakeSpacedRepetition creates every uploaded word except the failing ones.
+type fakeSpacedRepetition struct {
+	failed   map[string]error
+	uploaded []*domain.Word
+	err      error
+}
+
+func (f *fakeSpacedRepetition) Name() string {
+	return "fake"
+}
+
+func (f *fakeSpacedRepetition) UploadWords(
+	_ context.Context,
+	words []*domain.Word,
+) ([]*spacedrepetition.UploadReportDTO, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.uploaded = append(f.uploaded, words...)
+
+	results := make([]*spacedrepetition.WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		results[i] = spacedrepetition.NewWordUploadResultDTO(word.Word(), spacedrepetition.UploadStatusCreated, nil)
+		if err := f.failed[word.Word()]; err != nil {
+			results[i] = spacedrepetition.NewWordUploadResultDTO(word.Word(), spacedrepetition.UploadStatusFailed, err)
+		}
+	}
+
+	return []*spacedrepetition.UploadReportDTO{spacedrepetition.NewUploadReportDTO(f.Name(), results, "")}, nil
+}
@@ -0,0 +1,340 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+	"github.com/r-erema/vocaboost/internal/application/service/imagequality"
+	"github.com/r-erema/vocaboost/internal/application/service/images"
+	"github.com/r-erema/vocaboost/internal/application/service/textparser"
+)
+
+var errBadImagesQuery = errors.New("found images query and searched query aren't equal")
+
+// queriesImages are images of a word found by every images query template, in the templates order.
+type queriesImages [][]string
+
+// ImagesShortage describes a word whose images searches found fewer images than the images quotas sum up to.
+type ImagesShortage struct {
+	Word  string
+	Found int
+}
+
+// LookUpWords collects the card material candidates of words: definitions, examples, pronunciation and images.
+type LookUpWords struct {
+	dictionary    dictionary.Interface
+	images        images.Interface
+	imagesQueries []*images.QueryTemplate
+	imageFilter   imagequality.Interface
+	textParser    textparser.Interface
+	offeredURLs   repository.OfferedURLsInterface
+	// maxDefinitions and maxExamples are how many of the first definitions and examples are selected.
+	maxDefinitions int
+	maxExamples    int
+}
+
+// NewLookUpWords creates the look up, the image filter is optional.
+func NewLookUpWords(
+	dictionaryService dictionary.Interface,
+	imagesService images.Interface,
+	imagesQueries []*images.QueryTemplate,
+	imageFilter imagequality.Interface,
+	textParser textparser.Interface,
+	offeredURLs repository.OfferedURLsInterface,
+	maxDefinitions, maxExamples int,
+) *LookUpWords {
+	return &LookUpWords{
+		dictionary:     dictionaryService,
+		images:         imagesService,
+		imagesQueries:  imagesQueries,
+		imageFilter:    imageFilter,
+		textParser:     textParser,
+		offeredURLs:    offeredURLs,
+		maxDefinitions: maxDefinitions,
+		maxExamples:    maxExamples,
+	}
+}
+
+// RunText looks up the words written in the text, e.g. a list the user has typed in.
+func (l LookUpWords) RunText(ctx context.Context, wordsText, sourceText string) ([]*WordCandidates, error) {
+	words, err := l.textParser.ExtractWords(wordsText)
+	if err != nil {
+		return nil, fmt.Errorf("extracting words error: %w", err)
+	}
+
+	return l.Run(ctx, words, sourceText)
+}
+
+// Run returns the candidates of the words which are found in the dictionary and finds the sentences of the source
+// text the words were met in. The first definitions and examples and the images within the quotas are selected.
+// The audio and images URLs of the candidates are saved as offered, cards can be built only with offered media.
+func (l LookUpWords) Run(ctx context.Context, words []string, sourceText string) ([]*WordCandidates, error) {
+	wordsInfo, err := l.dictionary.WordsInfo(ctx, normalizeWords(words))
+	if err != nil {
+		return nil, fmt.Errorf("getting words info error: %w", err)
+	}
+
+	wordsImages, err := l.searchImages(ctx, wordsInfo)
+	if err != nil {
+		return nil, fmt.Errorf("getting words images error: %w", err)
+	}
+
+	if wordsImages, err = l.filterImages(ctx, wordsImages); err != nil {
+		return nil, fmt.Errorf("filtering words images error: %w", err)
+	}
+
+	candidates := make([]*WordCandidates, len(wordsInfo))
+
+	for i, wordInfo := range wordsInfo {
+		candidates[i] = &WordCandidates{
+			Word:           wordInfo.Word(),
+			IPA:            "",
+			Syllables:      make([]string, 0),
+			AudioURL:       "",
+			SourceSentence: l.textParser.FindSentence(sourceText, wordInfo.Word()),
+			Definitions:    make([]*DefinitionCandidate, len(wordInfo.Definitions())),
+			Examples:       make([]*Candidate, len(wordInfo.Examples())),
+			Images:         imagesCandidates(wordsImages[i], ImagesQuotas(l.imagesQueries)),
+		}
+
+		if wordInfo.Pronunciation() != nil {
+			candidates[i].IPA = wordInfo.Pronunciation().IPA()
+			candidates[i].Syllables = append(candidates[i].Syllables, wordInfo.Pronunciation().Syllables()...)
+			candidates[i].AudioURL = wordInfo.Pronunciation().AudioURL()
+		}
+
+		for j, definition := range wordInfo.Definitions() {
+			candidates[i].Definitions[j] = &DefinitionCandidate{
+				Definition: Definition{
+					PartOfSpeech: definition.PartOfSpeech(),
+					Gloss:        definition.Definition(),
+					Synonyms:     definition.Synonyms(),
+					Antonyms:     definition.Antonyms(),
+				},
+				Selected: j < l.maxDefinitions,
+			}
+		}
+
+		for j, example := range wordInfo.Examples() {
+			candidates[i].Examples[j] = &Candidate{Value: example, Selected: j < l.maxExamples}
+		}
+	}
+
+	if err = l.offeredURLs.SaveOfferedURLs(ctx, offeredMediaURLs(candidates)); err != nil {
+		return nil, fmt.Errorf("saving offered URLs error: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func offeredMediaURLs(candidates []*WordCandidates) []string {
+	urls := make([]string, 0)
+
+	for _, candidate := range candidates {
+		if candidate.AudioURL != "" {
+			urls = append(urls, candidate.AudioURL)
+		}
+
+		for _, image := range candidate.Images {
+			urls = append(urls, image.Value)
+		}
+	}
+
+	return urls
+}
+
+// searchImages searches images of every word with every images query template. A query is built from the word,
+// its first definition and that definition's part of speech.
+func (l LookUpWords) searchImages(ctx context.Context, wordsInfo []*dictionary.WordInfoDTO) ([]queriesImages, error) {
+	wordsImages := make([]queriesImages, len(wordsInfo))
+	for i := range wordsImages {
+		wordsImages[i] = make(queriesImages, len(l.imagesQueries))
+	}
+
+	for j, imagesQuery := range l.imagesQueries {
+		queries := make([]string, len(wordsInfo))
+
+		for i, wordInfo := range wordsInfo {
+			var partOfSpeech, definition string
+			if len(wordInfo.Definitions()) > 0 {
+				partOfSpeech = wordInfo.Definitions()[0].PartOfSpeech()
+				definition = wordInfo.Definitions()[0].Definition()
+			}
+
+			queries[i] = imagesQuery.Query(wordInfo.Word(), partOfSpeech, definition)
+		}
+
+		foundImages, err := l.images.Search(ctx, queries)
+		if err != nil {
+			return nil, fmt.Errorf("searching images by template `%s` error: %w", imagesQuery.Template(), err)
+		}
+
+		for i := range queries {
+			if foundImages[i].Word() != queries[i] {
+				return nil, fmt.Errorf(
+					"%w, found images query: %s, searched query: %s", errBadImagesQuery, foundImages[i].Word(), queries[i],
+				)
+			}
+
+			wordsImages[i][j] = foundImages[i].Urls()
+		}
+	}
+
+	return wordsImages, nil
+}
+
+// filterImages drops low quality images and near-duplicates of all the queries of a word at once,
+// since they often return the same picture. The spare results of the searches make up for the dropped ones.
+// Without a configured image filter the images are returned as they are.
+func (l LookUpWords) filterImages(ctx context.Context, wordsImages []queriesImages) ([]queriesImages, error) {
+	if l.imageFilter == nil {
+		return wordsImages, nil
+	}
+
+	filteredImages := make([]queriesImages, len(wordsImages))
+
+	for i, wordImages := range wordsImages {
+		keptURLs, err := l.imageFilter.Filter(ctx, wordImages.all())
+		if err != nil {
+			return nil, fmt.Errorf("filtering images error: %w", err)
+		}
+
+		kept := make(map[string]struct{}, len(keptURLs))
+		for _, imageURL := range keptURLs {
+			kept[imageURL] = struct{}{}
+		}
+
+		filteredImages[i] = make(queriesImages, len(wordImages))
+
+		for j, queryImages := range wordImages {
+			filteredImages[i][j] = make([]string, 0, len(queryImages))
+
+			for _, imageURL := range queryImages {
+				if _, isKept := kept[imageURL]; isKept {
+					filteredImages[i][j] = append(filteredImages[i][j], imageURL)
+				}
+			}
+		}
+	}
+
+	return filteredImages, nil
+}
+
+// ImagesShortages lists the words whose images searches found fewer images than a card gets at most.
+// The images dropped by the image filter aren't counted, the ones the user doesn't pick later on are.
+func (l LookUpWords) ImagesShortages(candidates []*WordCandidates) []*ImagesShortage {
+	maxImages := l.MaxImages()
+	shortages := make([]*ImagesShortage, 0)
+
+	for _, candidate := range candidates {
+		if len(candidate.Images) < maxImages {
+			shortages = append(shortages, &ImagesShortage{Word: candidate.Word, Found: len(candidate.Images)})
+		}
+	}
+
+	return shortages
+}
+
+// MaxImages is how many images a card gets at most.
+func (l LookUpWords) MaxImages() int {
+	return MaxImages(l.imagesQueries)
+}
+
+// ImagesQuotas are the quotas of the images query templates in the templates order.
+func ImagesQuotas(imagesQueries []*images.QueryTemplate) []int {
+	quotas := make([]int, len(imagesQueries))
+	for i := range imagesQueries {
+		quotas[i] = imagesQueries[i].Quota()
+	}
+
+	return quotas
+}
+
+// MaxImages is how many images a card gets at most, the sum of the images quotas.
+func MaxImages(imagesQueries []*images.QueryTemplate) int {
+	var maxImages int
+	for _, quota := range ImagesQuotas(imagesQueries) {
+		maxImages += quota
+	}
+
+	return maxImages
+}
+
+// imagesCandidates lists images of all the queries without duplicates, the ones selectImages picks are selected.
+func imagesCandidates(wordImages queriesImages, quotas []int) []*Candidate {
+	selectedImages := selectImages(wordImages, quotas)
+
+	selected := make(map[string]struct{}, len(selectedImages))
+	for _, imageURL := range selectedImages {
+		selected[imageURL] = struct{}{}
+	}
+
+	allImages := wordImages.all()
+	candidates := make([]*Candidate, len(allImages))
+
+	for i, imageURL := range allImages {
+		_, isSelected := selected[imageURL]
+		candidates[i] = &Candidate{Value: imageURL, Selected: isSelected}
+	}
+
+	return candidates
+}
+
+// selectImages takes up to the quota of images from each query and fills the rest up to the sum of the quotas
+// from the queries which have spare images, in the queries order. Duplicates are skipped,
+// the result may have fewer images than the quotas sum up to or none.
+func selectImages(wordImages queriesImages, quotas []int) []string {
+	var maxImages int
+	for _, quota := range quotas {
+		maxImages += quota
+	}
+
+	result := make([]string, 0, maxImages)
+	seen := make(map[string]struct{}, maxImages)
+
+	takeImages := func(urls []string, limit int) []string {
+		for i := range urls {
+			if len(result) >= limit {
+				return urls[i:]
+			}
+
+			if _, exists := seen[urls[i]]; !exists {
+				seen[urls[i]] = struct{}{}
+				result = append(result, urls[i])
+			}
+		}
+
+		return nil
+	}
+
+	imagesLeft := make(queriesImages, len(wordImages))
+	for i, queryImages := range wordImages {
+		imagesLeft[i] = takeImages(queryImages, len(result)+quotas[i])
+	}
+
+	for _, queryImagesLeft := range imagesLeft {
+		takeImages(queryImagesLeft, maxImages)
+	}
+
+	return result
+}
+
+// all lists images of all the queries without duplicates.
+func (qi queriesImages) all() []string {
+	urls := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, queryImages := range qi {
+		for _, imageURL := range queryImages {
+			if _, exists := seen[imageURL]; !exists {
+				seen[imageURL] = struct{}{}
+				urls = append(urls, imageURL)
+			}
+		}
+	}
+
+	return urls
+}
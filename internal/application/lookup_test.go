@@ -0,0 +1,416 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+	"github.com/r-erema/vocaboost/internal/application/service/images"
+)
+
+const (
+	testMaxDefinitions = 4
+	testMaxExamples    = 4
+)
+
+func testDictionary() fakeDictionary {
+	return fakeDictionary{
+		words: map[string]*dictionary.WordInfoDTO{
+			"cat": dictionary.NewWordInfoDTO(
+				"cat",
+				[]*dictionary.DefinitionDTO{
+					dictionary.NewDefinition("a small animal", "noun", []string{"feline"}, nil),
+					dictionary.NewDefinition("a person", "noun", nil, nil),
+					dictionary.NewDefinition("a whip", "noun", nil, nil),
+					dictionary.NewDefinition("a jazz player", "noun", nil, nil),
+					dictionary.NewDefinition("to vomit", "verb", nil, nil),
+				},
+				[]string{"a black cat"},
+				dictionary.NewPronunciationDTO("kæt", []string{"cat"}, "https://audio.test/cat.mp3"),
+			),
+			"purr": dictionary.NewWordInfoDTO("purr", nil, nil, nil),
+		},
+		err: nil,
+	}
+}
+
+func TestLookUpWords(t *testing.T) {
+	t.Parallel()
+
+	imagesService := &fakeImages{
+		urls: map[string][]string{
+			"cat illustration": {"https://img.test/1.jpg", "https://img.test/2.jpg", "https://img.test/3.jpg"},
+			"a small animal":   {"https://img.test/2.jpg", "https://img.test/4.jpg", "https://img.test/5.jpg"},
+		},
+		queries: nil,
+		err:     nil,
+	}
+
+	offeredURLs := newFakeOfferedURLs()
+	lookUp := application.NewLookUpWords(
+		testDictionary(),
+		imagesService,
+		[]*images.QueryTemplate{
+			images.NewQueryTemplate("{word} illustration", 1),
+			images.NewQueryTemplate("{definition}", 2),
+		},
+		fakeImageFilter{rejected: map[string]bool{"https://img.test/4.jpg": true}},
+		fakeTextParser{err: nil},
+		offeredURLs,
+		testMaxDefinitions,
+		testMaxExamples,
+	)
+
+	candidates, err := lookUp.Run(context.Background(), []string{" Cat", "dog", "purr"}, "A dog sleeps. The cat purrs.")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(candidates) != 2 { //nolint:gomnd
+		t.Fatalf("got %d words, want 2", len(candidates))
+	}
+
+	cat := candidates[0]
+	if cat.Word != "cat" || cat.IPA != "kæt" || cat.AudioURL != "https://audio.test/cat.mp3" ||
+		cat.SourceSentence != "The cat purrs." || !reflect.DeepEqual(cat.Syllables, []string{"cat"}) {
+		t.Errorf("got word %+v", cat)
+	}
+
+	selectedDefinitions := make([]bool, len(cat.Definitions))
+	for i, definition := range cat.Definitions {
+		selectedDefinitions[i] = definition.Selected
+	}
+
+	if want := []bool{true, true, true, true, false}; !reflect.DeepEqual(selectedDefinitions, want) {
+		t.Errorf("got selected definitions %v, want %v", selectedDefinitions, want)
+	}
+
+	if !reflect.DeepEqual(cat.Definitions[0].Synonyms, []string{"feline"}) {
+		t.Errorf("got synonyms %q, want feline", cat.Definitions[0].Synonyms)
+	}
+
+	wantImages := []*application.Candidate{
+		{Value: "https://img.test/1.jpg", Selected: true},
+		{Value: "https://img.test/2.jpg", Selected: true},
+		{Value: "https://img.test/3.jpg", Selected: false},
+		{Value: "https://img.test/5.jpg", Selected: true},
+	}
+	if !reflect.DeepEqual(cat.Images, wantImages) {
+		t.Errorf("got images %+v, want %+v", cat.Images, wantImages)
+	}
+
+	if want := []string{"cat illustration", "purr illustration", "a small animal", "purr"}; !reflect.DeepEqual(
+		imagesService.queries, want,
+	) {
+		t.Errorf("got images queries %q, want %q", imagesService.queries, want)
+	}
+
+	wantOfferedURLs := map[string]bool{
+		"https://audio.test/cat.mp3": true,
+		"https://img.test/1.jpg":     true,
+		"https://img.test/2.jpg":     true,
+		"https://img.test/3.jpg":     true,
+		"https://img.test/5.jpg":     true,
+	}
+	if !reflect.DeepEqual(offeredURLs.urls, wantOfferedURLs) {
+		t.Errorf("got offered URLs %v, want %v", offeredURLs.urls, wantOfferedURLs)
+	}
+
+	if purr := candidates[1]; len(purr.Definitions) != 0 || len(purr.Images) != 0 || purr.IPA != "" {
+		t.Errorf("got word %+v, want no material", purr)
+	}
+}
+
+func TestLookUpWordsSelectsOnlyTheFirstCandidates(t *testing.T) {
+	t.Parallel()
+
+	definitions := make([]*dictionary.DefinitionDTO, 0)
+	examples := make([]string, 0)
+
+	for _, number := range []string{"one", "two", "three", "four", "five", "six"} {
+		definitions = append(definitions, dictionary.NewDefinition("meaning "+number, "noun", nil, nil))
+		examples = append(examples, "example "+number)
+	}
+
+	provider := fakeDictionary{
+		words: map[string]*dictionary.WordInfoDTO{"cat": dictionary.NewWordInfoDTO("cat", definitions, examples, nil)},
+		err:   nil,
+	}
+
+	lookUp := application.NewLookUpWords(
+		dictionary.NewChain(len(definitions), len(examples), provider),
+		&fakeImages{urls: nil, queries: nil, err: nil},
+		[]*images.QueryTemplate{images.NewQueryTemplate("{word}", 1)},
+		nil,
+		fakeTextParser{err: nil},
+		newFakeOfferedURLs(),
+		2, //nolint:gomnd
+		3, //nolint:gomnd
+	)
+
+	candidates, err := lookUp.Run(context.Background(), []string{"cat"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	selectedDefinitions := make([]bool, len(candidates[0].Definitions))
+	for i, definition := range candidates[0].Definitions {
+		selectedDefinitions[i] = definition.Selected
+	}
+
+	if want := []bool{true, true, false, false, false, false}; !reflect.DeepEqual(selectedDefinitions, want) {
+		t.Errorf("got selected definitions %v, want %v", selectedDefinitions, want)
+	}
+
+	selectedExamples := make([]bool, len(candidates[0].Examples))
+	for i, example := range candidates[0].Examples {
+		selectedExamples[i] = example.Selected
+	}
+
+	if want := []bool{true, true, true, false, false, false}; !reflect.DeepEqual(selectedExamples, want) {
+		t.Errorf("got selected examples %v, want %v", selectedExamples, want)
+	}
+}
+
+func TestLookUpWordsFillsImagesUpFromSpareOnes(t *testing.T) {
+	t.Parallel()
+
+	lookUp := application.NewLookUpWords(
+		testDictionary(),
+		&fakeImages{
+			urls: map[string][]string{
+				"cat": {"https://img.test/1.jpg", "https://img.test/2.jpg", "https://img.test/3.jpg"},
+			},
+			queries: nil,
+			err:     nil,
+		},
+		[]*images.QueryTemplate{images.NewQueryTemplate("{word}", 1), images.NewQueryTemplate("{word} drawing", 1)},
+		nil,
+		fakeTextParser{err: nil},
+		newFakeOfferedURLs(),
+		testMaxDefinitions,
+		testMaxExamples,
+	)
+
+	candidates, err := lookUp.Run(context.Background(), []string{"cat"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantImages := []*application.Candidate{
+		{Value: "https://img.test/1.jpg", Selected: true},
+		{Value: "https://img.test/2.jpg", Selected: true},
+		{Value: "https://img.test/3.jpg", Selected: false},
+	}
+	if !reflect.DeepEqual(candidates[0].Images, wantImages) {
+		t.Errorf("got images %+v, want %+v", candidates[0].Images, wantImages)
+	}
+}
+
+func TestLookUpWordsSelectsImagesWithinQueryQuotas(t *testing.T) {
+	t.Parallel()
+
+	found := map[string][]string{
+		"cat":         {"https://img.test/1.jpg", "https://img.test/2.jpg", "https://img.test/3.jpg"},
+		"cat drawing": {"https://img.test/2.jpg", "https://img.test/4.jpg", "https://img.test/5.jpg"},
+		"cat photo":   {"https://img.test/6.jpg"},
+	}
+
+	for name, testCase := range map[string]struct {
+		queries      []*images.QueryTemplate
+		wantSelected []string
+	}{
+		"takes each quota": {
+			queries: []*images.QueryTemplate{
+				images.NewQueryTemplate("{word}", 2), images.NewQueryTemplate("{word} drawing", 1),
+			},
+			wantSelected: []string{"https://img.test/1.jpg", "https://img.test/2.jpg", "https://img.test/4.jpg"},
+		},
+		"skips duplicates of earlier queries": {
+			queries: []*images.QueryTemplate{
+				images.NewQueryTemplate("{word}", 2), images.NewQueryTemplate("{word} drawing", 2),
+			},
+			wantSelected: []string{
+				"https://img.test/1.jpg", "https://img.test/2.jpg", "https://img.test/4.jpg", "https://img.test/5.jpg",
+			},
+		},
+		"fills up a short query in the queries order": {
+			queries: []*images.QueryTemplate{
+				images.NewQueryTemplate("{word} photo", 3),
+				images.NewQueryTemplate("{word} drawing", 1),
+				images.NewQueryTemplate("{word}", 1),
+			},
+			wantSelected: []string{
+				"https://img.test/6.jpg",
+				"https://img.test/2.jpg",
+				"https://img.test/1.jpg",
+				"https://img.test/4.jpg",
+				"https://img.test/5.jpg",
+			},
+		},
+		"selects fewer when all the queries are short": {
+			queries: []*images.QueryTemplate{
+				images.NewQueryTemplate("{word} photo", 2), images.NewQueryTemplate("{word} sketch", 2),
+			},
+			wantSelected: []string{"https://img.test/6.jpg"},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lookUp := application.NewLookUpWords(
+				testDictionary(),
+				&fakeImages{urls: found, queries: nil, err: nil},
+				testCase.queries,
+				nil,
+				fakeTextParser{err: nil},
+				newFakeOfferedURLs(),
+				testMaxDefinitions,
+				testMaxExamples,
+			)
+
+			candidates, err := lookUp.Run(context.Background(), []string{"cat"}, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			selected := make(map[string]bool)
+			for _, image := range candidates[0].Images {
+				selected[image.Value] = image.Selected
+			}
+
+			wantSelected := make(map[string]bool)
+			for _, imageURL := range testCase.wantSelected {
+				wantSelected[imageURL] = true
+			}
+
+			for imageURL, isSelected := range selected {
+				if isSelected != wantSelected[imageURL] {
+					t.Errorf("got image %s selected: %t, want selected %q", imageURL, isSelected, testCase.wantSelected)
+				}
+			}
+		})
+	}
+}
+
+func TestLookUpWordsImagesShortages(t *testing.T) {
+	t.Parallel()
+
+	lookUp := application.NewLookUpWords(
+		testDictionary(),
+		&fakeImages{
+			urls: map[string][]string{
+				"cat":         {"https://img.test/1.jpg", "https://img.test/2.jpg"},
+				"cat drawing": {"https://img.test/2.jpg", "https://img.test/3.jpg"},
+				"purr":        {"https://img.test/4.jpg", "https://img.test/5.jpg"},
+			},
+			queries: nil,
+			err:     nil,
+		},
+		[]*images.QueryTemplate{images.NewQueryTemplate("{word}", 1), images.NewQueryTemplate("{word} drawing", 2)},
+		fakeImageFilter{rejected: map[string]bool{"https://img.test/5.jpg": true}},
+		fakeTextParser{err: nil},
+		newFakeOfferedURLs(),
+		testMaxDefinitions,
+		testMaxExamples,
+	)
+
+	candidates, err := lookUp.Run(context.Background(), []string{"cat", "purr"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Unpicking images later on doesn't make a shortage, it's about what the searches found.
+	for _, image := range candidates[0].Images {
+		image.Selected = false
+	}
+
+	// The filtered out image isn't counted.
+	want := []*application.ImagesShortage{{Word: "purr", Found: 1}}
+	if shortages := lookUp.ImagesShortages(candidates); !reflect.DeepEqual(shortages, want) {
+		t.Errorf("got shortages %+v, want %+v", shortages, want)
+	}
+
+	if maxImages := lookUp.MaxImages(); maxImages != 3 { //nolint:gomnd
+		t.Errorf("got %d max images, want 3", maxImages)
+	}
+}
+
+func TestLookUpWordsRunText(t *testing.T) {
+	t.Parallel()
+
+	lookUp := application.NewLookUpWords(
+		testDictionary(),
+		&fakeImages{urls: nil, queries: nil, err: nil},
+		[]*images.QueryTemplate{images.NewQueryTemplate("{word}", 1)},
+		nil,
+		fakeTextParser{err: nil},
+		newFakeOfferedURLs(),
+		testMaxDefinitions,
+		testMaxExamples,
+	)
+
+	candidates, err := lookUp.RunText(context.Background(), "purr\n CAT dog", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	words := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		words[i] = candidate.Word
+	}
+
+	if want := []string{"purr", "cat"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("got words %q, want %q", words, want)
+	}
+}
+
+func TestLookUpWordsFails(t *testing.T) {
+	t.Parallel()
+
+	queries := []*images.QueryTemplate{images.NewQueryTemplate("{word}", 1)}
+	failingDictionary := testDictionary()
+	failingDictionary.err = errTest
+	failingOfferedURLs := newFakeOfferedURLs()
+	failingOfferedURLs.err = errTest
+
+	for name, lookUp := range map[string]*application.LookUpWords{
+		"dictionary": application.NewLookUpWords(
+			failingDictionary, &fakeImages{urls: nil, queries: nil, err: nil}, queries, nil, fakeTextParser{err: nil},
+			newFakeOfferedURLs(), testMaxDefinitions, testMaxExamples,
+		),
+		"images": application.NewLookUpWords(
+			testDictionary(), &fakeImages{urls: nil, queries: nil, err: errTest}, queries, nil, fakeTextParser{err: nil},
+			newFakeOfferedURLs(), testMaxDefinitions, testMaxExamples,
+		),
+		"offered URLs": application.NewLookUpWords(
+			testDictionary(), &fakeImages{urls: nil, queries: nil, err: nil}, queries, nil, fakeTextParser{err: nil},
+			failingOfferedURLs, testMaxDefinitions, testMaxExamples,
+		),
+	} {
+		lookUp := lookUp
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := lookUp.Run(context.Background(), []string{"cat"}, ""); !errors.Is(err, errTest) {
+				t.Errorf("got error %v, want %v", err, errTest)
+			}
+		})
+	}
+}
+
+func TestMaxImages(t *testing.T) {
+	t.Parallel()
+
+	queries := []*images.QueryTemplate{images.NewQueryTemplate("{word}", 2), images.NewQueryTemplate("{definition}", 3)}
+
+	if maxImages := application.MaxImages(queries); maxImages != 5 { //nolint:gomnd
+		t.Errorf("got %d max images, want 5", maxImages)
+	}
+}
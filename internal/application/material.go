@@ -0,0 +1,48 @@
+package application
+
+// Candidate is a piece of card material offered for a word, the selected candidates are the ones a card
+// gets unless the user picks others.
+type Candidate struct {
+	Value    string
+	Selected bool
+}
+
+// Definition is a definition of a word as it goes to a card.
+type Definition struct {
+	PartOfSpeech string
+	Gloss        string
+	Synonyms     []string
+	Antonyms     []string
+}
+
+type DefinitionCandidate struct {
+	Definition
+	Selected bool
+}
+
+// WordCandidates is the card material offered for a word, the user picks the material of the card from it.
+type WordCandidates struct {
+	Word      string
+	IPA       string
+	Syllables []string
+	AudioURL  string
+	// SourceSentence is the sentence of the source text the word was met in.
+	SourceSentence string
+	Definitions    []*DefinitionCandidate
+	Examples       []*Candidate
+	Images         []*Candidate
+}
+
+// CardMaterial is the picked card material of a word, all of it goes to the card.
+type CardMaterial struct {
+	Word      string
+	IPA       string
+	Syllables []string
+	// AudioURL is synthesized audio if it's empty and a pronunciation service is configured.
+	AudioURL       string
+	SourceSentence string
+	Tags           []string
+	Definitions    []*Definition
+	Examples       []string
+	Images         []string
+}
@@ -0,0 +1,34 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// PublishReport is the outcome of a publication.
+type PublishReport struct {
+	// Uploads has a report per target the cards went to.
+	Uploads []*spacedrepetition.UploadReportDTO
+}
+
+// PublishCards uploads cards to the spaced repetition service.
+type PublishCards struct {
+	spacedRepetition spacedrepetition.Interface
+}
+
+func NewPublishCards(spacedRepetitionService spacedrepetition.Interface) *PublishCards {
+	return &PublishCards{spacedRepetition: spacedRepetitionService}
+}
+
+// Run keeps going past failures of single cards, they're reported per target.
+func (p PublishCards) Run(ctx context.Context, words []*domain.Word) (*PublishReport, error) {
+	uploads, err := p.spacedRepetition.UploadWords(ctx, words)
+	if err != nil {
+		return nil, fmt.Errorf("uploading words to the spaced repetition service error: %w", err)
+	}
+
+	return &PublishReport{Uploads: uploads}, nil
+}
@@ -0,0 +1,58 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+func testWord(word string) *domain.Word {
+	return domain.NewWord(word, "", "", nil, nil, nil, nil, 0, nil)
+}
+
+func TestPublishCards(t *testing.T) {
+	t.Parallel()
+
+	srs := &fakeSpacedRepetition{failed: map[string]error{"dog": errTest}, uploaded: nil, err: nil}
+	words := []*domain.Word{testWord("cat"), testWord("dog"), testWord("purr")}
+
+	report, err := application.NewPublishCards(srs).Run(context.Background(), words)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(srs.uploaded, words) {
+		t.Errorf("got uploaded %+v, want %+v", srs.uploaded, words)
+	}
+
+	if len(report.Uploads) != 1 || report.Uploads[0].Target() != "fake" {
+		t.Fatalf("got upload reports %+v, want the fake target report", report.Uploads)
+	}
+
+	statuses := make([]spacedrepetition.UploadStatus, len(report.Uploads[0].Words()))
+	for i, result := range report.Uploads[0].Words() {
+		statuses[i] = result.Status()
+	}
+
+	want := []spacedrepetition.UploadStatus{
+		spacedrepetition.UploadStatusCreated, spacedrepetition.UploadStatusFailed, spacedrepetition.UploadStatusCreated,
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("got statuses %q, want %q", statuses, want)
+	}
+}
+
+func TestPublishCardsFails(t *testing.T) {
+	t.Parallel()
+
+	srs := &fakeSpacedRepetition{failed: nil, uploaded: nil, err: errTest}
+
+	if _, err := application.NewPublishCards(srs).Run(context.Background(), nil); !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+}
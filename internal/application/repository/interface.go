@@ -1,6 +1,11 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
 
 type Interface interface {
 	FilterKnownWords(ctx context.Context, words []string) ([]string, error)
@@ -10,3 +15,33 @@ type Interface interface {
 	KnownWordsCount(ctx context.Context) (int, error)
 	IgnoredWordsCount(ctx context.Context) (int, error)
 }
+
+// CardsInterface keeps cards under review, a card is identified by its word.
+type CardsInterface interface {
+	// SaveCards adds new cards and replaces existing ones.
+	SaveCards(ctx context.Context, cards []*domain.Card) error
+	// Cards returns the cards of the words in the same order, nil for words without a card.
+	Cards(ctx context.Context, words []string) ([]*domain.Card, error)
+	// DueCards returns up to the limit of cards due at the time, the longest overdue first.
+	DueCards(ctx context.Context, now time.Time, limit int) ([]*domain.Card, error)
+	DueCardsCount(ctx context.Context, now time.Time) (int, error)
+	DeleteCards(ctx context.Context, words []string) error
+	// PromoteCards deletes the cards and saves their words as known at once, so a word is never left
+	// both known and under review, or neither.
+	PromoteCards(ctx context.Context, words []string) error
+}
+
+// SyncCursorsInterface keeps the position a sync has reached, so the next run continues from there.
+type SyncCursorsInterface interface {
+	// SyncCursor returns the zero time if the sync has never run.
+	SyncCursor(ctx context.Context, sync string) (time.Time, error)
+	SaveSyncCursor(ctx context.Context, sync string, cursor time.Time) error
+}
+
+// OfferedURLsInterface remembers the media URLs looked up words were offered with, so cards get only media
+// the service has found itself and not any URL a client sends.
+type OfferedURLsInterface interface {
+	SaveOfferedURLs(ctx context.Context, urls []string) error
+	// FilterOfferedURLs returns the URLs which were offered in the same order.
+	FilterOfferedURLs(ctx context.Context, urls []string) ([]string, error)
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	cardsPrefix = "c:"
+	// cardsDueKey is a sorted set of words scored by the due time of their cards in Unix seconds.
+	cardsDueKey = "cards_due"
+)
+
+var errUnexpectedCardValue = errors.New("unexpected card value type")
+
+// RedisCardsRepo stores cards as JSON, the due set orders them for reviews. Promoted words are saved
+// under the keys of RedisWordsRepo, so both repositories must use the same database.
+type RedisCardsRepo struct {
+	client *redis.Client
+}
+
+func NewRedisCardsRepo(client *redis.Client) *RedisCardsRepo {
+	return &RedisCardsRepo{client: client}
+}
+
+type cardRecord struct {
+	Word           string               `json:"word"`
+	Translation    string               `json:"translation"`
+	SourceSentence string               `json:"source_sentence"`
+	Definitions    []definitionRecord   `json:"definitions"`
+	Examples       []string             `json:"examples"`
+	Images         []imageRecord        `json:"images"`
+	Pronunciation  *pronunciationRecord `json:"pronunciation"`
+	FrequencyRank  int                  `json:"frequency_rank"`
+	Tags           []string             `json:"tags"`
+	Clozes         []clozeRecord        `json:"clozes"`
+	Schedule       scheduleRecord       `json:"schedule"`
+}
+
+type definitionRecord struct {
+	PartOfSpeech string   `json:"part_of_speech"`
+	Gloss        string   `json:"gloss"`
+	Translation  string   `json:"translation"`
+	Synonyms     []string `json:"synonyms"`
+	Antonyms     []string `json:"antonyms"`
+}
+
+type imageRecord struct {
+	URL      string `json:"url"`
+	FilePath string `json:"file_path"`
+}
+
+type pronunciationRecord struct {
+	IPA           string   `json:"ipa"`
+	Syllables     []string `json:"syllables"`
+	AudioURL      string   `json:"audio_url"`
+	AudioFilePath string   `json:"audio_file_path"`
+}
+
+type clozeRecord struct {
+	Segments []string `json:"segments"`
+	Answers  []string `json:"answers"`
+}
+
+type scheduleRecord struct {
+	Due          time.Time `json:"due"`
+	LastReview   time.Time `json:"last_review"`
+	IntervalDays int       `json:"interval_days"`
+	Repetitions  int       `json:"repetitions"`
+	Lapses       int       `json:"lapses"`
+	EaseFactor   float64   `json:"ease_factor"`
+	Stability    float64   `json:"stability"`
+	Difficulty   float64   `json:"difficulty"`
+}
+
+func (rcr RedisCardsRepo) SaveCards(ctx context.Context, cards []*domain.Card) error {
+	if len(cards) == 0 {
+		return nil
+	}
+
+	pipe := rcr.client.TxPipeline()
+
+	for _, card := range cards {
+		value, err := json.Marshal(newCardRecord(card))
+		if err != nil {
+			return fmt.Errorf("card `%s` encoding error: %w", card.Word().Word(), err)
+		}
+
+		pipe.Set(ctx, cardKey(card.Word().Word()), value, 0)
+		pipe.ZAdd(ctx, cardsDueKey, &redis.Z{
+			Score:  float64(card.Schedule().Due().Unix()),
+			Member: card.Word().Word(),
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("saving cards transaction error: %w", err)
+	}
+
+	return nil
+}
+
+func (rcr RedisCardsRepo) Cards(ctx context.Context, words []string) ([]*domain.Card, error) {
+	if len(words) == 0 {
+		return []*domain.Card{}, nil
+	}
+
+	keys := make([]string, len(words))
+	for i := range words {
+		keys[i] = cardKey(words[i])
+	}
+
+	values, err := rcr.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis MGet operation error: %w", err)
+	}
+
+	cards := make([]*domain.Card, len(values))
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		encoded, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w of word `%s`", errUnexpectedCardValue, words[i])
+		}
+
+		var record cardRecord
+		if err = json.Unmarshal([]byte(encoded), &record); err != nil {
+			return nil, fmt.Errorf("card `%s` decoding error: %w", words[i], err)
+		}
+
+		cards[i] = record.card()
+	}
+
+	return cards, nil
+}
+
+func (rcr RedisCardsRepo) DueCards(ctx context.Context, now time.Time, limit int) ([]*domain.Card, error) {
+	words, err := rcr.client.ZRangeByScore(ctx, cardsDueKey, &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    strconv.FormatInt(now.Unix(), 10),
+		Offset: 0,
+		Count:  int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis ZRangeByScore operation error: %w", err)
+	}
+
+	cards, err := rcr.Cards(ctx, words)
+	if err != nil {
+		return nil, err
+	}
+
+	dueCards := make([]*domain.Card, 0, len(cards))
+
+	for _, card := range cards {
+		if card != nil {
+			dueCards = append(dueCards, card)
+		}
+	}
+
+	return dueCards, nil
+}
+
+func (rcr RedisCardsRepo) DueCardsCount(ctx context.Context, now time.Time) (int, error) {
+	count, err := rcr.client.ZCount(ctx, cardsDueKey, "-inf", strconv.FormatInt(now.Unix(), 10)).Result()
+	if err != nil {
+		return -1, fmt.Errorf("redis ZCount operation error: %w", err)
+	}
+
+	return int(count), nil
+}
+
+func (rcr RedisCardsRepo) DeleteCards(ctx context.Context, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(words))
+	members := make([]interface{}, len(words))
+
+	for i := range words {
+		keys[i] = cardKey(words[i])
+		members[i] = words[i]
+	}
+
+	pipe := rcr.client.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, cardsDueKey, members...)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("deleting cards transaction error: %w", err)
+	}
+
+	return nil
+}
+
+func (rcr RedisCardsRepo) PromoteCards(ctx context.Context, words []string) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(words))
+	members := make([]interface{}, len(words))
+	knownWords := make(map[string]string, len(words))
+
+	for i := range words {
+		keys[i] = cardKey(words[i])
+		members[i] = words[i]
+		knownWords[knownWordKey(words[i])] = words[i]
+	}
+
+	pipe := rcr.client.TxPipeline()
+	pipe.MSet(ctx, knownWords)
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, cardsDueKey, members...)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("promoting cards transaction error: %w", err)
+	}
+
+	return nil
+}
+
+func cardKey(word string) string {
+	return fmt.Sprintf("%s%s", cardsPrefix, word)
+}
+
+func newCardRecord(card *domain.Card) cardRecord {
+	word := card.Word()
+
+	definitions := make([]definitionRecord, len(word.Definitions()))
+	for i, definition := range word.Definitions() {
+		definitions[i] = definitionRecord{
+			PartOfSpeech: definition.PartOfSpeech(),
+			Gloss:        definition.Gloss(),
+			Translation:  definition.Translation(),
+			Synonyms:     definition.Synonyms(),
+			Antonyms:     definition.Antonyms(),
+		}
+	}
+
+	images := make([]imageRecord, len(word.Images()))
+	for i, image := range word.Images() {
+		images[i] = imageRecord{URL: image.URL(), FilePath: image.FilePath()}
+	}
+
+	var pronunciation *pronunciationRecord
+	if word.Pronunciation() != nil {
+		pronunciation = &pronunciationRecord{
+			IPA:           word.Pronunciation().IPA(),
+			Syllables:     word.Pronunciation().Syllables(),
+			AudioURL:      word.Pronunciation().AudioURL(),
+			AudioFilePath: word.Pronunciation().AudioFilePath(),
+		}
+	}
+
+	clozes := make([]clozeRecord, len(word.Clozes()))
+	for i, cloze := range word.Clozes() {
+		clozes[i] = clozeRecord{Segments: cloze.Segments(), Answers: cloze.Answers()}
+	}
+
+	schedule := card.Schedule()
+
+	return cardRecord{
+		Word:           word.Word(),
+		Translation:    word.Translation(),
+		SourceSentence: word.SourceSentence(),
+		Definitions:    definitions,
+		Examples:       word.Examples(),
+		Images:         images,
+		Pronunciation:  pronunciation,
+		FrequencyRank:  word.FrequencyRank(),
+		Tags:           word.Tags(),
+		Clozes:         clozes,
+		Schedule: scheduleRecord{
+			Due:          schedule.Due(),
+			LastReview:   schedule.LastReview(),
+			IntervalDays: schedule.IntervalDays(),
+			Repetitions:  schedule.Repetitions(),
+			Lapses:       schedule.Lapses(),
+			EaseFactor:   schedule.EaseFactor(),
+			Stability:    schedule.Stability(),
+			Difficulty:   schedule.Difficulty(),
+		},
+	}
+}
+
+func (r cardRecord) card() *domain.Card {
+	definitions := make([]*domain.Definition, len(r.Definitions))
+	for i, definition := range r.Definitions {
+		definitions[i] = domain.NewDefinition(
+			definition.PartOfSpeech, definition.Gloss, definition.Translation, definition.Synonyms, definition.Antonyms,
+		)
+	}
+
+	images := make([]*domain.Image, len(r.Images))
+	for i, image := range r.Images {
+		images[i] = domain.NewImage(image.URL, image.FilePath)
+	}
+
+	var pronunciation *domain.Pronunciation
+	if r.Pronunciation != nil {
+		pronunciation = domain.NewPronunciation(
+			r.Pronunciation.IPA, r.Pronunciation.Syllables, r.Pronunciation.AudioURL, r.Pronunciation.AudioFilePath,
+		)
+	}
+
+	var clozes []*domain.Cloze
+	for _, cloze := range r.Clozes {
+		clozes = append(clozes, domain.NewCloze(cloze.Segments, cloze.Answers))
+	}
+
+	return domain.NewCard(
+		domain.NewWord(
+			r.Word, r.Translation, r.SourceSentence, definitions, r.Examples, images, pronunciation,
+			r.FrequencyRank, r.Tags,
+		).WithClozes(clozes),
+		domain.NewSchedule(
+			r.Schedule.Due, r.Schedule.LastReview, r.Schedule.IntervalDays, r.Schedule.Repetitions, r.Schedule.Lapses,
+			r.Schedule.EaseFactor, r.Schedule.Stability, r.Schedule.Difficulty,
+		),
+	)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const offeredURLsPrefix = "o:"
+
+// RedisOfferedURLsRepo keeps offered URLs for the time to live, the keys are URL hashes, so long URLs
+// don't make long keys.
+type RedisOfferedURLsRepo struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRedisOfferedURLsRepo(client *redis.Client, ttl time.Duration) *RedisOfferedURLsRepo {
+	return &RedisOfferedURLsRepo{client: client, ttl: ttl}
+}
+
+func (rour RedisOfferedURLsRepo) SaveOfferedURLs(ctx context.Context, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	pipeline := rour.client.Pipeline()
+	for _, offeredURL := range urls {
+		pipeline.Set(ctx, offeredURLKey(offeredURL), 1, rour.ttl)
+	}
+
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return fmt.Errorf("redis pipeline Set operation error: %w", err)
+	}
+
+	return nil
+}
+
+func (rour RedisOfferedURLsRepo) FilterOfferedURLs(ctx context.Context, urls []string) ([]string, error) {
+	offeredURLs := make([]string, 0, len(urls))
+	if len(urls) == 0 {
+		return offeredURLs, nil
+	}
+
+	keys := make([]string, len(urls))
+	for i := range urls {
+		keys[i] = offeredURLKey(urls[i])
+	}
+
+	values, err := rour.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis MGet operation error: %w", err)
+	}
+
+	for i := range values {
+		if values[i] != nil {
+			offeredURLs = append(offeredURLs, urls[i])
+		}
+	}
+
+	return offeredURLs, nil
+}
+
+func offeredURLKey(offeredURL string) string {
+	hash := sha256.Sum256([]byte(offeredURL))
+
+	return offeredURLsPrefix + hex.EncodeToString(hash[:])
+}
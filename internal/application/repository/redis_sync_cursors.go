@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const syncCursorsPrefix = "s:"
+
+// RedisSyncCursorsRepo stores sync cursors as RFC 3339 times.
+type RedisSyncCursorsRepo struct {
+	client *redis.Client
+}
+
+func NewRedisSyncCursorsRepo(client *redis.Client) *RedisSyncCursorsRepo {
+	return &RedisSyncCursorsRepo{client: client}
+}
+
+func (rscr RedisSyncCursorsRepo) SyncCursor(ctx context.Context, sync string) (time.Time, error) {
+	value, err := rscr.client.Get(ctx, syncCursorKey(sync)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("redis Get operation error: %w", err)
+	}
+
+	cursor, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("sync `%s` cursor parsing error: %w", sync, err)
+	}
+
+	return cursor, nil
+}
+
+func (rscr RedisSyncCursorsRepo) SaveSyncCursor(ctx context.Context, sync string, cursor time.Time) error {
+	if err := rscr.client.Set(ctx, syncCursorKey(sync), cursor.Format(time.RFC3339Nano), 0).Err(); err != nil {
+		return fmt.Errorf("redis Set operation error: %w", err)
+	}
+
+	return nil
+}
+
+func syncCursorKey(sync string) string {
+	return fmt.Sprintf("%s%s", syncCursorsPrefix, sync)
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/scheduler"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// grades are the grades a card can be reviewed with.
+var grades = []domain.Grade{ //nolint:gochecknoglobals
+	domain.GradeAgain, domain.GradeHard, domain.GradeGood, domain.GradeEasy,
+}
+
+// DueReview is the card to review next, the card is nil if nothing is due.
+type DueReview struct {
+	Card     *domain.Card
+	DueCards int
+	// Intervals are how soon the card is due again after a review with each grade.
+	Intervals map[domain.Grade]time.Duration
+}
+
+// NextReview picks the card to review: the longest overdue one.
+type NextReview struct {
+	cardsRepo repository.CardsInterface
+	scheduler scheduler.Interface
+}
+
+func NewNextReview(cardsRepo repository.CardsInterface, cardsScheduler scheduler.Interface) *NextReview {
+	return &NextReview{cardsRepo: cardsRepo, scheduler: cardsScheduler}
+}
+
+func (n NextReview) Run(ctx context.Context, now time.Time) (*DueReview, error) {
+	dueCards, err := n.cardsRepo.DueCards(ctx, now, 1)
+	if err != nil {
+		return nil, fmt.Errorf("getting due cards error: %w", err)
+	}
+
+	dueCardsCount, err := n.cardsRepo.DueCardsCount(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("getting due cards count error: %w", err)
+	}
+
+	review := &DueReview{Card: nil, DueCards: dueCardsCount, Intervals: make(map[domain.Grade]time.Duration)}
+	if len(dueCards) == 0 {
+		return review, nil
+	}
+
+	review.Card = dueCards[0]
+	for _, grade := range grades {
+		review.Intervals[grade] = n.scheduler.Schedule(review.Card.Schedule(), grade, now).Due().Sub(now)
+	}
+
+	return review, nil
+}
+
+// GradeReview schedules a reviewed card, a card which reached the mature interval becomes a known word.
+type GradeReview struct {
+	cardsRepo repository.CardsInterface
+	scheduler scheduler.Interface
+	// matureIntervalDays is the interval a card is promoted to a known word at, zero turns promotion off.
+	matureIntervalDays int
+}
+
+func NewGradeReview(
+	cardsRepo repository.CardsInterface,
+	cardsScheduler scheduler.Interface,
+	matureIntervalDays int,
+) *GradeReview {
+	return &GradeReview{
+		cardsRepo:          cardsRepo,
+		scheduler:          cardsScheduler,
+		matureIntervalDays: matureIntervalDays,
+	}
+}
+
+// Run reports whether the word was promoted. A word without a card is skipped, the card is gone
+// if the review was submitted twice.
+func (g GradeReview) Run(ctx context.Context, word string, grade domain.Grade, now time.Time) (bool, error) {
+	cards, err := g.cardsRepo.Cards(ctx, []string{word})
+	if err != nil {
+		return false, fmt.Errorf("getting card `%s` error: %w", word, err)
+	}
+
+	if cards[0] == nil {
+		return false, nil
+	}
+
+	card := cards[0].WithSchedule(g.scheduler.Schedule(cards[0].Schedule(), grade, now))
+
+	if g.matureIntervalDays == 0 || card.Schedule().IntervalDays() < g.matureIntervalDays {
+		if err = g.cardsRepo.SaveCards(ctx, []*domain.Card{card}); err != nil {
+			return false, fmt.Errorf("saving card `%s` error: %w", word, err)
+		}
+
+		return false, nil
+	}
+
+	if err = g.cardsRepo.PromoteCards(ctx, []string{word}); err != nil {
+		return false, fmt.Errorf("promoting mature card `%s` error: %w", word, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	day                = 24 * time.Hour
+	matureIntervalDays = 21
+)
+
+func TestNextReview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cardsRepo := newFakeCardsRepo(
+		testCard("purr", now.Add(-time.Hour), 1),
+		testCard("meow", now.Add(-day), 1),
+		testCard("bark", now.Add(time.Hour), 1),
+	)
+
+	review, err := application.NewNextReview(cardsRepo, fakeScheduler{}).Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if review.Card == nil || review.Card.Word().Word() != "meow" || review.DueCards != 2 {
+		t.Fatalf("got review %+v, want the overdue meow card of 2 due cards", review)
+	}
+
+	want := map[domain.Grade]time.Duration{
+		domain.GradeAgain: day,
+		domain.GradeHard:  11 * day, //nolint:gomnd
+		domain.GradeGood:  21 * day, //nolint:gomnd
+		domain.GradeEasy:  31 * day, //nolint:gomnd
+	}
+	if !reflect.DeepEqual(review.Intervals, want) {
+		t.Errorf("got intervals %v, want %v", review.Intervals, want)
+	}
+}
+
+func TestNextReviewWithoutDueCards(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cardsRepo := newFakeCardsRepo(testCard("bark", now.Add(time.Hour), 1))
+
+	review, err := application.NewNextReview(cardsRepo, fakeScheduler{}).Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if review.Card != nil || review.DueCards != 0 || len(review.Intervals) != 0 {
+		t.Errorf("got review %+v, want no card", review)
+	}
+}
+
+func TestGradeReview(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, testCase := range map[string]struct {
+		word               string
+		grade              domain.Grade
+		matureIntervalDays int
+		promoted           bool
+		wantIntervalDays   int
+	}{
+		"rescheduled": {
+			word: "purr", grade: domain.GradeHard, matureIntervalDays: matureIntervalDays,
+			promoted: false, wantIntervalDays: 11,
+		},
+		"reached the mature interval": {
+			word: "purr", grade: domain.GradeGood, matureIntervalDays: matureIntervalDays,
+			promoted: true, wantIntervalDays: 0,
+		},
+		"promotion is off": {
+			word: "purr", grade: domain.GradeEasy, matureIntervalDays: 0,
+			promoted: false, wantIntervalDays: 31,
+		},
+		"card is gone": {
+			word: "meow", grade: domain.GradeGood, matureIntervalDays: matureIntervalDays,
+			promoted: false, wantIntervalDays: 0,
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cardsRepo := newFakeCardsRepo(testCard("purr", now, 1))
+			gradeReview := application.NewGradeReview(cardsRepo, fakeScheduler{}, testCase.matureIntervalDays)
+
+			promoted, err := gradeReview.Run(context.Background(), testCase.word, testCase.grade, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if promoted != testCase.promoted || cardsRepo.known["purr"] != testCase.promoted {
+				t.Errorf("got promoted %t and known %t, want %t", promoted, cardsRepo.known["purr"], testCase.promoted)
+			}
+
+			card, kept := cardsRepo.cards["purr"]
+			if kept == testCase.promoted {
+				t.Fatalf("got card kept %t, want promoted %t", kept, testCase.promoted)
+			}
+
+			if kept && testCase.word == "purr" && card.Schedule().IntervalDays() != testCase.wantIntervalDays {
+				t.Errorf("got interval %d days, want %d", card.Schedule().IntervalDays(), testCase.wantIntervalDays)
+			}
+		})
+	}
+}
+
+func TestGradeReviewFails(t *testing.T) {
+	t.Parallel()
+
+	cardsRepo := newFakeCardsRepo(testCard("purr", time.Now(), 1))
+	cardsRepo.err = errTest
+
+	_, err := application.NewGradeReview(cardsRepo, fakeScheduler{}, matureIntervalDays).
+		Run(context.Background(), "purr", domain.GradeEasy, time.Now())
+	if !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+}
@@ -0,0 +1,304 @@
+package cloze
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	partOfSpeechNoun      = "noun"
+	partOfSpeechVerb      = "verb"
+	partOfSpeechAdjective = "adjective"
+	partOfSpeechAdverb    = "adverb"
+
+	// minSuffixedELength is the length of the shortest words ending in `e` which take `-d`, `-r` and `-st`,
+	// e.g. `use`, shorter ones like `be` don't.
+	minSuffixedELength = 3
+)
+
+// wordNetExceptionFiles list irregular forms, lines are like `went go` or `geese goose`.
+var wordNetExceptionFiles = []string{"noun.exc", "verb.exc", "adj.exc", "adv.exc"} //nolint:gochecknoglobals
+
+// Inflections finds the word in sentences by its regular English inflections, like plurals of nouns, past and -ing
+// forms of verbs and comparatives of adjectives and adverbs, and by the irregular forms, if they are given.
+type Inflections struct {
+	// irregularForms maps base forms to their irregular inflected forms.
+	irregularForms map[string][]string
+}
+
+func NewInflections(irregularForms map[string][]string) *Inflections {
+	return &Inflections{irregularForms: irregularForms}
+}
+
+// LoadWordNetIrregularForms reads the irregular forms from the exception lists of a WordNet `dict` directory.
+func LoadWordNetIrregularForms(dictPath string) (map[string][]string, error) {
+	irregularForms := make(map[string][]string)
+
+	for _, fileName := range wordNetExceptionFiles {
+		if err := readWordNetExceptionFile(filepath.Join(dictPath, fileName), irregularForms); err != nil {
+			return nil, err
+		}
+	}
+
+	return irregularForms, nil
+}
+
+func readWordNetExceptionFile(path string, irregularForms map[string][]string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file `%s` error: %w", path, err)
+	}
+
+	defer func() {
+		if err = file.Close(); err != nil {
+			log.Printf("file closing error: %s", err.Error())
+		}
+	}()
+
+	// A line is an inflected form followed by its base forms, blank and malformed lines are skipped.
+	const minFields = 2
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < minFields {
+			continue
+		}
+
+		for _, base := range fields[1:] {
+			irregularForms[wordNetLemma(base)] = append(irregularForms[wordNetLemma(base)], wordNetLemma(fields[0]))
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("reading file `%s` error: %w", path, err)
+	}
+
+	return nil
+}
+
+// wordNetLemma turns WordNet collocations like `look_up` into plain text.
+func wordNetLemma(lemma string) string {
+	return strings.ReplaceAll(lemma, "_", " ")
+}
+
+func (i Inflections) Clozes(word string, partsOfSpeech, sentences []string) []*domain.Cloze {
+	forms := i.forms(word, partsOfSpeech)
+	if len(forms) == 0 {
+		return nil
+	}
+
+	quotedForms := make([]string, len(forms))
+	for j := range forms {
+		quotedForms[j] = regexp.QuoteMeta(forms[j])
+	}
+
+	// Longer forms go first, so `cats` isn't matched as `cat`.
+	formsRegexp := regexp.MustCompile(`(?i)\b(?:` + strings.Join(quotedForms, "|") + `)\b`)
+
+	clozes := make([]*domain.Cloze, 0, len(sentences))
+
+	for _, sentence := range sentences {
+		matches := formsRegexp.FindAllStringIndex(sentence, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		segments := make([]string, 0, len(matches)+1)
+		answers := make([]string, 0, len(matches))
+		previousEnd := 0
+
+		for _, match := range matches {
+			segments = append(segments, sentence[previousEnd:match[0]])
+			answers = append(answers, sentence[match[0]:match[1]])
+			previousEnd = match[1]
+		}
+
+		clozes = append(clozes, domain.NewCloze(append(segments, sentence[previousEnd:]), answers))
+	}
+
+	return clozes
+}
+
+// forms are the word and its inflected forms, the longest first.
+func (i Inflections) forms(word string, partsOfSpeech []string) []string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return nil
+	}
+
+	unique := map[string]bool{word: true}
+
+	// Only the last word of a phrase is inflected, e.g. `ice creams`.
+	head, last := "", word
+	if space := strings.LastIndex(word, " "); space >= 0 {
+		head, last = word[:space+1], word[space+1:]
+	}
+
+	for _, form := range regularForms(last, partsOfSpeech) {
+		unique[head+form] = true
+	}
+
+	for _, form := range i.irregularForms[word] {
+		unique[form] = true
+	}
+
+	forms := make([]string, 0, len(unique))
+	for form := range unique {
+		forms = append(forms, form)
+	}
+
+	sort.Slice(forms, func(a, b int) bool {
+		if len(forms[a]) != len(forms[b]) {
+			return len(forms[a]) > len(forms[b])
+		}
+
+		return forms[a] < forms[b]
+	})
+
+	return forms
+}
+
+// regularForms applies the spelling rules of English suffixes of the parts of speech. Words of unknown parts
+// of speech are inflected as nouns and verbs, the most of them are. Some of the forms may not exist, e.g. `stoped`,
+// which is harmless as they don't occur in sentences, but existing words the word isn't inflected to, like `cater`
+// of `cat` or `singer` of `sing`, would be blanked out, so the suffixes are limited to the parts of speech.
+func regularForms(word string, partsOfSpeech []string) []string {
+	noun, verb, comparable := inflectedPartsOfSpeech(partsOfSpeech)
+	forms := make([]string, 0)
+
+	if noun {
+		forms = append(forms, nounForms(word)...)
+	}
+
+	if verb {
+		forms = append(forms, verbForms(word)...)
+	}
+
+	if comparable {
+		forms = append(forms, comparativeForms(word)...)
+	}
+
+	return forms
+}
+
+// inflectedPartsOfSpeech tells if the word is inflected as a noun, as a verb and if it has comparatives.
+func inflectedPartsOfSpeech(partsOfSpeech []string) (noun, verb, comparable bool) {
+	for _, partOfSpeech := range partsOfSpeech {
+		switch strings.ToLower(strings.TrimSpace(partOfSpeech)) {
+		case partOfSpeechNoun:
+			noun = true
+		case partOfSpeechVerb:
+			verb = true
+		case partOfSpeechAdjective, partOfSpeechAdverb:
+			comparable = true
+		}
+	}
+
+	if !noun && !verb && !comparable {
+		return true, true, false
+	}
+
+	return noun, verb, comparable
+}
+
+// nounForms are plurals.
+func nounForms(word string) []string {
+	forms := []string{sForm(word)}
+
+	switch {
+	case strings.HasSuffix(word, "fe"):
+		forms = append(forms, strings.TrimSuffix(word, "fe")+"ves")
+	case strings.HasSuffix(word, "f"):
+		forms = append(forms, strings.TrimSuffix(word, "f")+"ves")
+	}
+
+	return forms
+}
+
+// verbForms are third person, past and -ing forms.
+func verbForms(word string) []string {
+	forms := []string{sForm(word), word + "ed", word + "ing"}
+
+	switch {
+	case strings.HasSuffix(word, "ie"):
+		forms = append(forms, word+"d", strings.TrimSuffix(word, "ie")+"ying")
+	case endsWithSilentE(word):
+		forms = append(forms, word+"d", strings.TrimSuffix(word, "e")+"ing")
+	case len(word) >= minSuffixedELength && strings.HasSuffix(word, "e"):
+		forms = append(forms, word+"d")
+	case endsWithConsonantY(word):
+		forms = append(forms, strings.TrimSuffix(word, "y")+"ied")
+	case endsWithShortSyllable(word):
+		last := word[len(word)-1:]
+		forms = append(forms, word+last+"ed", word+last+"ing")
+	}
+
+	return forms
+}
+
+// comparativeForms are comparatives and superlatives.
+func comparativeForms(word string) []string {
+	forms := []string{word + "er", word + "est"}
+
+	switch {
+	case endsWithSilentE(word):
+		forms = append(forms, word+"r", word+"st")
+	case endsWithConsonantY(word):
+		stem := strings.TrimSuffix(word, "y")
+		forms = append(forms, stem+"ier", stem+"iest")
+	case endsWithShortSyllable(word):
+		last := word[len(word)-1:]
+		forms = append(forms, word+last+"er", word+last+"est")
+	}
+
+	return forms
+}
+
+// sForm is the plural of a noun or the third person of a verb, e.g. `cats`, `boxes`, `goes` or `tries`.
+func sForm(word string) string {
+	switch {
+	case endsWithConsonantY(word):
+		return strings.TrimSuffix(word, "y") + "ies"
+	case strings.HasSuffix(word, "ch") || strings.HasSuffix(word, "sh") || strings.ContainsAny(word[len(word)-1:], "sxzo"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// endsWithSilentE is true for words like `make` or `large`. Words ending in `ee`, `oe` or `ye`, like `see`,
+// keep the `e` before `-ing`, and two letter words like `be` aren't suffixed with `-d`, `-r` or `-st` at all.
+func endsWithSilentE(word string) bool {
+	return len(word) >= minSuffixedELength && strings.HasSuffix(word, "e") &&
+		!strings.ContainsRune("eoy", rune(word[len(word)-2]))
+}
+
+func endsWithConsonantY(word string) bool {
+	return len(word) > 1 && strings.HasSuffix(word, "y") && !isVowel(word[len(word)-2])
+}
+
+// endsWithShortSyllable is true for words like `stop` or `big`, their last consonant is doubled before suffixes.
+func endsWithShortSyllable(word string) bool {
+	const minLength = 3
+	if len(word) < minLength {
+		return false
+	}
+
+	last, middle, first := word[len(word)-1], word[len(word)-2], word[len(word)-3]
+
+	return !isVowel(last) && !strings.ContainsRune("wxy", rune(last)) && isVowel(middle) && !isVowel(first)
+}
+
+func isVowel(letter byte) bool {
+	return strings.ContainsRune("aeiou", rune(letter))
+}
@@ -0,0 +1,240 @@
+package cloze
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadWordNetIrregularForms(t *testing.T) {
+	t.Parallel()
+
+	dictPath := t.TempDir()
+	files := map[string]string{
+		"noun.exc": "geese goose\n\n   \nmice mouse\n",
+		"verb.exc": "went go\nlooked_up look_up\ngone\n",
+		"adj.exc":  "better good well\n",
+		"adv.exc":  "",
+	}
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dictPath, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("writing file error: %s", err)
+		}
+	}
+
+	irregularForms, err := LoadWordNetIrregularForms(dictPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := map[string][]string{
+		"goose":   {"geese"},
+		"mouse":   {"mice"},
+		"go":      {"went"},
+		"look up": {"looked up"},
+		"good":    {"better"},
+		"well":    {"better"},
+	}
+	if !reflect.DeepEqual(irregularForms, want) {
+		t.Errorf("got irregular forms %q, want %q", irregularForms, want)
+	}
+}
+
+func TestRegularForms(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		word          string
+		partsOfSpeech []string
+		forms         []string
+		notForms      []string
+	}{
+		"noun":                        {"cat", []string{"noun"}, []string{"cats"}, []string{"cater", "catted", "catest"}},
+		"noun ending in x":            {"box", []string{"noun"}, []string{"boxes"}, []string{"boxer", "boxed"}},
+		"noun ending in f":            {"leaf", []string{"noun"}, []string{"leaves"}, nil},
+		"noun ending in fe":           {"knife", []string{"noun"}, []string{"knives"}, []string{"knifed", "knifer"}},
+		"noun ending in y":            {"city", []string{"noun"}, []string{"cities"}, []string{"citied", "citier"}},
+		"verb":                        {"play", []string{"verb"}, []string{"plays", "played", "playing"}, []string{"player"}},
+		"verb of short syllable":      {"stop", []string{"verb"}, []string{"stops", "stopped", "stopping"}, nil},
+		"verb ending in y":            {"try", []string{"verb"}, []string{"tries", "tried", "trying"}, nil},
+		"verb ending in ie":           {"lie", []string{"verb"}, []string{"lies", "lied", "lying"}, nil},
+		"verb ending in silent e":     {"make", []string{"verb"}, []string{"makes", "making"}, []string{"maker", "makest"}},
+		"verb ending in ee":           {"agree", []string{"verb"}, []string{"agrees", "agreed", "agreeing"}, nil},
+		"verb of two letters":         {"be", []string{"verb"}, []string{"being"}, []string{"bed", "best", "bing"}},
+		"verb with a noun in -er":     {"sing", []string{"verb"}, []string{"sings", "singing"}, []string{"singer"}},
+		"adjective":                   {"large", []string{"adjective"}, []string{"larger", "largest"}, []string{"larges"}},
+		"adjective ending in y":       {"happy", []string{"adjective"}, []string{"happier", "happiest"}, nil},
+		"adjective of short syllable": {"big", []string{"adjective"}, []string{"bigger", "biggest"}, []string{"bigs"}},
+		"adverb":                      {"fast", []string{"adverb"}, []string{"faster", "fastest"}, nil},
+		"noun and verb":               {"fish", []string{"noun", "verb"}, []string{"fishes", "fished"}, []string{"fisher"}},
+		"unknown part of speech":      {"cat", []string{"", "phrase"}, []string{"cats", "catted"}, []string{"cater"}},
+		"capitalized part of speech":  {"big", []string{" Adjective"}, []string{"bigger"}, []string{"bigs"}},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			forms := make(map[string]bool)
+			for _, form := range regularForms(testCase.word, testCase.partsOfSpeech) {
+				forms[form] = true
+			}
+
+			for _, form := range testCase.forms {
+				if !forms[form] {
+					t.Errorf("got forms %v, want them to contain %q", forms, form)
+				}
+			}
+
+			for _, form := range testCase.notForms {
+				if forms[form] {
+					t.Errorf("got forms %v, want them not to contain %q", forms, form)
+				}
+			}
+		})
+	}
+}
+
+func TestEndsWithShortSyllable(t *testing.T) {
+	t.Parallel()
+
+	for word, want := range map[string]bool{
+		"stop":  true,
+		"big":   true,
+		"admit": true,
+		"go":    false,
+		"play":  false,
+		"fix":   false,
+		"snow":  false,
+		"rain":  false,
+		"help":  false,
+		"eat":   false,
+	} {
+		word, want := word, want
+
+		t.Run(word, func(t *testing.T) {
+			t.Parallel()
+
+			if got := endsWithShortSyllable(word); got != want {
+				t.Errorf("got %t, want %t", got, want)
+			}
+		})
+	}
+}
+
+func TestClozes(t *testing.T) {
+	t.Parallel()
+
+	inflections := NewInflections(map[string][]string{
+		"go":    {"went", "gone"},
+		"mouse": {"mice"},
+	})
+
+	for name, testCase := range map[string]struct {
+		word          string
+		partsOfSpeech []string
+		sentences     []string
+		blanked       []string
+		answers       [][]string
+	}{
+		"-ies and -ied": {
+			word:          "try",
+			partsOfSpeech: []string{"verb"},
+			sentences:     []string{"She tried twice.", "He tries again.", "Nothing here."},
+			blanked:       []string{"She _____ twice.", "He _____ again."},
+			answers:       [][]string{{"tried"}, {"tries"}},
+		},
+		"doubled consonant": {
+			word:          "stop",
+			partsOfSpeech: []string{"verb"},
+			sentences:     []string{"The bus stopped and kept stopping."},
+			blanked:       []string{"The bus _____ and kept _____."},
+			answers:       [][]string{{"stopped", "stopping"}},
+		},
+		"-ves": {
+			word:          "leaf",
+			partsOfSpeech: []string{"noun"},
+			sentences:     []string{"Leaves fall, a leaf stays."},
+			blanked:       []string{"_____ fall, a _____ stays."},
+			answers:       [][]string{{"Leaves", "leaf"}},
+		},
+		"phrase": {
+			word:          "ice cream",
+			partsOfSpeech: []string{"noun"},
+			sentences:     []string{"Two ice creams, please.", "Ice is cold, cream is sweet."},
+			blanked:       []string{"Two _____, please."},
+			answers:       [][]string{{"ice creams"}},
+		},
+		"irregular forms": {
+			word:          "go",
+			partsOfSpeech: []string{"verb"},
+			sentences:     []string{"They went home.", "It's gone.", "Going out."},
+			blanked:       []string{"They _____ home.", "It's _____.", "_____ out."},
+			answers:       [][]string{{"went"}, {"gone"}, {"Going"}},
+		},
+		"irregular plural": {
+			word:          "mouse",
+			partsOfSpeech: []string{"noun"},
+			sentences:     []string{"Mice and a mouse."},
+			blanked:       []string{"_____ and a _____."},
+			answers:       [][]string{{"Mice", "mouse"}},
+		},
+		"whole words only": {
+			word:          "cat",
+			partsOfSpeech: []string{"noun"},
+			sentences:     []string{"A category of cats."},
+			blanked:       []string{"A category of _____."},
+			answers:       [][]string{{"cats"}},
+		},
+		"not a noun inflection": {
+			word:          "cat",
+			partsOfSpeech: []string{"noun"},
+			sentences:     []string{"They cater for cats."},
+			blanked:       []string{"They cater for _____."},
+			answers:       [][]string{{"cats"}},
+		},
+		"not a verb inflection": {
+			word:          "be",
+			partsOfSpeech: []string{"verb"},
+			sentences:     []string{"Being in bed is the best.", "No forms here."},
+			blanked:       []string{"_____ in bed is the best."},
+			answers:       [][]string{{"Being"}},
+		},
+		"comparatives of adjectives": {
+			word:          "large",
+			partsOfSpeech: []string{"adjective"},
+			sentences:     []string{"Larger than the largest."},
+			blanked:       []string{"_____ than the _____."},
+			answers:       [][]string{{"Larger", "largest"}},
+		},
+		"blank word": {
+			word:          " ",
+			partsOfSpeech: nil,
+			sentences:     []string{"Anything."},
+			blanked:       []string{},
+			answers:       [][]string{},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			clozes := inflections.Clozes(testCase.word, testCase.partsOfSpeech, testCase.sentences)
+
+			blanked := make([]string, len(clozes))
+			answers := make([][]string, len(clozes))
+
+			for i, cloze := range clozes {
+				blanked[i] = cloze.Blanked()
+				answers[i] = cloze.Answers()
+			}
+
+			if !reflect.DeepEqual(blanked, testCase.blanked) || !reflect.DeepEqual(answers, testCase.answers) {
+				t.Errorf("got clozes %q with answers %q, want %q with %q", blanked, answers, testCase.blanked, testCase.answers)
+			}
+		})
+	}
+}
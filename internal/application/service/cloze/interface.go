@@ -0,0 +1,9 @@
+package cloze
+
+import "github.com/r-erema/vocaboost/internal/domain"
+
+type Interface interface {
+	// Clozes blanks the word and its inflected forms out in the sentences, sentences without the word are dropped.
+	// The parts of speech of the word's definitions tell which inflections it has.
+	Clozes(word string, partsOfSpeech, sentences []string) []*domain.Cloze
+}
@@ -0,0 +1,152 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+const freeDictionaryAPICallURLTemplate = "https://api.dictionaryapi.dev/api/v2/entries/en/%s"
+
+var errUnexpectedStatus = errors.New("unexpected response status")
+
+type FreeDictionaryAPI struct {
+	httpClient *http.Client
+}
+
+type freeDictionaryEntry struct {
+	Word      string `json:"word"`
+	Phonetic  string `json:"phonetic"`
+	Phonetics []struct {
+		Text  string `json:"text"`
+		Audio string `json:"audio"`
+	} `json:"phonetics"`
+	Meanings []struct {
+		PartOfSpeech string `json:"partOfSpeech"` //nolint:tagliatelle
+		Definitions  []struct {
+			Definition string   `json:"definition"`
+			Example    string   `json:"example"`
+			Synonyms   []string `json:"synonyms"`
+			Antonyms   []string `json:"antonyms"`
+		} `json:"definitions"`
+		Synonyms []string `json:"synonyms"`
+		Antonyms []string `json:"antonyms"`
+	} `json:"meanings"`
+}
+
+func NewFreeDictionaryAPI(httpClient *http.Client) *FreeDictionaryAPI {
+	return &FreeDictionaryAPI{httpClient: httpClient}
+}
+
+func (fda FreeDictionaryAPI) WordsInfo(ctx context.Context, words []string) ([]*WordInfoDTO, error) {
+	wordsInfo := make([]*WordInfoDTO, len(words))
+
+	for i := range words {
+		entries, err := fda.entriesAPICall(ctx, words[i])
+		if err != nil {
+			return nil, fmt.Errorf("entries API call error: %w", err)
+		}
+
+		var (
+			definitions   []*DefinitionDTO
+			examples      []string
+			pronunciation *PronunciationDTO
+		)
+
+		for _, entry := range entries {
+			if pronunciation == nil {
+				pronunciation = entry.pronunciation()
+			}
+
+			for _, meaning := range entry.Meanings {
+				for _, definition := range meaning.Definitions {
+					definitions = append(definitions, NewDefinition(
+						definition.Definition,
+						meaning.PartOfSpeech,
+						concatWords(definition.Synonyms, meaning.Synonyms),
+						concatWords(definition.Antonyms, meaning.Antonyms),
+					))
+
+					if definition.Example != "" {
+						examples = append(examples, definition.Example)
+					}
+				}
+			}
+		}
+
+		wordsInfo[i] = NewWordInfoDTO(words[i], definitions, examples, pronunciation)
+	}
+
+	return wordsInfo, nil
+}
+
+// concatWords copies the words into a new slice, appending to a decoded slice could overwrite
+// the words of another definition sharing its backing array.
+func concatWords(definitionWords, meaningWords []string) []string {
+	if len(definitionWords)+len(meaningWords) == 0 {
+		return nil
+	}
+
+	words := make([]string, 0, len(definitionWords)+len(meaningWords))
+
+	return append(append(words, definitionWords...), meaningWords...)
+}
+
+func (fde freeDictionaryEntry) pronunciation() *PronunciationDTO {
+	ipa, audioURL := fde.Phonetic, ""
+
+	for _, phonetic := range fde.Phonetics {
+		if ipa == "" {
+			ipa = phonetic.Text
+		}
+
+		if audioURL == "" {
+			audioURL = phonetic.Audio
+		}
+	}
+
+	if ipa == "" && audioURL == "" {
+		return nil
+	}
+
+	return NewPronunciationDTO(ipa, nil, audioURL)
+}
+
+func (fda FreeDictionaryAPI) entriesAPICall(ctx context.Context, word string) ([]freeDictionaryEntry, error) {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet, fmt.Sprintf(freeDictionaryAPICallURLTemplate, url.PathEscape(word)), http.NoBody,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creation request error: %w", err)
+	}
+
+	resp, err := fda.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request execution(word `%s`) error: %w", word, err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return []freeDictionaryEntry{}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w(word `%s`): %d", errUnexpectedStatus, word, resp.StatusCode)
+	}
+
+	var entries []freeDictionaryEntry
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("response decoding to entries error: %w", err)
+	}
+
+	return entries, nil
+}
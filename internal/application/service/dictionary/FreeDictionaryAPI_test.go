@@ -0,0 +1,80 @@
+package dictionary_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newFreeDictionaryStub serves the recorded responses from testdata/freedictionary named after the word.
+func newFreeDictionaryStub(t *testing.T) *http.Client {
+	t.Helper()
+
+	return &http.Client{ //nolint:exhaustruct
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			status := http.StatusOK
+
+			fixture, err := os.ReadFile(filepath.Join("testdata", "freedictionary", path.Base(req.URL.Path)+".json"))
+			if err != nil {
+				status, fixture = http.StatusNotFound, []byte(`{"title":"No Definitions Found"}`)
+			}
+
+			return &http.Response{ //nolint:exhaustruct
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(bytes.NewReader(fixture)),
+				Request:    req,
+			}, nil
+		}),
+	}
+}
+
+func TestFreeDictionaryAPIWordsInfo(t *testing.T) {
+	t.Parallel()
+
+	wordsInfo, err := dictionary.NewFreeDictionaryAPI(newFreeDictionaryStub(t)).
+		WordsInfo(context.Background(), []string{"bright", "qwertyuiop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(wordsInfo) != 2 {
+		t.Fatalf("expected 2 words info, got %d", len(wordsInfo))
+	}
+
+	// The meaning synonyms and antonyms are added to every definition of the meaning.
+	assertWordInfo(t, wordsInfo[0], "bright", []expectedDefinition{
+		{
+			definition:   "Visually dazzling; luminous, lucent, clear, radiant.",
+			partOfSpeech: "adjective",
+			synonyms:     []string{"shining", "vivid"},
+			antonyms:     []string{"dull"},
+		},
+		{
+			definition:   "Having a clever mind.",
+			partOfSpeech: "adjective",
+			synonyms:     []string{"smart", "intelligent", "vivid"},
+			antonyms:     []string{"dim", "dull"},
+		},
+	}, []string{"a bright star"})
+
+	if pronunciation := wordsInfo[0].Pronunciation(); pronunciation == nil || pronunciation.IPA() != "/bɹaɪt/" ||
+		pronunciation.AudioURL() != "https://api.dictionaryapi.dev/media/pronunciations/en/bright-us.mp3" {
+		t.Errorf("expected the IPA and the audio of bright, got %+v", pronunciation)
+	}
+
+	assertWordInfo(t, wordsInfo[1], "qwertyuiop", nil, nil)
+}
@@ -0,0 +1,271 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var errMalformedWordNetLine = errors.New("malformed WordNet line")
+
+// wordNetPartsOfSpeech maps WordNet database file suffixes to the parts of speech.
+var wordNetPartsOfSpeech = []struct {
+	fileSuffix, partOfSpeech string
+}{
+	{fileSuffix: "noun", partOfSpeech: "noun"},
+	{fileSuffix: "verb", partOfSpeech: "verb"},
+	{fileSuffix: "adj", partOfSpeech: "adjective"},
+	{fileSuffix: "adv", partOfSpeech: "adverb"},
+}
+
+// WordNet looks words up in a local copy of the WordNet 3.x database files (the `dict` directory),
+// so it works offline. Index files are loaded lazily on the first lookup.
+type WordNet struct {
+	dictPath string
+
+	loadIndexOnce sync.Once
+	loadIndexErr  error
+	index         map[string]map[string][]int64
+}
+
+func NewWordNet(dictPath string) *WordNet {
+	return &WordNet{dictPath: dictPath, loadIndexOnce: sync.Once{}, loadIndexErr: nil, index: nil}
+}
+
+func (wn *WordNet) WordsInfo(_ context.Context, words []string) ([]*WordInfoDTO, error) {
+	wn.loadIndexOnce.Do(func() {
+		wn.index, wn.loadIndexErr = wn.loadIndex()
+	})
+
+	if wn.loadIndexErr != nil {
+		return nil, fmt.Errorf("WordNet index loading error: %w", wn.loadIndexErr)
+	}
+
+	wordsInfo := make([]*WordInfoDTO, len(words))
+
+	for i := range words {
+		definitions, examples, err := wn.lookup(words[i])
+		if err != nil {
+			return nil, fmt.Errorf("WordNet lookup(word `%s`) error: %w", words[i], err)
+		}
+
+		wordsInfo[i] = NewWordInfoDTO(words[i], definitions, examples, nil)
+	}
+
+	return wordsInfo, nil
+}
+
+func (wn *WordNet) lookup(word string) ([]*DefinitionDTO, []string, error) {
+	lemma := strings.ReplaceAll(strings.ToLower(word), " ", "_")
+
+	var (
+		definitions []*DefinitionDTO
+		examples    []string
+	)
+
+	for _, pos := range wordNetPartsOfSpeech {
+		offsets := wn.index[pos.fileSuffix][lemma]
+		if len(offsets) == 0 {
+			continue
+		}
+
+		synsets, err := wn.readSynsets(pos.fileSuffix, offsets)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, synset := range synsets {
+			definition, glossExamples := splitGloss(synset.gloss)
+			definitions = append(definitions, NewDefinition(definition, pos.partOfSpeech, synset.synonymsOf(lemma), nil))
+			examples = append(examples, glossExamples...)
+		}
+	}
+
+	return definitions, examples, nil
+}
+
+func (wn *WordNet) loadIndex() (map[string]map[string][]int64, error) {
+	index := make(map[string]map[string][]int64, len(wordNetPartsOfSpeech))
+
+	for _, pos := range wordNetPartsOfSpeech {
+		posIndex, err := readWordNetIndexFile(filepath.Join(wn.dictPath, "index."+pos.fileSuffix))
+		if err != nil {
+			return nil, err
+		}
+
+		index[pos.fileSuffix] = posIndex
+	}
+
+	return index, nil
+}
+
+// readWordNetIndexFile parses lines like `lemma pos synset_cnt p_cnt [ptr_symbol...] sense_cnt tagsense_cnt offset...`.
+func readWordNetIndexFile(path string) (map[string][]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file `%s` error: %w", path, err)
+	}
+
+	defer func() {
+		if err = file.Close(); err != nil {
+			log.Printf("file closing error: %s", err.Error())
+		}
+	}()
+
+	index := make(map[string][]int64)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		const minFieldsCount = 4
+		if len(fields) < minFieldsCount {
+			return nil, fmt.Errorf("%w in `%s`: %s", errMalformedWordNetLine, path, line)
+		}
+
+		synsetCount, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w in `%s`: %s", errMalformedWordNetLine, path, line)
+		}
+
+		if len(fields) < synsetCount {
+			return nil, fmt.Errorf("%w in `%s`: %s", errMalformedWordNetLine, path, line)
+		}
+
+		offsets := make([]int64, 0, synsetCount)
+
+		for _, field := range fields[len(fields)-synsetCount:] {
+			offset, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w in `%s`: %s", errMalformedWordNetLine, path, line)
+			}
+
+			offsets = append(offsets, offset)
+		}
+
+		index[fields[0]] = offsets
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning file `%s` error: %w", path, err)
+	}
+
+	return index, nil
+}
+
+type wordNetSynset struct {
+	words []string
+	gloss string
+}
+
+func (ws wordNetSynset) synonymsOf(lemma string) []string {
+	synonyms := make([]string, 0, len(ws.words))
+
+	for _, word := range ws.words {
+		if word != lemma {
+			synonyms = append(synonyms, strings.ReplaceAll(word, "_", " "))
+		}
+	}
+
+	return synonyms
+}
+
+// readSynsets parses lines like `offset lex_filenum ss_type w_cnt word lex_id [word lex_id...] ... | gloss`.
+func (wn *WordNet) readSynsets(fileSuffix string, offsets []int64) ([]wordNetSynset, error) {
+	path := filepath.Join(wn.dictPath, "data."+fileSuffix)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file `%s` error: %w", path, err)
+	}
+
+	defer func() {
+		if err = file.Close(); err != nil {
+			log.Printf("file closing error: %s", err.Error())
+		}
+	}()
+
+	synsets := make([]wordNetSynset, 0, len(offsets))
+
+	for _, offset := range offsets {
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seeking file `%s` error: %w", path, err)
+		}
+
+		line, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("reading file `%s` error: %w", path, err)
+		}
+
+		synset, err := parseWordNetDataLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w in `%s`: %s", err, path, line)
+		}
+
+		synsets = append(synsets, synset)
+	}
+
+	return synsets, nil
+}
+
+func parseWordNetDataLine(line string) (wordNetSynset, error) {
+	header, gloss, found := strings.Cut(line, "|")
+	fields := strings.Fields(header)
+
+	const wordsCountField = 3
+	if !found || len(fields) <= wordsCountField {
+		return wordNetSynset{}, errMalformedWordNetLine
+	}
+
+	wordsCount, err := strconv.ParseInt(fields[wordsCountField], 16, 32)
+	if err != nil || len(fields) < wordsCountField+1+int(wordsCount)*2 {
+		return wordNetSynset{}, errMalformedWordNetLine
+	}
+
+	words := make([]string, 0, wordsCount)
+
+	for i := 0; i < int(wordsCount); i++ {
+		word := strings.ToLower(fields[wordsCountField+1+i*2])
+		// Adjectives may carry a syntactic marker, e.g. `galore(ip)`.
+		if markerStart := strings.Index(word, "("); markerStart > 0 {
+			word = word[:markerStart]
+		}
+
+		words = append(words, word)
+	}
+
+	return wordNetSynset{words: words, gloss: strings.TrimSpace(gloss)}, nil
+}
+
+// splitGloss splits a gloss like `definition; "example one"; "example two"` into its parts.
+func splitGloss(gloss string) (string, []string) {
+	parts := strings.Split(gloss, ";")
+	definitionParts := make([]string, 0, len(parts))
+	examples := make([]string, 0)
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, `"`) {
+			examples = append(examples, strings.Trim(part, `"`))
+
+			continue
+		}
+
+		definitionParts = append(definitionParts, part)
+	}
+
+	return strings.Join(definitionParts, "; "), examples
+}
@@ -0,0 +1,170 @@
+package dictionary_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+)
+
+// wordNetSynset is a synset line of a test WordNet database, the lemmas index it.
+type wordNetSynset struct {
+	lemmas []string
+	gloss  string
+}
+
+// writeWordNetDict writes the index and data files of every part of speech, the data lines are at the offsets
+// the index lines point at, as in the real database.
+func writeWordNetDict(t *testing.T, synsets map[string][]wordNetSynset) string {
+	t.Helper()
+
+	dictPath := t.TempDir()
+
+	for _, fileSuffix := range []string{"noun", "verb", "adj", "adv"} {
+		data := "  1 This software and database is being provided to you, the LICENSEE, by Princeton University\n"
+		offsets := make(map[string][]int)
+		lemmas := make([]string, 0)
+
+		for _, synset := range synsets[fileSuffix] {
+			words := make([]string, 0, len(synset.lemmas))
+			for _, word := range synset.lemmas {
+				words = append(words, word+" 0")
+				// The index has no syntactic markers like `(ip)`.
+				lemma, _, _ := strings.Cut(word, "(")
+
+				if _, seen := offsets[lemma]; !seen {
+					lemmas = append(lemmas, lemma)
+				}
+
+				offsets[lemma] = append(offsets[lemma], len(data))
+			}
+
+			data += fmt.Sprintf(
+				"%08d 05 n %02x %s 000 | %s  \n", len(data), len(synset.lemmas), strings.Join(words, " "), synset.gloss,
+			)
+		}
+
+		index := "  1 This software and database is being provided to you, the LICENSEE, by Princeton University\n"
+
+		for _, lemma := range lemmas {
+			lemmaOffsets := make([]string, len(offsets[lemma]))
+			for i, offset := range offsets[lemma] {
+				lemmaOffsets[i] = fmt.Sprintf("%08d", offset)
+			}
+
+			index += fmt.Sprintf(
+				"%s n %d 1 @ %d 0 %s  \n", lemma, len(lemmaOffsets), len(lemmaOffsets), strings.Join(lemmaOffsets, " "),
+			)
+		}
+
+		for name, content := range map[string]string{"data." + fileSuffix: data, "index." + fileSuffix: index} {
+			if err := os.WriteFile(filepath.Join(dictPath, name), []byte(content), 0o600); err != nil {
+				t.Fatalf("writing file error: %s", err)
+			}
+		}
+	}
+
+	return dictPath
+}
+
+func TestWordNet(t *testing.T) {
+	t.Parallel()
+
+	dictPath := writeWordNetDict(t, map[string][]wordNetSynset{
+		"noun": {
+			{lemmas: []string{"dog", "domestic_dog"}, gloss: `a domesticated canid; "the dog barked all night"`},
+			{lemmas: []string{"hot_dog", "frank"}, gloss: `a smooth-textured sausage; "a hot dog with mustard"`},
+			{lemmas: []string{"cad", "dog"}, gloss: `someone who is morally reprehensible`},
+		},
+		"verb": {
+			{lemmas: []string{"chase", "dog"}, gloss: `go after with the intent to catch; "the dog chased the cat"; "a chase"`},
+		},
+		"adj": {
+			{lemmas: []string{"galore(ip)", "in_abundance"}, gloss: `in great numbers; "apples galore"`},
+		},
+	})
+
+	wordsInfo, err := dictionary.NewWordNet(dictPath).WordsInfo(
+		context.Background(), []string{"Dog", "hot dog", "galore", "purr"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	dog := wordsInfo[0]
+	if dog.Word() != "Dog" || dog.Pronunciation() != nil {
+		t.Errorf("got word %q with pronunciation %+v, want Dog without pronunciation", dog.Word(), dog.Pronunciation())
+	}
+
+	wantDefinitions := []*dictionary.DefinitionDTO{
+		dictionary.NewDefinition("a domesticated canid", "noun", []string{"domestic dog"}, nil),
+		dictionary.NewDefinition("someone who is morally reprehensible", "noun", []string{"cad"}, nil),
+		dictionary.NewDefinition("go after with the intent to catch", "verb", []string{"chase"}, nil),
+	}
+	if !reflect.DeepEqual(dog.Definitions(), wantDefinitions) {
+		t.Errorf("got definitions %+v, want %+v", dog.Definitions(), wantDefinitions)
+	}
+
+	if want := []string{"the dog barked all night", "the dog chased the cat", "a chase"}; !reflect.DeepEqual(
+		dog.Examples(), want,
+	) {
+		t.Errorf("got examples %q, want %q", dog.Examples(), want)
+	}
+
+	if want := []string{"a smooth-textured sausage"}; !reflect.DeepEqual(definitionsOf(wordsInfo[1]), want) {
+		t.Errorf("got collocation definitions %q, want %q", definitionsOf(wordsInfo[1]), want)
+	}
+
+	galore := wordsInfo[2].Definitions()
+	if len(galore) != 1 || galore[0].PartOfSpeech() != "adjective" ||
+		!reflect.DeepEqual(galore[0].Synonyms(), []string{"in abundance"}) {
+		t.Errorf("got definitions %+v, want an adjective without the syntactic marker", galore)
+	}
+
+	if purr := wordsInfo[3]; purr.Word() != "purr" || len(purr.Definitions()) != 0 || len(purr.Examples()) != 0 {
+		t.Errorf("got word %q with definitions %q, want purr without any", purr.Word(), definitionsOf(purr))
+	}
+}
+
+func TestWordNetFails(t *testing.T) {
+	t.Parallel()
+
+	malformedIndex := writeWordNetDict(t, nil)
+	if err := os.WriteFile(filepath.Join(malformedIndex, "index.noun"), []byte("dog n\n"), 0o600); err != nil {
+		t.Fatalf("writing file error: %s", err)
+	}
+
+	malformedData := writeWordNetDict(t, map[string][]wordNetSynset{
+		"noun": {{lemmas: []string{"dog"}, gloss: "a domesticated canid"}},
+	})
+	malformedLine := []byte(strings.Repeat("x", 200)) //nolint:gomnd
+	if err := os.WriteFile(filepath.Join(malformedData, "data.noun"), malformedLine, 0o600); err != nil {
+		t.Fatalf("writing file error: %s", err)
+	}
+
+	for name, dictPath := range map[string]string{
+		"missing files":   t.TempDir(),
+		"malformed index": malformedIndex,
+		"malformed data":  malformedData,
+	} {
+		dictPath := dictPath
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			wordNet := dictionary.NewWordNet(dictPath)
+
+			// The index is loaded once, so the later lookups must fail as well.
+			for i := 0; i < 2; i++ {
+				if _, err := wordNet.WordsInfo(context.Background(), []string{"dog"}); err == nil {
+					t.Errorf("lookup %d: got no error, want one", i+1)
+				}
+			}
+		})
+	}
+}
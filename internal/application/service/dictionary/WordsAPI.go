@@ -6,24 +6,68 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 const (
-	definitionsAPICallURLTemplate = "https://%s/words/%s/definitions"
-	examplesAPICallURLTemplate    = "https://%s/words/%s/examples"
-	apiHost                       = "wordsapiv1.p.rapidapi.com"
+	wordAPICallURLTemplate     = "%s/words/%s"
+	examplesAPICallURLTemplate = "%s/words/%s/examples"
+	apiHost                    = "wordsapiv1.p.rapidapi.com"
+	defaultBaseURL             = "https://" + apiHost
+	defaultTimeout             = 10 * time.Second
 )
 
 type WordsAPI struct {
-	apiKey string
+	apiKey,
+	baseURL,
+	userAgent string
+	httpClient *http.Client
+	timeout    time.Duration
 }
 
-type definitionsResponse struct {
-	Word        string `json:"word"`
-	Definitions []struct {
-		Definition   string `json:"definition"`
-		PartOfSpeech string `json:"partOfSpeech"` //nolint:tagliatelle
-	} `json:"definitions"`
+type WordsAPIOption func(*WordsAPI)
+
+// WithBaseURL points the client to another server, e.g. a local stub.
+func WithBaseURL(baseURL string) WordsAPIOption {
+	return func(wa *WordsAPI) {
+		wa.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) WordsAPIOption {
+	return func(wa *WordsAPI) {
+		wa.httpClient = httpClient
+	}
+}
+
+// WithTimeout limits every API call regardless of the HTTP client's own timeout, zero disables the limit.
+func WithTimeout(timeout time.Duration) WordsAPIOption {
+	return func(wa *WordsAPI) {
+		wa.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) WordsAPIOption {
+	return func(wa *WordsAPI) {
+		wa.userAgent = userAgent
+	}
+}
+
+type wordResponse struct {
+	Word    string `json:"word"`
+	Results []struct {
+		Definition   string   `json:"definition"`
+		PartOfSpeech string   `json:"partOfSpeech"` //nolint:tagliatelle
+		Synonyms     []string `json:"synonyms"`
+		Antonyms     []string `json:"antonyms"`
+	} `json:"results"`
+	Syllables struct {
+		List []string `json:"list"`
+	} `json:"syllables"`
+	Pronunciation json.RawMessage `json:"pronunciation"`
 }
 
 type examplesResponse struct {
@@ -31,22 +75,34 @@ type examplesResponse struct {
 	Examples []string `json:"examples"`
 }
 
-func NewWordsAPI(apiKey string) *WordsAPI {
-	return &WordsAPI{apiKey: apiKey}
+func NewWordsAPI(apiKey string, options ...WordsAPIOption) *WordsAPI {
+	wordsAPI := &WordsAPI{
+		apiKey:     apiKey,
+		baseURL:    defaultBaseURL,
+		userAgent:  "",
+		httpClient: http.DefaultClient,
+		timeout:    defaultTimeout,
+	}
+
+	for _, option := range options {
+		option(wordsAPI)
+	}
+
+	return wordsAPI
 }
 
 func (wa WordsAPI) WordsInfo(ctx context.Context, words []string) ([]*WordInfoDTO, error) {
 	wordsInfo := make([]*WordInfoDTO, len(words))
 
 	for i := range words {
-		definitionsResp, err := wa.definitionsAPICall(ctx, words[i])
+		wordResp, err := wa.wordAPICall(ctx, words[i])
 		if err != nil {
-			return nil, fmt.Errorf("definition API call error: %w", err)
+			return nil, fmt.Errorf("word API call error: %w", err)
 		}
 
-		definitions := make([]*DefinitionDTO, len(definitionsResp.Definitions))
-		for j := range definitionsResp.Definitions {
-			definitions[j] = NewDefinition(definitionsResp.Definitions[j].Definition, definitionsResp.Definitions[j].PartOfSpeech)
+		definitions := make([]*DefinitionDTO, len(wordResp.Results))
+		for j, result := range wordResp.Results {
+			definitions[j] = NewDefinition(result.Definition, result.PartOfSpeech, result.Synonyms, result.Antonyms)
 		}
 
 		examples, err := wa.examplesAPICall(ctx, words[i])
@@ -54,36 +110,93 @@ func (wa WordsAPI) WordsInfo(ctx context.Context, words []string) ([]*WordInfoDT
 			return nil, fmt.Errorf("example API call error: %w", err)
 		}
 
-		wordsInfo[i] = NewWordInfoDTO(words[i], definitions, examples.Examples)
+		wordsInfo[i] = NewWordInfoDTO(words[i], definitions, examples.Examples, wordResp.pronunciation())
 	}
 
 	return wordsInfo, nil
 }
 
-func (wa WordsAPI) definitionsAPICall(ctx context.Context, word string) (*definitionsResponse, error) {
-	url := fmt.Sprintf(definitionsAPICallURLTemplate, apiHost, word)
-	definitionItems := new(definitionsResponse)
+// pronunciation handles both shapes WordsAPI uses: a plain string or an object keyed by part of speech.
+// The object has an `all` key if the parts of speech sound alike, otherwise the pronunciation of the first
+// part of speech is taken.
+func (wr wordResponse) pronunciation() *PronunciationDTO {
+	var ipa string
+
+	if err := json.Unmarshal(wr.Pronunciation, &ipa); err != nil {
+		byPartOfSpeech := make(map[string]string)
+		if err = json.Unmarshal(wr.Pronunciation, &byPartOfSpeech); err == nil {
+			ipa = wr.partOfSpeechIPA(byPartOfSpeech)
+		}
+	}
+
+	if ipa == "" && len(wr.Syllables.List) == 0 {
+		return nil
+	}
+
+	return NewPronunciationDTO(ipa, wr.Syllables.List, "")
+}
 
-	if err := wa.apiCall(ctx, url, definitionItems); err != nil {
+// partOfSpeechIPA prefers the `all` pronunciation, then the one of the first defined part of speech,
+// then the first by the part of speech name, so the choice doesn't depend on the map order.
+func (wr wordResponse) partOfSpeechIPA(byPartOfSpeech map[string]string) string {
+	if ipa := byPartOfSpeech["all"]; ipa != "" {
+		return ipa
+	}
+
+	for _, result := range wr.Results {
+		if ipa := byPartOfSpeech[result.PartOfSpeech]; ipa != "" {
+			return ipa
+		}
+	}
+
+	partsOfSpeech := make([]string, 0, len(byPartOfSpeech))
+	for partOfSpeech := range byPartOfSpeech {
+		partsOfSpeech = append(partsOfSpeech, partOfSpeech)
+	}
+
+	sort.Strings(partsOfSpeech)
+
+	for _, partOfSpeech := range partsOfSpeech {
+		if ipa := byPartOfSpeech[partOfSpeech]; ipa != "" {
+			return ipa
+		}
+	}
+
+	return ""
+}
+
+func (wa WordsAPI) wordAPICall(ctx context.Context, word string) (*wordResponse, error) {
+	callURL := fmt.Sprintf(wordAPICallURLTemplate, wa.baseURL, url.PathEscape(word))
+	wordItem := new(wordResponse)
+
+	if err := wa.apiCall(ctx, callURL, wordItem); err != nil {
 		return nil, fmt.Errorf("API call(word `%s`) error: %w", word, err)
 	}
 
-	return definitionItems, nil
+	return wordItem, nil
 }
 
 func (wa WordsAPI) examplesAPICall(ctx context.Context, word string) (*examplesResponse, error) {
-	url := fmt.Sprintf(examplesAPICallURLTemplate, apiHost, word)
+	callURL := fmt.Sprintf(examplesAPICallURLTemplate, wa.baseURL, url.PathEscape(word))
 	exampleItems := new(examplesResponse)
 
-	if err := wa.apiCall(ctx, url, exampleItems); err != nil {
+	if err := wa.apiCall(ctx, callURL, exampleItems); err != nil {
 		return nil, fmt.Errorf("API call(word `%s`) error: %w", word, err)
 	}
 
 	return exampleItems, nil
 }
 
-func (wa WordsAPI) apiCall(ctx context.Context, url string, dto interface{}) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+// apiCall leaves the dto empty if the word isn't found, so unknown words are misses rather than errors.
+func (wa WordsAPI) apiCall(ctx context.Context, callURL string, dto interface{}) error {
+	if wa.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, wa.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, callURL, http.NoBody)
 	if err != nil {
 		return fmt.Errorf("creation request error: %w", err)
 	}
@@ -91,7 +204,11 @@ func (wa WordsAPI) apiCall(ctx context.Context, url string, dto interface{}) err
 	req.Header.Add("X-RapidAPI-Key", wa.apiKey)
 	req.Header.Add("X-RapidAPI-Host", apiHost)
 
-	resp, err := http.DefaultClient.Do(req)
+	if wa.userAgent != "" {
+		req.Header.Set("User-Agent", wa.userAgent)
+	}
+
+	resp, err := wa.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("request execution error: %w", err)
 	}
@@ -102,8 +219,16 @@ func (wa WordsAPI) apiCall(ctx context.Context, url string, dto interface{}) err
 		}
 	}()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
 	if err = json.NewDecoder(resp.Body).Decode(dto); err != nil {
-		return fmt.Errorf("response decoding to examples error: %w", err)
+		return fmt.Errorf("response decoding error: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,276 @@
+package dictionary_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+)
+
+const (
+	testAPIKey    = "test-api-key"
+	testUserAgent = "vocaboost-test"
+)
+
+// newWordsAPIStub serves recorded WordsAPI responses from testdata/wordsapi, the file path mirrors the request path.
+func newWordsAPIStub(t *testing.T) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		fixture, err := os.ReadFile(filepath.Join("testdata", "wordsapi", filepath.FromSlash(r.URL.Path)+".json"))
+		if err != nil {
+			notFound, _ := os.ReadFile(filepath.Join("testdata", "wordsapi", "not_found.json"))
+
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write(notFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestWordsAPIWordsInfo(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWordsAPIStub(t)
+	wordsAPI := dictionary.NewWordsAPI(testAPIKey, dictionary.WithBaseURL(server.URL), dictionary.WithHTTPClient(server.Client()))
+
+	wordsInfo, err := wordsAPI.WordsInfo(context.Background(), []string{"hello", "abandon"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(wordsInfo) != 2 {
+		t.Fatalf("expected 2 words info, got %d", len(wordsInfo))
+	}
+
+	hello, abandon := wordsInfo[0], wordsInfo[1]
+
+	assertWordInfo(t, hello, "hello", []expectedDefinition{
+		{
+			definition:   "an expression of greeting",
+			partOfSpeech: "noun",
+			synonyms:     []string{"hi", "how-do-you-do", "howdy", "hullo"},
+			antonyms:     nil,
+		},
+	}, []string{"every morning they exchanged polite hellos"})
+	assertPronunciation(t, hello.Pronunciation(), "hɛ'loʊ", []string{"hel", "lo"})
+
+	assertWordInfo(t, abandon, "abandon", []expectedDefinition{
+		{
+			definition:   "forsake, leave behind",
+			partOfSpeech: "verb",
+			synonyms:     []string{"empty", "vacate"},
+			antonyms:     []string{"keep"},
+		},
+		{
+			definition:   "the trait of lacking restraint or control",
+			partOfSpeech: "noun",
+			synonyms:     []string{"wantonness", "unconstraint"},
+			antonyms:     nil,
+		},
+	}, []string{"We abandoned the old car in the empty parking lot", "they danced with abandon"})
+	assertPronunciation(t, abandon.Pronunciation(), "ə'bændən", []string{"a", "ban", "don"})
+}
+
+func TestWordsAPIWordsInfoPronunciationByPartOfSpeech(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWordsAPIStub(t)
+	wordsAPI := dictionary.NewWordsAPI(
+		testAPIKey, dictionary.WithBaseURL(server.URL), dictionary.WithHTTPClient(server.Client()),
+	)
+
+	wordsInfo, err := wordsAPI.WordsInfo(context.Background(), []string{"record"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The pronunciation has no `all` key, the noun one is taken as the noun is the first definition.
+	assertPronunciation(t, wordsInfo[0].Pronunciation(), "'rɛkərd", []string{"rec", "ord"})
+}
+
+func TestWordsAPIWordsInfoNotFoundWordIsMiss(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newWordsAPIStub(t)
+	wordsAPI := dictionary.NewWordsAPI(testAPIKey, dictionary.WithBaseURL(server.URL))
+
+	wordsInfo, err := wordsAPI.WordsInfo(context.Background(), []string{"qwertyuiop"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	assertWordInfo(t, wordsInfo[0], "qwertyuiop", nil, nil)
+
+	if wordsInfo[0].Pronunciation() != nil {
+		t.Errorf("expected no pronunciation, got %+v", wordsInfo[0].Pronunciation())
+	}
+}
+
+func TestWordsAPIWordsInfoSendsHeaders(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newWordsAPIStub(t)
+	wordsAPI := dictionary.NewWordsAPI(
+		testAPIKey, dictionary.WithBaseURL(server.URL+"/"), dictionary.WithUserAgent(testUserAgent),
+	)
+
+	if _, err := wordsAPI.WordsInfo(context.Background(), []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedPaths := []string{"/words/hello", "/words/hello/examples"}
+	if len(*requests) != len(expectedPaths) {
+		t.Fatalf("expected %d requests, got %d", len(expectedPaths), len(*requests))
+	}
+
+	for i, req := range *requests {
+		if req.URL.Path != expectedPaths[i] {
+			t.Errorf("expected path `%s`, got `%s`", expectedPaths[i], req.URL.Path)
+		}
+
+		if key := req.Header.Get("X-RapidAPI-Key"); key != testAPIKey {
+			t.Errorf("expected API key `%s`, got `%s`", testAPIKey, key)
+		}
+
+		if host := req.Header.Get("X-RapidAPI-Host"); host != "wordsapiv1.p.rapidapi.com" {
+			t.Errorf("unexpected API host `%s`", host)
+		}
+
+		if userAgent := req.Header.Get("User-Agent"); userAgent != testUserAgent {
+			t.Errorf("expected user agent `%s`, got `%s`", testUserAgent, userAgent)
+		}
+	}
+}
+
+func TestWordsAPIWordsInfoServerError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"You have exceeded the rate limit per second for your plan"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	wordsAPI := dictionary.NewWordsAPI(testAPIKey, dictionary.WithBaseURL(server.URL))
+
+	if _, err := wordsAPI.WordsInfo(context.Background(), []string{"hello"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWordsAPIWordsInfoTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+
+	wordsAPI := dictionary.NewWordsAPI(
+		testAPIKey, dictionary.WithBaseURL(server.URL), dictionary.WithTimeout(50*time.Millisecond),
+	)
+
+	started := time.Now()
+
+	if _, err := wordsAPI.WordsInfo(context.Background(), []string{"hello"}); err == nil {
+		t.Fatal("expected timeout error, got nil")
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("timeout isn't applied, the call took %s", elapsed)
+	}
+}
+
+type expectedDefinition struct {
+	definition, partOfSpeech string
+	synonyms, antonyms       []string
+}
+
+func assertWordInfo(
+	t *testing.T,
+	wordInfo *dictionary.WordInfoDTO,
+	word string,
+	definitions []expectedDefinition,
+	examples []string,
+) {
+	t.Helper()
+
+	if wordInfo.Word() != word {
+		t.Errorf("expected word `%s`, got `%s`", word, wordInfo.Word())
+	}
+
+	if len(wordInfo.Definitions()) != len(definitions) {
+		t.Fatalf("expected %d definitions of `%s`, got %d", len(definitions), word, len(wordInfo.Definitions()))
+	}
+
+	for i, expected := range definitions {
+		actual := wordInfo.Definitions()[i]
+
+		if actual.Definition() != expected.definition {
+			t.Errorf("expected definition `%s`, got `%s`", expected.definition, actual.Definition())
+		}
+
+		if actual.PartOfSpeech() != expected.partOfSpeech {
+			t.Errorf("expected part of speech `%s`, got `%s`", expected.partOfSpeech, actual.PartOfSpeech())
+		}
+
+		if !reflect.DeepEqual(actual.Synonyms(), expected.synonyms) {
+			t.Errorf("expected synonyms %q, got %q", expected.synonyms, actual.Synonyms())
+		}
+
+		if !reflect.DeepEqual(actual.Antonyms(), expected.antonyms) {
+			t.Errorf("expected antonyms %q, got %q", expected.antonyms, actual.Antonyms())
+		}
+	}
+
+	if len(wordInfo.Examples()) != len(examples) || (len(examples) > 0 && !reflect.DeepEqual(wordInfo.Examples(), examples)) {
+		t.Errorf("expected examples %q, got %q", examples, wordInfo.Examples())
+	}
+}
+
+func assertPronunciation(t *testing.T, pronunciation *dictionary.PronunciationDTO, ipa string, syllables []string) {
+	t.Helper()
+
+	if pronunciation == nil {
+		t.Fatal("expected pronunciation, got nil")
+	}
+
+	if pronunciation.IPA() != ipa {
+		t.Errorf("expected IPA `%s`, got `%s`", ipa, pronunciation.IPA())
+	}
+
+	if !reflect.DeepEqual(pronunciation.Syllables(), syllables) {
+		t.Errorf("expected syllables %q, got %q", syllables, pronunciation.Syllables())
+	}
+}
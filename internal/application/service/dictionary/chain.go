@@ -0,0 +1,197 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var errAllProvidersFailed = errors.New("all dictionary providers failed")
+
+// Chain queries the providers in order and falls back to the next one while a word
+// still lacks definitions or examples. Results of all the queried providers are merged
+// and deduplicated up to the configured limits.
+type Chain struct {
+	providers      []Interface
+	maxDefinitions int
+	maxExamples    int
+}
+
+func NewChain(maxDefinitions, maxExamples int, providers ...Interface) *Chain {
+	return &Chain{providers: providers, maxDefinitions: maxDefinitions, maxExamples: maxExamples}
+}
+
+func (c Chain) WordsInfo(ctx context.Context, words []string) ([]*WordInfoDTO, error) {
+	merged := make([]*wordInfoMerger, len(words))
+	for i := range words {
+		merged[i] = newWordInfoMerger(words[i], c.maxDefinitions, c.maxExamples)
+	}
+
+	var failedProviders int
+
+	for _, provider := range c.providers {
+		pending := make([]string, 0, len(words))
+		// pendingIndexes are the indexes of the pending words, a word may be given more than once.
+		pendingIndexes := make(map[string][]int, len(words))
+
+		for i := range merged {
+			if merged[i].full() {
+				continue
+			}
+
+			if _, isPending := pendingIndexes[words[i]]; !isPending {
+				pending = append(pending, words[i])
+			}
+
+			pendingIndexes[words[i]] = append(pendingIndexes[words[i]], i)
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+
+		wordsInfo, err := provider.WordsInfo(ctx, pending)
+		if err != nil {
+			log.Printf("dictionary provider %T error, falling back to the next one: %s", provider, err)
+
+			failedProviders++
+
+			continue
+		}
+
+		// Providers may skip words or reorder them, so the results are matched to the words by the word.
+		for _, wordInfo := range wordsInfo {
+			if wordInfo == nil {
+				continue
+			}
+
+			indexes, isPending := pendingIndexes[wordInfo.Word()]
+			if !isPending {
+				log.Printf("dictionary provider %T returned word `%s` which wasn't asked for", provider, wordInfo.Word())
+
+				continue
+			}
+
+			for _, i := range indexes {
+				merged[i].merge(wordInfo)
+			}
+		}
+	}
+
+	if len(c.providers) > 0 && failedProviders == len(c.providers) {
+		return nil, fmt.Errorf("%w: %d providers", errAllProvidersFailed, failedProviders)
+	}
+
+	wordsInfo := make([]*WordInfoDTO, len(words))
+	for i := range merged {
+		wordsInfo[i] = merged[i].result()
+	}
+
+	return wordsInfo, nil
+}
+
+type wordInfoMerger struct {
+	word                        string
+	maxDefinitions, maxExamples int
+
+	definitions     []*DefinitionDTO
+	examples        []string
+	pronunciation   *PronunciationDTO
+	seenDefinitions map[string]struct{}
+	seenExamples    map[string]struct{}
+}
+
+func newWordInfoMerger(word string, maxDefinitions, maxExamples int) *wordInfoMerger {
+	return &wordInfoMerger{
+		word:            word,
+		maxDefinitions:  maxDefinitions,
+		maxExamples:     maxExamples,
+		definitions:     make([]*DefinitionDTO, 0, maxDefinitions),
+		examples:        make([]string, 0, maxExamples),
+		pronunciation:   nil,
+		seenDefinitions: make(map[string]struct{}),
+		seenExamples:    make(map[string]struct{}),
+	}
+}
+
+func (m *wordInfoMerger) full() bool {
+	return len(m.definitions) >= m.maxDefinitions && len(m.examples) >= m.maxExamples
+}
+
+func (m *wordInfoMerger) merge(wordInfo *WordInfoDTO) {
+	if wordInfo == nil {
+		return
+	}
+
+	m.mergePronunciation(wordInfo.pronunciation)
+
+	for _, definition := range wordInfo.definitionsDTO {
+		if len(m.definitions) >= m.maxDefinitions {
+			break
+		}
+
+		if definition == nil {
+			continue
+		}
+
+		key := normalizeText(definition.definition)
+		if _, seen := m.seenDefinitions[key]; seen || key == "" {
+			continue
+		}
+
+		m.seenDefinitions[key] = struct{}{}
+		m.definitions = append(m.definitions, definition)
+	}
+
+	for _, example := range wordInfo.examples {
+		if len(m.examples) >= m.maxExamples {
+			break
+		}
+
+		key := normalizeText(example)
+		if _, seen := m.seenExamples[key]; seen || key == "" {
+			continue
+		}
+
+		m.seenExamples[key] = struct{}{}
+		m.examples = append(m.examples, example)
+	}
+}
+
+// mergePronunciation fills the parts of the pronunciation which previous providers didn't have.
+func (m *wordInfoMerger) mergePronunciation(pronunciation *PronunciationDTO) {
+	switch {
+	case pronunciation == nil:
+		return
+	case m.pronunciation == nil:
+		m.pronunciation = pronunciation
+
+		return
+	}
+
+	ipa, syllables, audioURL := m.pronunciation.ipa, m.pronunciation.syllables, m.pronunciation.audioURL
+
+	if ipa == "" {
+		ipa = pronunciation.ipa
+	}
+
+	if len(syllables) == 0 {
+		syllables = pronunciation.syllables
+	}
+
+	if audioURL == "" {
+		audioURL = pronunciation.audioURL
+	}
+
+	m.pronunciation = NewPronunciationDTO(ipa, syllables, audioURL)
+}
+
+func (m *wordInfoMerger) result() *WordInfoDTO {
+	return NewWordInfoDTO(m.word, m.definitions, m.examples, m.pronunciation)
+}
+
+func normalizeText(text string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(text)), ".!;")
+}
@@ -0,0 +1,197 @@
+package dictionary_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+)
+
+var errProvider = errors.New("provider error")
+
+// fakeProvider returns the words it knows in reverse order and skips the rest, the words it's asked for
+// are recorded.
+type fakeProvider struct {
+	words   map[string]*dictionary.WordInfoDTO
+	err     error
+	queried [][]string
+}
+
+func (f *fakeProvider) WordsInfo(_ context.Context, words []string) ([]*dictionary.WordInfoDTO, error) {
+	f.queried = append(f.queried, words)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	wordsInfo := make([]*dictionary.WordInfoDTO, 0, len(words))
+
+	for i := len(words) - 1; i >= 0; i-- {
+		if wordInfo, ok := f.words[words[i]]; ok {
+			wordsInfo = append(wordsInfo, wordInfo)
+		}
+	}
+
+	return wordsInfo, nil
+}
+
+func definitionsOf(wordInfo *dictionary.WordInfoDTO) []string {
+	definitions := make([]string, len(wordInfo.Definitions()))
+	for i, definition := range wordInfo.Definitions() {
+		definitions[i] = definition.Definition()
+	}
+
+	return definitions
+}
+
+func TestChainMergesProviders(t *testing.T) {
+	t.Parallel()
+
+	first := &fakeProvider{
+		words: map[string]*dictionary.WordInfoDTO{
+			"cat": dictionary.NewWordInfoDTO(
+				"cat",
+				[]*dictionary.DefinitionDTO{dictionary.NewDefinition("A small animal.", "noun", nil, nil)},
+				[]string{"a black cat"},
+				dictionary.NewPronunciationDTO("kæt", nil, ""),
+			),
+		},
+		err:     nil,
+		queried: nil,
+	}
+	second := &fakeProvider{
+		words: map[string]*dictionary.WordInfoDTO{
+			"cat": dictionary.NewWordInfoDTO(
+				"cat",
+				[]*dictionary.DefinitionDTO{
+					dictionary.NewDefinition("a small animal", "noun", nil, nil),
+					dictionary.NewDefinition("a jazz player", "noun", nil, nil),
+					dictionary.NewDefinition("a whip", "noun", nil, nil),
+				},
+				[]string{"A black cat!", "the cat sat", "a lazy cat", ""},
+				dictionary.NewPronunciationDTO("kat", []string{"cat"}, "https://audio.test/cat.mp3"),
+			),
+			"dog": dictionary.NewWordInfoDTO(
+				"dog",
+				[]*dictionary.DefinitionDTO{dictionary.NewDefinition("a pet", "noun", nil, nil)},
+				nil,
+				nil,
+			),
+		},
+		err:     nil,
+		queried: nil,
+	}
+
+	wordsInfo, err := dictionary.NewChain(2, 2, first, second).WordsInfo(context.Background(), []string{"dog", "cat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	dog, cat := wordsInfo[0], wordsInfo[1]
+	if dog.Word() != "dog" || !reflect.DeepEqual(definitionsOf(dog), []string{"a pet"}) {
+		t.Errorf("got dog %q with definitions %q", dog.Word(), definitionsOf(dog))
+	}
+
+	if want := []string{"A small animal.", "a jazz player"}; cat.Word() != "cat" ||
+		!reflect.DeepEqual(definitionsOf(cat), want) {
+		t.Errorf("got cat %q with definitions %q, want %q", cat.Word(), definitionsOf(cat), want)
+	}
+
+	if want := []string{"a black cat", "the cat sat"}; !reflect.DeepEqual(cat.Examples(), want) {
+		t.Errorf("got examples %q, want %q", cat.Examples(), want)
+	}
+
+	pronunciation := cat.Pronunciation()
+	if pronunciation.IPA() != "kæt" || !reflect.DeepEqual(pronunciation.Syllables(), []string{"cat"}) ||
+		pronunciation.AudioURL() != "https://audio.test/cat.mp3" {
+		t.Errorf("got pronunciation %+v, want the first IPA and the rest from the second provider", pronunciation)
+	}
+}
+
+func TestChainQueriesOnlyWordsLackingMaterial(t *testing.T) {
+	t.Parallel()
+
+	full := dictionary.NewWordInfoDTO(
+		"cat", []*dictionary.DefinitionDTO{dictionary.NewDefinition("a small animal", "noun", nil, nil)},
+		[]string{"a black cat"}, nil,
+	)
+	first := &fakeProvider{words: map[string]*dictionary.WordInfoDTO{"cat": full}, err: nil, queried: nil}
+	second := &fakeProvider{words: nil, err: nil, queried: nil}
+	third := &fakeProvider{words: nil, err: nil, queried: nil}
+
+	wordsInfo, err := dictionary.NewChain(1, 1, first, second, third).WordsInfo(
+		context.Background(), []string{"cat", "purr", "purr"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := [][]string{{"cat", "purr"}}; !reflect.DeepEqual(first.queried, want) {
+		t.Errorf("got the first provider queried for %q, want %q", first.queried, want)
+	}
+
+	if want := [][]string{{"purr"}}; !reflect.DeepEqual(second.queried, want) ||
+		!reflect.DeepEqual(third.queried, want) {
+		t.Errorf("got the next providers queried for %q and %q, want %q", second.queried, third.queried, want)
+	}
+
+	if len(wordsInfo) != 3 || wordsInfo[1].Word() != "purr" || wordsInfo[2].Word() != "purr" ||
+		len(wordsInfo[1].Definitions()) != 0 {
+		t.Errorf("got words info %+v, want purr twice without material", wordsInfo)
+	}
+}
+
+func TestChainFallsBackOnProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	failing := &fakeProvider{words: nil, err: errProvider, queried: nil}
+	working := &fakeProvider{
+		words: map[string]*dictionary.WordInfoDTO{
+			"cat": dictionary.NewWordInfoDTO(
+				"cat", []*dictionary.DefinitionDTO{dictionary.NewDefinition("a small animal", "noun", nil, nil)}, nil, nil,
+			),
+		},
+		err:     nil,
+		queried: nil,
+	}
+
+	wordsInfo, err := dictionary.NewChain(4, 4, failing, working).WordsInfo(context.Background(), []string{"cat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := []string{"a small animal"}; !reflect.DeepEqual(definitionsOf(wordsInfo[0]), want) {
+		t.Errorf("got definitions %q, want %q", definitionsOf(wordsInfo[0]), want)
+	}
+
+	if _, err = dictionary.NewChain(4, 4, failing, failing).WordsInfo(
+		context.Background(), []string{"cat"},
+	); err == nil {
+		t.Error("got no error, want an error when all the providers fail")
+	}
+}
+
+func TestChainIgnoresWordsNotAskedFor(t *testing.T) {
+	t.Parallel()
+
+	provider := &fakeProvider{
+		words: map[string]*dictionary.WordInfoDTO{
+			"cat": dictionary.NewWordInfoDTO(
+				"dog", []*dictionary.DefinitionDTO{dictionary.NewDefinition("a pet", "noun", nil, nil)}, nil, nil,
+			),
+		},
+		err:     nil,
+		queried: nil,
+	}
+
+	wordsInfo, err := dictionary.NewChain(4, 4, provider).WordsInfo(context.Background(), []string{"cat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if wordsInfo[0].Word() != "cat" || len(wordsInfo[0].Definitions()) != 0 {
+		t.Errorf("got word %q with definitions %q, want cat without any", wordsInfo[0].Word(), definitionsOf(wordsInfo[0]))
+	}
+}
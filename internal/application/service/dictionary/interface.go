@@ -7,11 +7,20 @@ type (
 		word           string
 		definitionsDTO []*DefinitionDTO
 		examples       []string
+		pronunciation  *PronunciationDTO
 	}
 
 	DefinitionDTO struct {
 		definition   string
 		partOfSpeech string
+		synonyms     []string
+		antonyms     []string
+	}
+
+	PronunciationDTO struct {
+		ipa       string
+		syllables []string
+		audioURL  string
 	}
 )
 
@@ -19,25 +28,61 @@ func (w WordInfoDTO) Word() string {
 	return w.word
 }
 
-func (w WordInfoDTO) Definitions() []string {
-	slice := make([]string, len(w.definitionsDTO))
-	for i := range w.definitionsDTO {
-		slice[i] = w.definitionsDTO[i].definition
-	}
-
-	return slice
+func (w WordInfoDTO) Definitions() []*DefinitionDTO {
+	return w.definitionsDTO
 }
 
 func (w WordInfoDTO) Examples() []string {
 	return w.examples
 }
 
-func NewWordInfoDTO(word string, definitions []*DefinitionDTO, examples []string) *WordInfoDTO {
-	return &WordInfoDTO{word: word, definitionsDTO: definitions, examples: examples}
+func (w WordInfoDTO) Pronunciation() *PronunciationDTO {
+	return w.pronunciation
+}
+
+func NewWordInfoDTO(
+	word string,
+	definitions []*DefinitionDTO,
+	examples []string,
+	pronunciation *PronunciationDTO,
+) *WordInfoDTO {
+	return &WordInfoDTO{word: word, definitionsDTO: definitions, examples: examples, pronunciation: pronunciation}
+}
+
+func (d DefinitionDTO) Definition() string {
+	return d.definition
+}
+
+func (d DefinitionDTO) PartOfSpeech() string {
+	return d.partOfSpeech
+}
+
+func (d DefinitionDTO) Synonyms() []string {
+	return d.synonyms
+}
+
+func (d DefinitionDTO) Antonyms() []string {
+	return d.antonyms
+}
+
+func NewDefinition(definition, partOfSpeech string, synonyms, antonyms []string) *DefinitionDTO {
+	return &DefinitionDTO{definition: definition, partOfSpeech: partOfSpeech, synonyms: synonyms, antonyms: antonyms}
+}
+
+func (p PronunciationDTO) IPA() string {
+	return p.ipa
+}
+
+func (p PronunciationDTO) Syllables() []string {
+	return p.syllables
+}
+
+func (p PronunciationDTO) AudioURL() string {
+	return p.audioURL
 }
 
-func NewDefinition(definition, partOfSpeech string) *DefinitionDTO {
-	return &DefinitionDTO{definition: definition, partOfSpeech: partOfSpeech}
+func NewPronunciationDTO(ipa string, syllables []string, audioURL string) *PronunciationDTO {
+	return &PronunciationDTO{ipa: ipa, syllables: syllables, audioURL: audioURL}
 }
 
 type Interface interface {
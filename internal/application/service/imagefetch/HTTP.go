@@ -0,0 +1,63 @@
+package imagefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+var (
+	errUnexpectedStatus = errors.New("unexpected response status")
+	errFileTooLarge     = errors.New("the image file is too large")
+)
+
+// HTTP downloads images, files larger than the maximum size are refused.
+type HTTP struct {
+	httpClient   *http.Client
+	userAgent    string
+	maxFileBytes int64
+}
+
+func NewHTTP(httpClient *http.Client, userAgent string, maxFileBytes int64) *HTTP {
+	return &HTTP{httpClient: httpClient, userAgent: userAgent, maxFileBytes: maxFileBytes}
+}
+
+func (h HTTP) Fetch(ctx context.Context, imageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creation request error: %w", err)
+	}
+
+	if h.userAgent != "" {
+		req.Header.Set("User-Agent", h.userAgent)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request execution error: %w", err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, h.maxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response error: %w", err)
+	}
+
+	if int64(len(content)) > h.maxFileBytes {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", errFileTooLarge, h.maxFileBytes)
+	}
+
+	return content, nil
+}
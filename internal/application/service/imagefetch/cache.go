@@ -0,0 +1,103 @@
+package imagefetch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type cachedImage struct {
+	content   []byte
+	fetchedAt time.Time
+}
+
+// Cache keeps recently fetched images in memory, so an image the quality filter has downloaded for the preview
+// isn't downloaded again when it's stored on upload. The oldest images are evicted once the cache outgrows
+// the maximum size, failed fetches aren't cached.
+type Cache struct {
+	fetcher  Interface
+	maxBytes int64
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	images map[string]cachedImage
+	// order lists the cached URLs from the oldest to the newest.
+	order []string
+	size  int64
+}
+
+func NewCache(fetcher Interface, maxBytes int64, ttl time.Duration) *Cache {
+	return &Cache{
+		fetcher:  fetcher,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		mu:       sync.Mutex{},
+		images:   make(map[string]cachedImage),
+		order:    make([]string, 0),
+		size:     0,
+	}
+}
+
+func (c *Cache) Fetch(ctx context.Context, imageURL string) ([]byte, error) {
+	if content, ok := c.cached(imageURL); ok {
+		return content, nil
+	}
+
+	content, err := c.fetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching image error: %w", err)
+	}
+
+	c.keep(imageURL, content)
+
+	return content, nil
+}
+
+func (c *Cache) cached(imageURL string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	image, ok := c.images[imageURL]
+	if !ok || time.Since(image.fetchedAt) > c.ttl {
+		return nil, false
+	}
+
+	return image.content, true
+}
+
+func (c *Cache) keep(imageURL string, content []byte) {
+	if int64(len(content)) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous, ok := c.images[imageURL]; ok {
+		c.size -= int64(len(previous.content))
+		c.forget(imageURL)
+	}
+
+	c.images[imageURL] = cachedImage{content: content, fetchedAt: time.Now()}
+	c.order = append(c.order, imageURL)
+	c.size += int64(len(content))
+
+	for c.size > c.maxBytes {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		c.size -= int64(len(c.images[oldest].content))
+		delete(c.images, oldest)
+	}
+}
+
+// forget removes the URL from the eviction order.
+func (c *Cache) forget(imageURL string) {
+	for i := range c.order {
+		if c.order[i] == imageURL {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+
+			return
+		}
+	}
+}
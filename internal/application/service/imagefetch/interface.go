@@ -0,0 +1,8 @@
+package imagefetch
+
+import "context"
+
+type Interface interface {
+	// Fetch returns the content of the image file, callers share it and mustn't modify it.
+	Fetch(ctx context.Context, imageURL string) ([]byte, error)
+}
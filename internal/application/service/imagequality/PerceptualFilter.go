@@ -0,0 +1,161 @@
+package imagequality
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // registers the GIF decoder
+	_ "image/jpeg" // registers the JPEG decoder
+	_ "image/png"  // registers the PNG decoder
+	"log"
+	"sync"
+
+	"github.com/r-erema/vocaboost/internal/application/service/imagefetch"
+)
+
+var (
+	errImageTooLarge    = errors.New("the image is too large")
+	errImageTooSmall    = errors.New("the image is too small")
+	errBadAspectRatio   = errors.New("the image aspect ratio is too extreme")
+	errImageDecoding    = errors.New("image decoding error")
+	errImageDownloading = errors.New("image downloading error")
+)
+
+// PerceptualFilter downloads the images and drops the ones which are smaller than the minimum size,
+// larger than the maximum number of pixels, have an extreme aspect ratio, can't be downloaded or decoded,
+// or look like a preceding image, i.e. their perceptual hashes differ in no more bits than the maximum distance.
+type PerceptualFilter struct {
+	fetcher        imagefetch.Interface
+	maxPixels      int
+	minWidth       int
+	minHeight      int
+	maxAspectRatio float64
+	maxDistance    int
+	concurrency    int
+}
+
+func NewPerceptualFilter(
+	fetcher imagefetch.Interface,
+	maxPixels, minWidth, minHeight int,
+	maxAspectRatio float64,
+	maxDistance, concurrency int,
+) *PerceptualFilter {
+	return &PerceptualFilter{
+		fetcher:        fetcher,
+		maxPixels:      maxPixels,
+		minWidth:       minWidth,
+		minHeight:      minHeight,
+		maxAspectRatio: maxAspectRatio,
+		maxDistance:    maxDistance,
+		concurrency:    concurrency,
+	}
+}
+
+type inspectedImage struct {
+	hash uint64
+	err  error
+}
+
+func (pf PerceptualFilter) Filter(ctx context.Context, imageURLs []string) ([]string, error) {
+	inspected := make([]inspectedImage, len(imageURLs))
+	semaphore := make(chan struct{}, pf.concurrency)
+
+	var wg sync.WaitGroup
+
+	for i := range imageURLs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			hash, err := pf.inspect(ctx, imageURLs[i])
+			inspected[i] = inspectedImage{hash: hash, err: err}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("filtering images interrupted: %w", ctx.Err())
+	}
+
+	filtered := make([]string, 0, len(imageURLs))
+	keptHashes := make([]uint64, 0, len(imageURLs))
+
+	for i := range inspected {
+		if inspected[i].err != nil {
+			log.Printf("image `%s` is filtered out: %s", imageURLs[i], inspected[i].err)
+
+			continue
+		}
+
+		if pf.duplicated(inspected[i].hash, keptHashes) {
+			continue
+		}
+
+		keptHashes = append(keptHashes, inspected[i].hash)
+		filtered = append(filtered, imageURLs[i])
+	}
+
+	return filtered, nil
+}
+
+func (pf PerceptualFilter) duplicated(hash uint64, keptHashes []uint64) bool {
+	for _, keptHash := range keptHashes {
+		if hammingDistance(hash, keptHash) <= pf.maxDistance {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pf PerceptualFilter) inspect(ctx context.Context, imageURL string) (uint64, error) {
+	content, err := pf.fetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", errImageDownloading, err.Error())
+	}
+
+	// The header is enough to check the size, so images which don't fit aren't decoded at all.
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", errImageDecoding, err.Error())
+	}
+
+	if err = pf.checkSize(config.Width, config.Height); err != nil {
+		return 0, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", errImageDecoding, err.Error())
+	}
+
+	return perceptualHash(img), nil
+}
+
+func (pf PerceptualFilter) checkSize(width, height int) error {
+	if width < pf.minWidth || height < pf.minHeight {
+		return fmt.Errorf("%w: %dx%d", errImageTooSmall, width, height)
+	}
+
+	if width*height > pf.maxPixels {
+		return fmt.Errorf("%w: %dx%d is more than %d pixels", errImageTooLarge, width, height, pf.maxPixels)
+	}
+
+	longSide, shortSide := float64(width), float64(height)
+	if shortSide > longSide {
+		longSide, shortSide = shortSide, longSide
+	}
+
+	if longSide/shortSide > pf.maxAspectRatio {
+		return fmt.Errorf("%w: %dx%d", errBadAspectRatio, width, height)
+	}
+
+	return nil
+}
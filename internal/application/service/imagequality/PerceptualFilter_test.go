@@ -0,0 +1,94 @@
+package imagequality
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+var errNotFound = errors.New("not found")
+
+type fakeFetcher map[string][]byte
+
+func (f fakeFetcher) Fetch(_ context.Context, imageURL string) ([]byte, error) {
+	content, ok := f[imageURL]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	return content, nil
+}
+
+func encodedPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encoding image error: %s", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestPerceptualFilter(t *testing.T) {
+	t.Parallel()
+
+	fetcher := fakeFetcher{
+		"landscape.png":        encodedPNG(t, testImage(400, 300, waves)),
+		"landscape-large.png":  encodedPNG(t, testImage(800, 600, waves)),
+		"landscape-noisy.png":  encodedPNG(t, testImage(400, 300, noisy(waves))),
+		"object.png":           encodedPNG(t, testImage(300, 300, rings)),
+		"object-brighter.png":  encodedPNG(t, testImage(300, 300, brighter(rings))),
+		"pattern.png":          encodedPNG(t, testImage(300, 300, checkerboard)),
+		"icon.png":             encodedPNG(t, testImage(64, 64, rings)),
+		"banner.png":           encodedPNG(t, testImage(1000, 200, checkerboard)),
+		"huge.png":             encodedPNG(t, testImage(1000, 1000, waves)),
+		"not-an-image.png":     []byte("<html>not found</html>"),
+		"truncated-object.png": encodedPNG(t, testImage(300, 300, rings))[:100],
+	}
+
+	for name, testCase := range map[string]struct {
+		imageURLs []string
+		want      []string
+	}{
+		"near duplicates keep the first image": {
+			imageURLs: []string{
+				"landscape.png", "object.png", "landscape-large.png", "object-brighter.png", "landscape-noisy.png",
+			},
+			want: []string{"landscape.png", "object.png"},
+		},
+		"distinct images are kept": {
+			imageURLs: []string{"pattern.png", "object.png", "landscape.png"},
+			want:      []string{"pattern.png", "object.png", "landscape.png"},
+		},
+		"unfit images are dropped": {
+			imageURLs: []string{"icon.png", "banner.png", "huge.png", "object.png"},
+			want:      []string{"object.png"},
+		},
+		"broken images are dropped": {
+			imageURLs: []string{"missing.png", "not-an-image.png", "truncated-object.png", "object-brighter.png"},
+			want:      []string{"object-brighter.png"},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := NewPerceptualFilter(fetcher, 500_000, 100, 100, 3, testDuplicateDistance, 2) //nolint:gomnd
+
+			filtered, err := filter.Filter(context.Background(), testCase.imageURLs)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(filtered, testCase.want) {
+				t.Errorf("got images %q, want %q", filtered, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package imagequality
+
+import "context"
+
+type Interface interface {
+	// Filter drops low quality images and near-duplicates of preceding ones keeping the order of the rest.
+	Filter(ctx context.Context, imageURLs []string) ([]string, error)
+}
@@ -0,0 +1,101 @@
+package imagequality
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	phashSampleSide = 32
+	phashLowSide    = 8
+)
+
+// perceptualHash computes the DCT based 64 bit hash: the image is scaled down to 32x32 grayscale,
+// the lowest 8x8 frequencies are compared with their median. Similar images have close hashes.
+func perceptualHash(img image.Image) uint64 {
+	gray := image.NewGray(image.Rect(0, 0, phashSampleSide, phashSampleSide))
+	draw.ApproxBiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	pixels := make([][]float64, phashSampleSide)
+	for y := range pixels {
+		pixels[y] = make([]float64, phashSampleSide)
+		for x := range pixels[y] {
+			pixels[y][x] = float64(gray.GrayAt(x, y).Y)
+		}
+	}
+
+	frequencies := dct2D(pixels)
+
+	lowFrequencies := make([]float64, 0, phashLowSide*phashLowSide)
+	for y := 0; y < phashLowSide; y++ {
+		lowFrequencies = append(lowFrequencies, frequencies[y][:phashLowSide]...)
+	}
+
+	// The DC coefficient is the average brightness, it's left out of the median.
+	sorted := append([]float64{}, lowFrequencies[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+
+	for i, frequency := range lowFrequencies {
+		if frequency > median {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func dct2D(matrix [][]float64) [][]float64 {
+	size := len(matrix)
+
+	rows := make([][]float64, size)
+	for y := range matrix {
+		rows[y] = dct1D(matrix[y])
+	}
+
+	result := make([][]float64, size)
+	for y := range result {
+		result[y] = make([]float64, size)
+	}
+
+	column := make([]float64, size)
+
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			column[y] = rows[y][x]
+		}
+
+		transformed := dct1D(column)
+		for y := 0; y < size; y++ {
+			result[y][x] = transformed[y]
+		}
+	}
+
+	return result
+}
+
+func dct1D(values []float64) []float64 {
+	size := len(values)
+	result := make([]float64, size)
+
+	for k := 0; k < size; k++ {
+		var sum float64
+		for n, value := range values {
+			sum += value * math.Cos(math.Pi/float64(size)*(float64(n)+0.5)*float64(k))
+		}
+
+		result[k] = sum
+	}
+
+	return result
+}
@@ -0,0 +1,137 @@
+package imagequality
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// testDuplicateDistance is the default IMAGE_DUPLICATE_DISTANCE.
+const testDuplicateDistance = 10
+
+// testImage draws the pattern, its coordinates are scaled into [0, 1), so an image looks the same at any size.
+func testImage(width, height int, pattern func(x, y float64) uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: pattern(float64(x)/float64(width), float64(y)/float64(height))})
+		}
+	}
+
+	return img
+}
+
+// waves looks like a landscape: a few soft overlapping shapes rather than a flat fill.
+func waves(x, y float64) uint8 {
+	return uint8(128 + 60*math.Sin(3*x+1) + 50*math.Cos(5*y-2*x)) //nolint:gomnd
+}
+
+// rings looks like a centered object.
+func rings(x, y float64) uint8 {
+	return uint8(128 + 110*math.Cos(10*math.Hypot(x-0.5, y-0.5))) //nolint:gomnd
+}
+
+func checkerboard(x, y float64) uint8 {
+	if (int(x*4)+int(y*4))%2 == 0 { //nolint:gomnd
+		return 255 //nolint:gomnd
+	}
+
+	return 0
+}
+
+// brighter lightens the pattern keeping its shape.
+func brighter(pattern func(x, y float64) uint8) func(x, y float64) uint8 {
+	return func(x, y float64) uint8 {
+		return uint8(20 + float64(pattern(x, y))*0.9) //nolint:gomnd
+	}
+}
+
+// noisy adds a faint grain, like JPEG compression artifacts.
+func noisy(pattern func(x, y float64) uint8) func(x, y float64) uint8 {
+	return func(x, y float64) uint8 {
+		grain := 12 * math.Sin(x*997+y*313) //nolint:gomnd
+
+		return uint8(math.Max(0, math.Min(255, float64(pattern(x, y))+grain))) //nolint:gomnd
+	}
+}
+
+func TestPerceptualHashDistance(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		a, b      image.Image
+		duplicate bool
+	}{
+		"same image": {
+			a:         testImage(300, 300, waves),
+			b:         testImage(300, 300, waves),
+			duplicate: true,
+		},
+		"scaled copy": {
+			a:         testImage(300, 200, checkerboard),
+			b:         testImage(900, 600, checkerboard),
+			duplicate: true,
+		},
+		"brightened copy": {
+			a:         testImage(300, 300, waves),
+			b:         testImage(300, 300, brighter(waves)),
+			duplicate: true,
+		},
+		"noisy copy": {
+			a:         testImage(400, 400, waves),
+			b:         testImage(400, 400, noisy(waves)),
+			duplicate: true,
+		},
+		"landscape and object": {
+			a:         testImage(300, 300, waves),
+			b:         testImage(300, 300, rings),
+			duplicate: false,
+		},
+		"landscape and pattern": {
+			a:         testImage(300, 300, waves),
+			b:         testImage(300, 300, checkerboard),
+			duplicate: false,
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			distance := hammingDistance(perceptualHash(testCase.a), perceptualHash(testCase.b))
+			if duplicate := distance <= testDuplicateDistance; duplicate != testCase.duplicate {
+				t.Errorf(
+					"got distance %d, want duplicate %t at the distance %d", distance, testCase.duplicate, testDuplicateDistance,
+				)
+			}
+		})
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		a, b     uint64
+		distance int
+	}{
+		"equal":         {a: 0xF0F0, b: 0xF0F0, distance: 0},
+		"one bit":       {a: 0b1000, b: 0b1001, distance: 1},
+		"lowest byte":   {a: 0xFF, b: 0, distance: 8},
+		"opposite":      {a: 0, b: ^uint64(0), distance: 64},
+		"highest bits":  {a: 1 << 63, b: 1 << 62, distance: 2},
+		"symmetric mix": {a: 0b1100, b: 0b0110, distance: 2},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if distance := hammingDistance(testCase.a, testCase.b); distance != testCase.distance {
+				t.Errorf("got distance %d, want %d", distance, testCase.distance)
+			}
+		})
+	}
+}
@@ -7,20 +7,37 @@ import (
 	"google.golang.org/api/customsearch/v1"
 )
 
+const (
+	googleSafeSearchActive = "active"
+	googleSafeSearchOff    = "off"
+)
+
 type GoogleCustomSearch struct {
-	service  *customsearch.Service
-	engineID string
+	service    *customsearch.Service
+	engineID   string
+	safeSearch string
 }
 
-func NewGoogleCustomSearch(service *customsearch.Service, engineID string) *GoogleCustomSearch {
-	return &GoogleCustomSearch{service: service, engineID: engineID}
+// NewGoogleCustomSearch creates the provider, Google has only on and off safe search,
+// both moderate and strict turn it on.
+func NewGoogleCustomSearch(
+	service *customsearch.Service,
+	engineID string,
+	safeSearch SafeSearch,
+) *GoogleCustomSearch {
+	googleSafeSearch := googleSafeSearchActive
+	if safeSearch == SafeSearchOff {
+		googleSafeSearch = googleSafeSearchOff
+	}
+
+	return &GoogleCustomSearch{service: service, engineID: engineID, safeSearch: googleSafeSearch}
 }
 
 func (gcs GoogleCustomSearch) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
 	wordImages := make([]*WordImagesDTO, len(words))
 
 	for i := range words {
-		resp, err := gcs.service.Cse.List().Context(ctx).SearchType("image").Cx(gcs.engineID).Q(words[i]).Do()
+		resp, err := gcs.service.Cse.List().Context(ctx).SearchType("image").Cx(gcs.engineID).Safe(gcs.safeSearch).Q(words[i]).Do()
 		if err != nil {
 			return nil, fmt.Errorf("custom search call error for word `%s`: %w", words[i], err)
 		}
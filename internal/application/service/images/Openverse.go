@@ -0,0 +1,68 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const openverseAPIURL = "https://api.openverse.org/v1/images/"
+
+// Openverse searches openly licensed images, the token is optional, anonymous requests have a lower rate limit.
+type Openverse struct {
+	httpClient *http.Client
+	token      string
+	userAgent  string
+	mature     bool
+}
+
+type openverseResponse struct {
+	Results []struct {
+		URL      string `json:"url"`
+		Filetype string `json:"filetype"`
+	} `json:"results"`
+}
+
+// NewOpenverse creates the provider, mature content is included only with the safe search turned off.
+func NewOpenverse(httpClient *http.Client, token, userAgent string, safeSearch SafeSearch) *Openverse {
+	return &Openverse{httpClient: httpClient, token: token, userAgent: userAgent, mature: safeSearch == SafeSearchOff}
+}
+
+func (o Openverse) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	wordImages := make([]*WordImagesDTO, len(words))
+
+	headers := map[string]string{"User-Agent": o.userAgent}
+	if o.token != "" {
+		headers["Authorization"] = "Bearer " + o.token
+	}
+
+	for i := range words {
+		query := url.Values{
+			"q":         {words[i]},
+			"page_size": {strconv.Itoa(resultsPerQuery)},
+			"mature":    {strconv.FormatBool(o.mature)},
+			"extension": {"jpg,jpeg,png"},
+		}
+
+		resp := new(openverseResponse)
+		if err := apiCall(ctx, o.httpClient, openverseAPIURL+"?"+query.Encode(), headers, resp); err != nil {
+			return nil, fmt.Errorf("openverse call error for word `%s`: %w", words[i], err)
+		}
+
+		links := make([]string, 0, len(resp.Results))
+
+		for _, result := range resp.Results {
+			switch strings.ToLower(result.Filetype) {
+			case "jpg", "jpeg", "png", "":
+				links = append(links, result.URL)
+			}
+		}
+
+		wordImages[i] = NewWordImagesDTO(words[i], links)
+	}
+
+	return wordImages, nil
+}
@@ -0,0 +1,56 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const pixabayAPIURL = "https://pixabay.com/api/"
+
+type Pixabay struct {
+	httpClient *http.Client
+	apiKey     string
+	safeSearch SafeSearch
+}
+
+type pixabayResponse struct {
+	Hits []struct {
+		WebformatURL string `json:"webformatURL"` //nolint:tagliatelle
+	} `json:"hits"`
+}
+
+// NewPixabay creates the provider, Pixabay has only on and off safe search, both moderate and strict turn it on.
+func NewPixabay(httpClient *http.Client, apiKey string, safeSearch SafeSearch) *Pixabay {
+	return &Pixabay{httpClient: httpClient, apiKey: apiKey, safeSearch: safeSearch}
+}
+
+func (p Pixabay) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	wordImages := make([]*WordImagesDTO, len(words))
+
+	for i := range words {
+		query := url.Values{
+			"key":        {p.apiKey},
+			"q":          {words[i]},
+			"image_type": {"photo"},
+			"per_page":   {strconv.Itoa(resultsPerQuery)},
+			"safesearch": {strconv.FormatBool(p.safeSearch != SafeSearchOff)},
+		}
+
+		resp := new(pixabayResponse)
+		if err := apiCall(ctx, p.httpClient, pixabayAPIURL+"?"+query.Encode(), nil, resp); err != nil {
+			return nil, fmt.Errorf("pixabay call error for word `%s`: %w", words[i], err)
+		}
+
+		links := make([]string, 0, len(resp.Hits))
+		for _, hit := range resp.Hits {
+			links = append(links, hit.WebformatURL)
+		}
+
+		wordImages[i] = NewWordImagesDTO(words[i], links)
+	}
+
+	return wordImages, nil
+}
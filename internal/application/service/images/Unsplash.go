@@ -0,0 +1,68 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const unsplashAPIURL = "https://api.unsplash.com/search/photos"
+
+type Unsplash struct {
+	httpClient    *http.Client
+	accessKey     string
+	contentFilter string
+}
+
+const (
+	unsplashContentFilterLow  = "low"
+	unsplashContentFilterHigh = "high"
+)
+
+type unsplashResponse struct {
+	Results []struct {
+		URLs struct {
+			Regular string `json:"regular"`
+		} `json:"urls"`
+	} `json:"results"`
+}
+
+// NewUnsplash creates the provider, Unsplash always filters explicit content out,
+// the strict safe search makes the filter stricter.
+func NewUnsplash(httpClient *http.Client, accessKey string, safeSearch SafeSearch) *Unsplash {
+	contentFilter := unsplashContentFilterLow
+	if safeSearch == SafeSearchStrict {
+		contentFilter = unsplashContentFilterHigh
+	}
+
+	return &Unsplash{httpClient: httpClient, accessKey: accessKey, contentFilter: contentFilter}
+}
+
+func (u Unsplash) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	wordImages := make([]*WordImagesDTO, len(words))
+	headers := map[string]string{"Authorization": "Client-ID " + u.accessKey, "Accept-Version": "v1"}
+
+	for i := range words {
+		query := url.Values{
+			"query":          {words[i]},
+			"per_page":       {strconv.Itoa(resultsPerQuery)},
+			"content_filter": {u.contentFilter},
+		}
+
+		resp := new(unsplashResponse)
+		if err := apiCall(ctx, u.httpClient, unsplashAPIURL+"?"+query.Encode(), headers, resp); err != nil {
+			return nil, fmt.Errorf("unsplash call error for word `%s`: %w", words[i], err)
+		}
+
+		links := make([]string, 0, len(resp.Results))
+		for _, result := range resp.Results {
+			links = append(links, result.URLs.Regular)
+		}
+
+		wordImages[i] = NewWordImagesDTO(words[i], links)
+	}
+
+	return wordImages, nil
+}
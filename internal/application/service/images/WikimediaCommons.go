@@ -0,0 +1,86 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+const (
+	wikimediaCommonsAPIURL = "https://commons.wikimedia.org/w/api.php"
+	wikimediaFileNamespace = "6"
+)
+
+// WikimediaCommons searches files of Wikimedia Commons, its API policy requires a descriptive user agent.
+// The API has no safe search, so the provider is left out under the strict safe search.
+type WikimediaCommons struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+type wikimediaCommonsResponse struct {
+	Query struct {
+		Pages map[string]struct {
+			Index     int `json:"index"`
+			ImageInfo []struct {
+				URL  string `json:"url"`
+				Mime string `json:"mime"`
+			} `json:"imageinfo"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+func NewWikimediaCommons(httpClient *http.Client, userAgent string) *WikimediaCommons {
+	return &WikimediaCommons{httpClient: httpClient, userAgent: userAgent}
+}
+
+func (wc WikimediaCommons) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	wordImages := make([]*WordImagesDTO, len(words))
+	headers := map[string]string{"User-Agent": wc.userAgent}
+
+	for i := range words {
+		query := url.Values{
+			"action":       {"query"},
+			"format":       {"json"},
+			"generator":    {"search"},
+			"gsrsearch":    {words[i]},
+			"gsrnamespace": {wikimediaFileNamespace},
+			"gsrlimit":     {strconv.Itoa(resultsPerQuery)},
+			"prop":         {"imageinfo"},
+			"iiprop":       {"url|mime"},
+		}
+
+		resp := new(wikimediaCommonsResponse)
+		if err := apiCall(ctx, wc.httpClient, wikimediaCommonsAPIURL+"?"+query.Encode(), headers, resp); err != nil {
+			return nil, fmt.Errorf("wikimedia commons call error for word `%s`: %w", words[i], err)
+		}
+
+		// Pages come as a map, the index keeps the search relevance order.
+		pages := make([]int, 0, len(resp.Query.Pages))
+		pageIDs := make(map[int]string, len(resp.Query.Pages))
+
+		for pageID, page := range resp.Query.Pages {
+			pages = append(pages, page.Index)
+			pageIDs[page.Index] = pageID
+		}
+
+		sort.Ints(pages)
+
+		links := make([]string, 0, len(pages))
+
+		for _, index := range pages {
+			for _, info := range resp.Query.Pages[pageIDs[index]].ImageInfo {
+				if info.Mime == "image/png" || info.Mime == "image/jpeg" {
+					links = append(links, info.URL)
+				}
+			}
+		}
+
+		wordImages[i] = NewWordImagesDTO(words[i], links)
+	}
+
+	return wordImages, nil
+}
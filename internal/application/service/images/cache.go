@@ -0,0 +1,107 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const cacheKeyPrefix = "img:"
+
+// RedisCache caches search results per query, so repeated uploads of the same words don't spend the search quota.
+// Results are cached per safe search level, so changing it doesn't return results filtered with another level.
+type RedisCache struct {
+	images     Interface
+	client     *redis.Client
+	ttl        time.Duration
+	safeSearch SafeSearch
+}
+
+func NewRedisCache(images Interface, client *redis.Client, ttl time.Duration, safeSearch SafeSearch) *RedisCache {
+	return &RedisCache{images: images, client: client, ttl: ttl, safeSearch: safeSearch}
+}
+
+func (rc RedisCache) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	wordImages := make([]*WordImagesDTO, len(words))
+	missedWords := make([]string, 0)
+	missedIndexes := make([]int, 0)
+
+	for i := range words {
+		urls, found, err := rc.cached(ctx, words[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			missedWords = append(missedWords, words[i])
+			missedIndexes = append(missedIndexes, i)
+
+			continue
+		}
+
+		wordImages[i] = NewWordImagesDTO(words[i], urls)
+	}
+
+	if len(missedWords) == 0 {
+		return wordImages, nil
+	}
+
+	searchedImages, err := rc.images.Search(ctx, missedWords)
+	if err != nil {
+		return nil, fmt.Errorf("searching images for cache misses error: %w", err)
+	}
+
+	for i, searched := range searchedImages {
+		if err = rc.store(ctx, searched); err != nil {
+			return nil, err
+		}
+
+		wordImages[missedIndexes[i]] = searched
+	}
+
+	return wordImages, nil
+}
+
+func (rc RedisCache) cached(ctx context.Context, word string) ([]string, bool, error) {
+	value, err := rc.client.Get(ctx, rc.cacheKey(word)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("redis Get operation error: %w", err)
+	}
+
+	var urls []string
+	if err = json.Unmarshal(value, &urls); err != nil {
+		return nil, false, fmt.Errorf("cached images decoding error: %w", err)
+	}
+
+	return urls, true, nil
+}
+
+func (rc RedisCache) store(ctx context.Context, wordImages *WordImagesDTO) error {
+	urls := wordImages.Urls()
+	if urls == nil {
+		urls = []string{}
+	}
+
+	value, err := json.Marshal(urls)
+	if err != nil {
+		return fmt.Errorf("images encoding error: %w", err)
+	}
+
+	if err = rc.client.Set(ctx, rc.cacheKey(wordImages.Word()), value, rc.ttl).Err(); err != nil {
+		return fmt.Errorf("redis Set operation error: %w", err)
+	}
+
+	return nil
+}
+
+func (rc RedisCache) cacheKey(word string) string {
+	return fmt.Sprintf("%s%s:%s", cacheKeyPrefix, rc.safeSearch, word)
+}
@@ -0,0 +1,177 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+const testCacheTTL = time.Hour
+
+var errProvider = errors.New("provider error")
+
+// fakeProvider finds the images it knows and none for other words, the words it's asked for are recorded.
+type fakeProvider struct {
+	urls    map[string][]string
+	err     error
+	queried [][]string
+}
+
+func (f *fakeProvider) Search(_ context.Context, words []string) ([]*WordImagesDTO, error) {
+	f.queried = append(f.queried, words)
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	wordImages := make([]*WordImagesDTO, len(words))
+	for i, word := range words {
+		wordImages[i] = NewWordImagesDTO(word, f.urls[word])
+	}
+
+	return wordImages, nil
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()}) //nolint:exhaustruct
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	return server, client
+}
+
+func imagesOf(wordImages []*WordImagesDTO) map[string][]string {
+	images := make(map[string][]string, len(wordImages))
+	for _, wordImage := range wordImages {
+		images[wordImage.Word()] = wordImage.Urls()
+	}
+
+	return images
+}
+
+func TestRedisCacheSearchesOnlyMisses(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestRedis(t)
+	provider := &fakeProvider{
+		urls:    map[string][]string{"cat": {"https://img.test/cat.jpg"}, "owl": {"https://img.test/owl.jpg"}},
+		err:     nil,
+		queried: nil,
+	}
+	cache := NewRedisCache(provider, client, testCacheTTL, SafeSearchStrict)
+
+	if _, err := cache.Search(context.Background(), []string{"cat", "dog"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wordImages, err := cache.Search(context.Background(), []string{"dog", "owl", "cat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Words without images are cached as well, so they aren't searched on every lookup.
+	if want := [][]string{{"cat", "dog"}, {"owl"}}; !reflect.DeepEqual(provider.queried, want) {
+		t.Errorf("got the provider queried for %q, want %q", provider.queried, want)
+	}
+
+	words := make([]string, len(wordImages))
+	for i := range wordImages {
+		words[i] = wordImages[i].Word()
+	}
+
+	if want := []string{"dog", "owl", "cat"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("got words %q, want them in the asked order", words)
+	}
+
+	want := map[string][]string{"dog": {}, "owl": {"https://img.test/owl.jpg"}, "cat": {"https://img.test/cat.jpg"}}
+	if got := imagesOf(wordImages); !reflect.DeepEqual(got, want) {
+		t.Errorf("got images %q, want %q", got, want)
+	}
+}
+
+func TestRedisCacheExpires(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestRedis(t)
+	provider := &fakeProvider{urls: map[string][]string{"cat": {"https://img.test/cat.jpg"}}, err: nil, queried: nil}
+	cache := NewRedisCache(provider, client, testCacheTTL, SafeSearchStrict)
+
+	if _, err := cache.Search(context.Background(), []string{"cat"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ttl := server.TTL("img:strict:cat"); ttl != testCacheTTL {
+		t.Errorf("got TTL %s, want %s", ttl, testCacheTTL)
+	}
+
+	for _, elapsed := range []time.Duration{testCacheTTL - time.Second, time.Second} {
+		server.FastForward(elapsed)
+
+		if _, err := cache.Search(context.Background(), []string{"cat"}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if want := [][]string{{"cat"}, {"cat"}}; !reflect.DeepEqual(provider.queried, want) {
+		t.Errorf("got the provider queried for %q, want it queried again only once the TTL is over", provider.queried)
+	}
+}
+
+func TestRedisCacheSeparatesSafeSearchLevels(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestRedis(t)
+	strictProvider := &fakeProvider{urls: nil, err: nil, queried: nil}
+	offProvider := &fakeProvider{urls: map[string][]string{"cat": {"https://img.test/cat.jpg"}}, err: nil, queried: nil}
+
+	if _, err := NewRedisCache(strictProvider, client, testCacheTTL, SafeSearchStrict).Search(
+		context.Background(), []string{"cat"},
+	); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wordImages, err := NewRedisCache(offProvider, client, testCacheTTL, SafeSearchOff).Search(
+		context.Background(), []string{"cat"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(offProvider.queried) != 1 || len(wordImages[0].Urls()) != 1 {
+		t.Errorf("got images %q, want the results of the strict safe search not taken", wordImages[0].Urls())
+	}
+}
+
+func TestRedisCacheFails(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestRedis(t)
+
+	failing := NewRedisCache(&fakeProvider{urls: nil, err: errProvider, queried: nil}, client, testCacheTTL, SafeSearchOff)
+	if _, err := failing.Search(context.Background(), []string{"cat"}); !errors.Is(err, errProvider) {
+		t.Errorf("got error %v, want the provider error", err)
+	}
+
+	if err := server.Set("img:off:dog", "not JSON"); err != nil {
+		t.Fatal(err)
+	}
+
+	working := NewRedisCache(&fakeProvider{urls: nil, err: nil, queried: nil}, client, testCacheTTL, SafeSearchOff)
+	if _, err := working.Search(context.Background(), []string{"dog"}); err == nil {
+		t.Error("got no error for a malformed cached value, want one")
+	}
+
+	server.Close()
+
+	if _, err := working.Search(context.Background(), []string{"cat"}); err == nil {
+		t.Error("got no error without Redis, want one")
+	}
+}
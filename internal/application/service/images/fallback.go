@@ -0,0 +1,58 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+var errAllProvidersFailed = errors.New("all images providers failed")
+
+// Fallback searches with the first provider and moves to the next one when a provider fails,
+// e.g. because its quota ran out. In the round-robin mode every search starts with the next provider
+// in turn, spreading the calls over all of them.
+type Fallback struct {
+	providers  []Interface
+	roundRobin bool
+	calls      *uint64
+}
+
+func NewFallback(roundRobin bool, providers ...Interface) *Fallback {
+	return &Fallback{providers: providers, roundRobin: roundRobin, calls: new(uint64)}
+}
+
+func (f Fallback) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	if len(f.providers) == 0 {
+		return nil, errAllProvidersFailed
+	}
+
+	start := 0
+	if f.roundRobin {
+		start = int((atomic.AddUint64(f.calls, 1) - 1) % uint64(len(f.providers)))
+	}
+
+	var quotaExceeded bool
+
+	for i := range f.providers {
+		provider := f.providers[(start+i)%len(f.providers)]
+
+		wordImages, err := provider.Search(ctx, words)
+		if err == nil {
+			return wordImages, nil
+		}
+
+		log.Printf("images provider %T error, falling back to the next one: %s", provider, err)
+
+		if errors.Is(err, ErrQuotaExceeded) {
+			quotaExceeded = true
+		}
+	}
+
+	if quotaExceeded {
+		return nil, fmt.Errorf("%w: %s, at least one ran out of quota", ErrQuotaExceeded, errAllProvidersFailed)
+	}
+
+	return nil, errAllProvidersFailed
+}
@@ -0,0 +1,147 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// namedProvider finds an image named after the provider, the providers it's called by are recorded in order.
+type namedProvider struct {
+	name  string
+	err   error
+	calls *[]string
+}
+
+func (n namedProvider) Search(_ context.Context, words []string) ([]*WordImagesDTO, error) {
+	*n.calls = append(*n.calls, n.name)
+
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	wordImages := make([]*WordImagesDTO, len(words))
+	for i, word := range words {
+		wordImages[i] = NewWordImagesDTO(word, []string{"https://" + n.name + ".test/" + word + ".jpg"})
+	}
+
+	return wordImages, nil
+}
+
+func newNamedProviders(calls *[]string, errs map[string]error, names ...string) []Interface {
+	providers := make([]Interface, len(names))
+	for i, name := range names {
+		providers[i] = namedProvider{name: name, err: errs[name], calls: calls}
+	}
+
+	return providers
+}
+
+func TestFallbackOrder(t *testing.T) {
+	t.Parallel()
+
+	quotaErr := fmt.Errorf("%w: provider responded with 429", ErrQuotaExceeded)
+
+	for name, testCase := range map[string]struct {
+		roundRobin bool
+		errs       map[string]error
+		wantCalls  []string
+		wantFound  []string
+	}{
+		"first provider first": {
+			roundRobin: false,
+			errs:       nil,
+			wantCalls:  []string{"google", "google", "google"},
+			wantFound:  []string{"google", "google", "google"},
+		},
+		"falls back in order": {
+			roundRobin: false,
+			errs:       map[string]error{"google": quotaErr, "pixabay": errProvider},
+			wantCalls: []string{
+				"google", "pixabay", "unsplash", "google", "pixabay", "unsplash", "google", "pixabay", "unsplash",
+			},
+			wantFound: []string{"unsplash", "unsplash", "unsplash"},
+		},
+		"round robin": {
+			roundRobin: true,
+			errs:       nil,
+			wantCalls:  []string{"google", "pixabay", "unsplash"},
+			wantFound:  []string{"google", "pixabay", "unsplash"},
+		},
+		"round robin falls back to the next in turn": {
+			roundRobin: true,
+			errs:       map[string]error{"pixabay": quotaErr},
+			wantCalls:  []string{"google", "pixabay", "unsplash", "unsplash"},
+			wantFound:  []string{"google", "unsplash", "unsplash"},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := make([]string, 0)
+			fallback := NewFallback(
+				testCase.roundRobin, newNamedProviders(&calls, testCase.errs, "google", "pixabay", "unsplash")...,
+			)
+
+			found := make([]string, 0, len(testCase.wantFound))
+
+			for range testCase.wantFound {
+				wordImages, err := fallback.Search(context.Background(), []string{"cat"})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				imageURL := wordImages[0].Urls()[0]
+				found = append(found, imageURL[len("https://"):len(imageURL)-len(".test/cat.jpg")])
+			}
+
+			if !reflect.DeepEqual(calls, testCase.wantCalls) {
+				t.Errorf("got calls %q, want %q", calls, testCase.wantCalls)
+			}
+
+			if !reflect.DeepEqual(found, testCase.wantFound) {
+				t.Errorf("got images found by %q, want %q", found, testCase.wantFound)
+			}
+		})
+	}
+}
+
+func TestFallbackFails(t *testing.T) {
+	t.Parallel()
+
+	quotaErr := fmt.Errorf("%w: provider responded with 429", ErrQuotaExceeded)
+
+	for name, testCase := range map[string]struct {
+		errs      map[string]error
+		names     []string
+		wantQuota bool
+	}{
+		"no providers":         {nil, nil, false},
+		"all fail":             {map[string]error{"a": errProvider, "b": errProvider}, []string{"a", "b"}, false},
+		"one is out of quota":  {map[string]error{"a": errProvider, "b": quotaErr}, []string{"a", "b"}, true},
+		"all are out of quota": {map[string]error{"a": quotaErr, "b": quotaErr}, []string{"a", "b"}, true},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := make([]string, 0)
+
+			_, err := NewFallback(true, newNamedProviders(&calls, testCase.errs, testCase.names...)...).Search(
+				context.Background(), []string{"cat"},
+			)
+			if err == nil || errors.Is(err, ErrQuotaExceeded) != testCase.wantQuota {
+				t.Errorf("got error %v, want all the providers failed, out of quota: %t", err, testCase.wantQuota)
+			}
+
+			if len(calls) != len(testCase.names) {
+				t.Errorf("got calls %q, want every provider tried once", calls)
+			}
+		})
+	}
+}
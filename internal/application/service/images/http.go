@@ -0,0 +1,60 @@
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+const resultsPerQuery = 10
+
+var errUnexpectedStatus = errors.New("unexpected response status")
+
+// apiCall decodes a JSON response into the dto, rate limiting statuses are reported as ErrQuotaExceeded,
+// so composites can move on to another provider.
+func apiCall(
+	ctx context.Context,
+	httpClient *http.Client,
+	callURL string,
+	headers map[string]string,
+	dto interface{},
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, callURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("creation request error: %w", err)
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request execution error: %w", err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	// Some providers, e.g. Unsplash, respond with 403 once the rate limit is exhausted.
+	rateLimitExhausted := resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-Ratelimit-Remaining") == "0"
+	if resp.StatusCode == http.StatusTooManyRequests || rateLimitExhausted {
+		return fmt.Errorf("%w: provider responded with %d", ErrQuotaExceeded, resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(dto); err != nil {
+		return fmt.Errorf("response decoding error: %w", err)
+	}
+
+	return nil
+}
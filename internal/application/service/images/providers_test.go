@@ -0,0 +1,196 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newProviderStub answers every request with the status, the headers and the body, the requests are recorded.
+func newProviderStub(status int, header http.Header, body string) (*http.Client, *[]*http.Request) {
+	requests := make([]*http.Request, 0)
+
+	return &http.Client{ //nolint:exhaustruct
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req)
+
+			return &http.Response{ //nolint:exhaustruct
+				StatusCode: status,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+				Request:    req,
+			}, nil
+		}),
+	}, &requests
+}
+
+func TestProvidersSearch(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		newProvider func(httpClient *http.Client) Interface
+		response    string
+		wantURL     string
+		wantQuery   map[string]string
+		wantHeaders map[string]string
+		wantImages  []string
+	}{
+		"pixabay": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewPixabay(httpClient, "pixabay-key", SafeSearchModerate)
+			},
+			response:    `{"hits":[{"webformatURL":"https://img.test/1.jpg"},{"webformatURL":"https://img.test/2.jpg"}]}`,
+			wantURL:     "https://pixabay.com/api/",
+			wantQuery:   map[string]string{"key": "pixabay-key", "q": "black cat", "safesearch": "true", "per_page": "10"},
+			wantHeaders: nil,
+			wantImages:  []string{"https://img.test/1.jpg", "https://img.test/2.jpg"},
+		},
+		"pixabay without safe search": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewPixabay(httpClient, "pixabay-key", SafeSearchOff)
+			},
+			response:    `{"hits":[]}`,
+			wantURL:     "https://pixabay.com/api/",
+			wantQuery:   map[string]string{"safesearch": "false"},
+			wantHeaders: nil,
+			wantImages:  []string{},
+		},
+		"unsplash": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewUnsplash(httpClient, "unsplash-key", SafeSearchStrict)
+			},
+			response:    `{"results":[{"urls":{"regular":"https://img.test/1.jpg"}}]}`,
+			wantURL:     "https://api.unsplash.com/search/photos",
+			wantQuery:   map[string]string{"query": "black cat", "content_filter": "high"},
+			wantHeaders: map[string]string{"Authorization": "Client-ID unsplash-key", "Accept-Version": "v1"},
+			wantImages:  []string{"https://img.test/1.jpg"},
+		},
+		"unsplash with moderate safe search": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewUnsplash(httpClient, "unsplash-key", SafeSearchModerate)
+			},
+			response:    `{"results":[]}`,
+			wantURL:     "https://api.unsplash.com/search/photos",
+			wantQuery:   map[string]string{"content_filter": "low"},
+			wantHeaders: nil,
+			wantImages:  []string{},
+		},
+		"openverse": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewOpenverse(httpClient, "openverse-token", "vocaboost-test", SafeSearchOff)
+			},
+			response: `{"results":[{"url":"https://img.test/1.jpg","filetype":"jpg"},` +
+				`{"url":"https://img.test/2.gif","filetype":"gif"},{"url":"https://img.test/3","filetype":""}]}`,
+			wantURL:     "https://api.openverse.org/v1/images/",
+			wantQuery:   map[string]string{"q": "black cat", "mature": "true", "extension": "jpg,jpeg,png"},
+			wantHeaders: map[string]string{"Authorization": "Bearer openverse-token", "User-Agent": "vocaboost-test"},
+			wantImages:  []string{"https://img.test/1.jpg", "https://img.test/3"},
+		},
+		"anonymous openverse": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewOpenverse(httpClient, "", "vocaboost-test", SafeSearchModerate)
+			},
+			response:    `{"results":[]}`,
+			wantURL:     "https://api.openverse.org/v1/images/",
+			wantQuery:   map[string]string{"mature": "false"},
+			wantHeaders: map[string]string{"Authorization": ""},
+			wantImages:  []string{},
+		},
+		"wikimedia commons": {
+			newProvider: func(httpClient *http.Client) Interface {
+				return NewWikimediaCommons(httpClient, "vocaboost-test")
+			},
+			response: `{"query":{"pages":{` +
+				`"7":{"index":2,"imageinfo":[{"url":"https://img.test/2.png","mime":"image/png"}]},` +
+				`"3":{"index":3,"imageinfo":[{"url":"https://img.test/3.svg","mime":"image/svg+xml"}]},` +
+				`"9":{"index":1,"imageinfo":[{"url":"https://img.test/1.jpg","mime":"image/jpeg"}]}}}}`,
+			wantURL:     "https://commons.wikimedia.org/w/api.php",
+			wantQuery:   map[string]string{"gsrsearch": "black cat", "gsrnamespace": "6", "generator": "search"},
+			wantHeaders: map[string]string{"User-Agent": "vocaboost-test"},
+			wantImages:  []string{"https://img.test/1.jpg", "https://img.test/2.png"},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			httpClient, requests := newProviderStub(http.StatusOK, nil, testCase.response)
+
+			wordImages, err := testCase.newProvider(httpClient).Search(context.Background(), []string{"black cat"})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if wordImages[0].Word() != "black cat" || !reflect.DeepEqual(wordImages[0].Urls(), testCase.wantImages) {
+				t.Errorf("got images %q of %q, want %q", wordImages[0].Urls(), wordImages[0].Word(), testCase.wantImages)
+			}
+
+			req := (*requests)[0]
+			if calledURL := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path; calledURL != testCase.wantURL {
+				t.Errorf("got URL %s called, want %s", calledURL, testCase.wantURL)
+			}
+
+			for param, want := range testCase.wantQuery {
+				if got := req.URL.Query().Get(param); got != want {
+					t.Errorf("got param `%s` %q, want %q", param, got, want)
+				}
+			}
+
+			for header, want := range testCase.wantHeaders {
+				if got := req.Header.Get(header); got != want {
+					t.Errorf("got header `%s` %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProvidersFail(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		status    int
+		header    http.Header
+		body      string
+		wantQuota bool
+	}{
+		"rate limited":           {http.StatusTooManyRequests, nil, "", true},
+		"rate limit exhausted":   {http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": {"0"}}, "", true},
+		"forbidden":              {http.StatusForbidden, http.Header{"X-Ratelimit-Remaining": {"12"}}, "", false},
+		"server error":           {http.StatusInternalServerError, nil, "", false},
+		"malformed response":     {http.StatusOK, nil, "{", false},
+		"unexpected result type": {http.StatusOK, nil, `{"hits":"none","results":"none"}`, false},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, newProvider := range []func(*http.Client) Interface{
+				func(httpClient *http.Client) Interface { return NewPixabay(httpClient, "", SafeSearchStrict) },
+				func(httpClient *http.Client) Interface { return NewUnsplash(httpClient, "", SafeSearchStrict) },
+				func(httpClient *http.Client) Interface { return NewOpenverse(httpClient, "", "", SafeSearchStrict) },
+			} {
+				httpClient, _ := newProviderStub(testCase.status, testCase.header, testCase.body)
+
+				provider := newProvider(httpClient)
+
+				_, err := provider.Search(context.Background(), []string{"cat"})
+				if err == nil || errors.Is(err, ErrQuotaExceeded) != testCase.wantQuota {
+					t.Errorf("%T: got error %v, want one, out of quota: %t", provider, err, testCase.wantQuota)
+				}
+			}
+		})
+	}
+}
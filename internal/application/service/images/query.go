@@ -0,0 +1,46 @@
+package images
+
+import (
+	"strings"
+)
+
+const (
+	QueryPlaceholderWord         = "{word}"
+	QueryPlaceholderPartOfSpeech = "{partOfSpeech}"
+	QueryPlaceholderDefinition   = "{definition}"
+)
+
+// QueryTemplate builds image search queries of words, e.g. "{word} illustration", "{word} {partOfSpeech}"
+// or "{definition}", the quota is how many of its images a card gets.
+type QueryTemplate struct {
+	template string
+	quota    int
+}
+
+func NewQueryTemplate(template string, quota int) *QueryTemplate {
+	return &QueryTemplate{template: template, quota: quota}
+}
+
+func (qt QueryTemplate) Template() string {
+	return qt.template
+}
+
+func (qt QueryTemplate) Quota() int {
+	return qt.quota
+}
+
+// Query fills the placeholders in, the word itself is the query if nothing is left, e.g. a word has no definitions.
+func (qt QueryTemplate) Query(word, partOfSpeech, definition string) string {
+	query := strings.NewReplacer(
+		QueryPlaceholderWord, word,
+		QueryPlaceholderPartOfSpeech, partOfSpeech,
+		QueryPlaceholderDefinition, definition,
+	).Replace(qt.template)
+
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return word
+	}
+
+	return query
+}
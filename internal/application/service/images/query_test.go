@@ -0,0 +1,36 @@
+package images_test
+
+import (
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/images"
+)
+
+func TestQueryTemplateQuery(t *testing.T) {
+	t.Parallel()
+
+	for template, want := range map[string]string{
+		"{word}":                        "cat",
+		"{word} illustration":           "cat illustration",
+		"{word} {partOfSpeech}":         "cat noun",
+		"{definition}":                  "a small animal",
+		"  {word}   {unknown} drawing ": "cat {unknown} drawing",
+		"{partOfSpeech}{definition}":    "nouna small animal",
+	} {
+		if got := images.NewQueryTemplate(template, 1).Query("cat", "noun", "a small animal"); got != want {
+			t.Errorf("got query %q of template %q, want %q", got, template, want)
+		}
+	}
+
+	// A word without definitions falls back to the word itself.
+	for template, want := range map[string]string{
+		"{definition}":                "purr",
+		"{partOfSpeech} {definition}": "purr",
+		"{word} {partOfSpeech}":       "purr",
+		"":                            "purr",
+	} {
+		if got := images.NewQueryTemplate(template, 1).Query("purr", "", ""); got != want {
+			t.Errorf("got query %q of template %q without definitions, want %q", got, template, want)
+		}
+	}
+}
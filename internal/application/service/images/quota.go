@@ -0,0 +1,100 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	quotaKeyPrefix = "quota:"
+	quotaKeyTTL    = 48 * time.Hour
+	quotaDayLayout = "2006-01-02"
+)
+
+// ErrQuotaExceeded is returned before calling the provider once the daily calls would exceed the limit.
+var ErrQuotaExceeded = errors.New("daily images search quota is exceeded")
+
+// Quota counts provider calls per day, one call per searched word, and refuses to search once
+// the calls would come closer to the daily limit than the reserve.
+type Quota struct {
+	images   Interface
+	client   *redis.Client
+	name     string
+	limit    int64
+	reserve  int64
+	location *time.Location
+	// now tells the day the calls are counted for.
+	now func() time.Time
+}
+
+// NewQuota creates the tracker, the name separates counters of different providers and the location
+// sets when the provider's day starts, e.g. Google resets quotas at midnight Pacific Time.
+func NewQuota(images Interface, client *redis.Client, name string, limit, reserve int64, location *time.Location) *Quota {
+	return &Quota{
+		images:   images,
+		client:   client,
+		name:     name,
+		limit:    limit,
+		reserve:  reserve,
+		location: location,
+		now:      time.Now,
+	}
+}
+
+func (q Quota) Search(ctx context.Context, words []string) ([]*WordImagesDTO, error) {
+	if len(words) == 0 {
+		return q.images.Search(ctx, words)
+	}
+
+	key := q.key(q.now())
+	calls := int64(len(words))
+
+	used, err := q.client.IncrBy(ctx, key, calls).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis IncrBy operation error: %w", err)
+	}
+
+	if err = q.client.Expire(ctx, key, quotaKeyTTL).Err(); err != nil {
+		return nil, fmt.Errorf("redis Expire operation error: %w", err)
+	}
+
+	if used > q.limit-q.reserve {
+		if err = q.client.DecrBy(ctx, key, calls).Err(); err != nil {
+			return nil, fmt.Errorf("redis DecrBy operation error: %w", err)
+		}
+
+		return nil, fmt.Errorf(
+			"%w for `%s`: %d calls used, %d requested, daily limit is %d with %d kept in reserve",
+			ErrQuotaExceeded, q.name, used-calls, calls, q.limit, q.reserve,
+		)
+	}
+
+	wordImages, err := q.images.Search(ctx, words)
+	if err != nil {
+		return nil, fmt.Errorf("quota tracked search error: %w", err)
+	}
+
+	return wordImages, nil
+}
+
+// Used returns the calls counted today.
+func (q Quota) Used(ctx context.Context) (int64, error) {
+	used, err := q.client.Get(ctx, q.key(q.now())).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("redis Get operation error: %w", err)
+	}
+
+	return used, nil
+}
+
+func (q Quota) key(now time.Time) string {
+	return fmt.Sprintf("%s%s:%s", quotaKeyPrefix, q.name, now.In(q.location).Format(quotaDayLayout))
+}
@@ -0,0 +1,124 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setQuotaClock sets the clock of the quota to the time, the returned function moves the clock.
+func setQuotaClock(quota *Quota, now time.Time) func(time.Duration) {
+	quota.now = func() time.Time { return now }
+
+	return func(elapsed time.Duration) {
+		now = now.Add(elapsed)
+	}
+}
+
+func TestQuotaRefusesBeyondLimit(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestRedis(t)
+	provider := &fakeProvider{urls: nil, err: nil, queried: nil}
+	quota := NewQuota(provider, client, "google", 6, 1, time.UTC)
+	setQuotaClock(quota, time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC))
+
+	for _, words := range [][]string{{"cat", "dog", "owl"}, {}, {"fox"}} {
+		if _, err := quota.Search(context.Background(), words); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// Two more calls would leave less than the reserve, the refused calls aren't counted.
+	if _, err := quota.Search(context.Background(), []string{"cow", "pig"}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("got error %v, want %v", err, ErrQuotaExceeded)
+	}
+
+	if _, err := quota.Search(context.Background(), []string{"cow"}); err != nil {
+		t.Errorf("got error %v, want the last call before the reserve allowed", err)
+	}
+
+	if want := [][]string{{"cat", "dog", "owl"}, {}, {"fox"}, {"cow"}}; !reflect.DeepEqual(provider.queried, want) {
+		t.Errorf("got the provider queried for %q, want %q", provider.queried, want)
+	}
+
+	if used, err := quota.Used(context.Background()); err != nil || used != 5 {
+		t.Errorf("got %d calls used and error %v, want 5", used, err)
+	}
+}
+
+func TestQuotaRollsOverAtProviderMidnight(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestRedis(t)
+	pacific := time.FixedZone("PDT", -7*60*60)
+	quota := NewQuota(&fakeProvider{urls: nil, err: nil, queried: nil}, client, "google", 2, 0, pacific)
+	// It's past midnight in UTC already, the provider's day goes on.
+	moveClock := setQuotaClock(quota, time.Date(2026, time.October, 18, 22, 59, 0, 0, pacific))
+
+	for _, elapsed := range []time.Duration{time.Hour, 0} {
+		if _, err := quota.Search(context.Background(), []string{"cat"}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		moveClock(elapsed)
+	}
+
+	if _, err := quota.Search(context.Background(), []string{"cat"}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("got error %v before midnight, want %v", err, ErrQuotaExceeded)
+	}
+
+	moveClock(time.Minute)
+
+	if used, err := quota.Used(context.Background()); err != nil || used != 0 {
+		t.Errorf("got %d calls used and error %v after midnight, want none", used, err)
+	}
+
+	if _, err := quota.Search(context.Background(), []string{"cat"}); err != nil {
+		t.Errorf("got error %v after midnight, want the calls allowed again", err)
+	}
+
+	if ttl := server.TTL("quota:google:2026-10-19"); ttl != quotaKeyTTL {
+		t.Errorf("got the counter TTL %s, want %s", ttl, quotaKeyTTL)
+	}
+}
+
+func TestQuotaCountsProvidersSeparately(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestRedis(t)
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	google := NewQuota(&fakeProvider{urls: nil, err: nil, queried: nil}, client, "google", 1, 0, time.UTC)
+	pixabay := NewQuota(&fakeProvider{urls: nil, err: nil, queried: nil}, client, "pixabay", 1, 0, time.UTC)
+	setQuotaClock(google, now)
+	setQuotaClock(pixabay, now)
+
+	for _, quota := range []*Quota{google, pixabay} {
+		if _, err := quota.Search(context.Background(), []string{"cat"}); err != nil {
+			t.Errorf("got error %v for `%s`, want its own counter", err, quota.name)
+		}
+	}
+}
+
+func TestQuotaFails(t *testing.T) {
+	t.Parallel()
+
+	server, client := newTestRedis(t)
+	quota := NewQuota(&fakeProvider{urls: nil, err: errProvider, queried: nil}, client, "google", 10, 0, time.UTC)
+
+	if _, err := quota.Search(context.Background(), []string{"cat"}); !errors.Is(err, errProvider) {
+		t.Errorf("got error %v, want the provider error", err)
+	}
+
+	server.Close()
+
+	if _, err := quota.Search(context.Background(), []string{"cat"}); err == nil {
+		t.Error("got no error without Redis, want one")
+	}
+
+	if _, err := quota.Used(context.Background()); err == nil {
+		t.Error("got no error for the used calls without Redis, want one")
+	}
+}
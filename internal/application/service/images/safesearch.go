@@ -0,0 +1,26 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SafeSearch is the level of explicit content filtering, providers map it to their own settings.
+type SafeSearch string
+
+const (
+	SafeSearchOff      SafeSearch = "off"
+	SafeSearchModerate SafeSearch = "moderate"
+	SafeSearchStrict   SafeSearch = "strict"
+)
+
+var errUnknownSafeSearch = errors.New("unknown safe search level")
+
+func ParseSafeSearch(level string) (SafeSearch, error) {
+	switch safeSearch := SafeSearch(level); safeSearch {
+	case SafeSearchOff, SafeSearchModerate, SafeSearchStrict:
+		return safeSearch, nil
+	default:
+		return "", fmt.Errorf("%w: `%s`", errUnknownSafeSearch, level)
+	}
+}
@@ -0,0 +1,38 @@
+package imagestorage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	localDiskDirPerm  = 0o755
+	localDiskFilePerm = 0o644
+)
+
+// LocalDisk stores files in a directory which is expected to be served from the base URL.
+type LocalDisk struct {
+	dir,
+	baseURL string
+}
+
+func NewLocalDisk(dir, baseURL string) (*LocalDisk, error) {
+	if err := os.MkdirAll(dir, localDiskDirPerm); err != nil {
+		return nil, fmt.Errorf("creating images storage directory error: %w", err)
+	}
+
+	return &LocalDisk{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+func (ld LocalDisk) Put(_ context.Context, key, _ string, content []byte) (string, string, error) {
+	filePath := filepath.Join(ld.dir, filepath.Base(key))
+
+	if err := os.WriteFile(filePath, content, localDiskFilePerm); err != nil {
+		return "", "", fmt.Errorf("writing file `%s` error: %w", filePath, err)
+	}
+
+	return ld.baseURL + "/" + filepath.Base(key), filePath, nil
+}
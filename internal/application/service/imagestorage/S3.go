@@ -0,0 +1,38 @@
+package imagestorage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// S3 stores files in a bucket of any S3-compatible storage, e.g. MinIO. The bucket is expected
+// to be readable from the public base URL, e.g. `http://localhost:9000/vocaboost`.
+type S3 struct {
+	client        *minio.Client
+	bucket        string
+	publicBaseURL string
+}
+
+func NewS3(client *minio.Client, bucket, publicBaseURL string) *S3 {
+	return &S3{client: client, bucket: bucket, publicBaseURL: strings.TrimRight(publicBaseURL, "/")}
+}
+
+func (s S3) Put(ctx context.Context, key, contentType string, content []byte) (string, string, error) {
+	_, err := s.client.PutObject(
+		ctx,
+		s.bucket,
+		key,
+		bytes.NewReader(content),
+		int64(len(content)),
+		minio.PutObjectOptions{ContentType: contentType}, //nolint:exhaustruct
+	)
+	if err != nil {
+		return "", "", fmt.Errorf("putting object `%s` to bucket `%s` error: %w", key, s.bucket, err)
+	}
+
+	return s.publicBaseURL + "/" + key, "", nil
+}
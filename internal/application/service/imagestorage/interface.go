@@ -0,0 +1,37 @@
+package imagestorage
+
+import "context"
+
+type StoredImageDTO struct {
+	sourceURL,
+	url,
+	filePath string
+}
+
+func (s StoredImageDTO) SourceURL() string {
+	return s.sourceURL
+}
+
+// URL is the stable URL the stored image is served from.
+func (s StoredImageDTO) URL() string {
+	return s.url
+}
+
+// FilePath is the path of the stored file if it's stored locally, otherwise it's empty.
+func (s StoredImageDTO) FilePath() string {
+	return s.filePath
+}
+
+func NewStoredImageDTO(sourceURL, url, filePath string) *StoredImageDTO {
+	return &StoredImageDTO{sourceURL: sourceURL, url: url, filePath: filePath}
+}
+
+type Interface interface {
+	// Store keeps the order of the images, images which can't be stored are skipped.
+	Store(ctx context.Context, imageURLs []string) ([]*StoredImageDTO, error)
+}
+
+// Storage is a place the prepared image files are put to.
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, content []byte) (url, filePath string, err error)
+}
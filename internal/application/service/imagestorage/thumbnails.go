@@ -0,0 +1,161 @@
+package imagestorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/application/service/imagefetch"
+	"golang.org/x/image/draw"
+)
+
+const (
+	thumbnailContentType = "image/jpeg"
+	thumbnailExtension   = ".jpg"
+	thumbnailQuality     = 85
+)
+
+var (
+	errNotAnImage    = errors.New("the content isn't an image")
+	errImageTooLarge = errors.New("the image is too large")
+	errEmptyImage    = errors.New("the image has no pixels")
+)
+
+// Thumbnails downloads images, checks they really are images, scales them down to fit
+// the maximum size and puts them to the storage as JPEG files named after the source URL hash.
+type Thumbnails struct {
+	storage Storage
+	fetcher imagefetch.Interface
+	maxSide int
+	// maxPixels limits the decoded size, a small file may declare huge dimensions and decode into gigabytes.
+	maxPixels int
+}
+
+func NewThumbnails(storage Storage, fetcher imagefetch.Interface, maxSide, maxPixels int) *Thumbnails {
+	return &Thumbnails{storage: storage, fetcher: fetcher, maxSide: maxSide, maxPixels: maxPixels}
+}
+
+func (t Thumbnails) Store(ctx context.Context, imageURLs []string) ([]*StoredImageDTO, error) {
+	stored := make([]*StoredImageDTO, 0, len(imageURLs))
+
+	for _, imageURL := range imageURLs {
+		storedImage, err := t.store(ctx, imageURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("storing images interrupted: %w", ctx.Err())
+			}
+
+			log.Printf("storing image `%s` error, skipping it: %s", imageURL, err)
+
+			continue
+		}
+
+		stored = append(stored, storedImage)
+	}
+
+	return stored, nil
+}
+
+func (t Thumbnails) store(ctx context.Context, imageURL string) (*StoredImageDTO, error) {
+	content, err := t.download(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnail, err := t.thumbnail(content)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(imageURL))
+
+	url, filePath, err := t.storage.Put(ctx, hex.EncodeToString(hash[:])+thumbnailExtension, thumbnailContentType, thumbnail)
+	if err != nil {
+		return nil, fmt.Errorf("putting image to the storage error: %w", err)
+	}
+
+	return NewStoredImageDTO(imageURL, url, filePath), nil
+}
+
+func (t Thumbnails) download(ctx context.Context, imageURL string) ([]byte, error) {
+	content, err := t.fetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading error: %w", err)
+	}
+
+	// The response content type is often wrong, the content itself is checked.
+	if contentType := http.DetectContentType(content); !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w, detected content type: %s", errNotAnImage, contentType)
+	}
+
+	return content, nil
+}
+
+func (t Thumbnails) thumbnail(content []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w, decoding error: %s", errNotAnImage, err.Error())
+	}
+
+	if config.Width*config.Height > t.maxPixels {
+		return nil, fmt.Errorf(
+			"%w, %dx%d is more than %d pixels", errImageTooLarge, config.Width, config.Height, t.maxPixels,
+		)
+	}
+
+	source, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w, decoding error: %s", errNotAnImage, err.Error())
+	}
+
+	bounds := source.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil, fmt.Errorf("%w, format: %s", errEmptyImage, format)
+	}
+
+	width, height := fitInto(bounds.Dx(), bounds.Dy(), t.maxSide)
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// JPEG has no transparency, transparent areas become white.
+	draw.Draw(thumbnail, thumbnail.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(thumbnail, thumbnail.Bounds(), source, bounds, draw.Over, nil)
+
+	var buffer bytes.Buffer
+	if err = jpeg.Encode(&buffer, thumbnail, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("thumbnail encoding error: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// fitInto scales the size down keeping the aspect ratio, so its longest side isn't longer than maxSide.
+func fitInto(width, height, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+
+	if width >= height {
+		return maxSide, maxInt(1, height*maxSide/width)
+	}
+
+	return maxInt(1, width*maxSide/height), maxSide
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
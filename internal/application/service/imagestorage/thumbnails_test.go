@@ -0,0 +1,357 @@
+package imagestorage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/r-erema/vocaboost/internal/application/service/imagestorage"
+)
+
+const (
+	testMaxSide   = 50
+	testMaxPixels = 200 * 100
+	testBaseURL   = "https://media.test/images"
+)
+
+var errFetch = errors.New("fetch error")
+
+// fakeFetcher serves the contents it has and fails for other URLs.
+type fakeFetcher struct {
+	contents map[string][]byte
+}
+
+func (f fakeFetcher) Fetch(_ context.Context, imageURL string) ([]byte, error) {
+	content, exists := f.contents[imageURL]
+	if !exists {
+		return nil, errFetch
+	}
+
+	return content, nil
+}
+
+// testPicture is an image of the size, its left half is transparent and its right half is red.
+func testPicture(width, height int) *image.NRGBA {
+	picture := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for x := width / 2; x < width; x++ {
+		for y := 0; y < height; y++ {
+			picture.Set(x, y, color.NRGBA{R: 255, G: 0, B: 0, A: 255})
+		}
+	}
+
+	return picture
+}
+
+func encodePNG(t *testing.T, picture image.Image) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, picture); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func encodeGIF(t *testing.T, picture image.Image) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	if err := gif.Encode(&buffer, picture, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.Bytes()
+}
+
+func thumbnailName(imageURL string) string {
+	hash := sha256.Sum256([]byte(imageURL))
+
+	return hex.EncodeToString(hash[:]) + ".jpg"
+}
+
+func TestThumbnailsStoreOnLocalDisk(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "images")
+
+	storage, err := imagestorage.NewLocalDisk(dir, testBaseURL+"/")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	contents := map[string][]byte{
+		"https://img.test/wide.png":  encodePNG(t, testPicture(200, 100)),
+		"https://img.test/tall.gif":  encodeGIF(t, testPicture(3, 90)),
+		"https://img.test/small.png": encodePNG(t, testPicture(40, 30)),
+	}
+	imageURLs := []string{"https://img.test/wide.png", "https://img.test/tall.gif", "https://img.test/small.png"}
+
+	stored, err := imagestorage.NewThumbnails(storage, fakeFetcher{contents: contents}, testMaxSide, testMaxPixels).
+		Store(context.Background(), imageURLs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(stored) != len(imageURLs) {
+		t.Fatalf("got %d images stored, want %d", len(stored), len(imageURLs))
+	}
+
+	// Scaled down keeping the aspect ratio, a side isn't shorter than a pixel, small images keep their size.
+	wantSizes := []image.Point{{X: 50, Y: 25}, {X: 1, Y: 50}, {X: 40, Y: 30}}
+
+	for i, storedImage := range stored {
+		name := thumbnailName(imageURLs[i])
+
+		if storedImage.SourceURL() != imageURLs[i] || storedImage.URL() != testBaseURL+"/"+name ||
+			storedImage.FilePath() != filepath.Join(dir, name) {
+			t.Errorf("got image %s stored at %s, %s", storedImage.SourceURL(), storedImage.URL(), storedImage.FilePath())
+		}
+
+		content, err := os.ReadFile(storedImage.FilePath())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		thumbnail, err := jpeg.Decode(bytes.NewReader(content))
+		if err != nil {
+			t.Fatalf("got not a JPEG thumbnail of %s: %s", imageURLs[i], err)
+		}
+
+		if size := thumbnail.Bounds().Size(); size != wantSizes[i] {
+			t.Errorf("got thumbnail %v of %s, want %v", size, imageURLs[i], wantSizes[i])
+		}
+	}
+}
+
+func TestThumbnailsFillTransparencyWithWhite(t *testing.T) {
+	t.Parallel()
+
+	storage, err := imagestorage.NewLocalDisk(t.TempDir(), testBaseURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fetcher := fakeFetcher{contents: map[string][]byte{"https://img.test/a.png": encodePNG(t, testPicture(40, 40))}}
+
+	stored, err := imagestorage.NewThumbnails(storage, fetcher, testMaxSide, testMaxPixels).
+		Store(context.Background(), []string{"https://img.test/a.png"})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("got %d images stored and error %v, want the image stored", len(stored), err)
+	}
+
+	content, err := os.ReadFile(stored[0].FilePath())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	thumbnail, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// JPEG is lossy, the colors are compared roughly.
+	for _, testCase := range []struct {
+		point   image.Point
+		wantRed bool
+	}{
+		{point: image.Point{X: 5, Y: 20}, wantRed: false},
+		{point: image.Point{X: 35, Y: 20}, wantRed: true},
+	} {
+		r, g, b, _ := thumbnail.At(testCase.point.X, testCase.point.Y).RGBA()
+		isWhite := r > 0xf000 && g > 0xf000 && b > 0xf000
+		isRed := r > 0xf000 && g < 0x1000 && b < 0x1000
+
+		if isRed != testCase.wantRed || isWhite == testCase.wantRed {
+			t.Errorf("got color %x %x %x at %v, want red: %t", r, g, b, testCase.point, testCase.wantRed)
+		}
+	}
+}
+
+func TestThumbnailsSkipBadImages(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	storage, err := imagestorage.NewLocalDisk(dir, testBaseURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pngContent := encodePNG(t, testPicture(40, 30))
+	contents := map[string][]byte{
+		"https://img.test/ok.png": pngContent,
+		// The pixels are limited, not the file size, so the check doesn't depend on the compression.
+		"https://img.test/huge.png":   encodePNG(t, image.NewGray(image.Rect(0, 0, 201, 100))),
+		"https://img.test/page.png":   []byte("<!DOCTYPE html><html><body>Not found</body></html>"),
+		"https://img.test/broken.png": pngContent[:len(pngContent)/2],
+	}
+	imageURLs := []string{
+		"https://img.test/missing.png",
+		"https://img.test/huge.png",
+		"https://img.test/page.png",
+		"https://img.test/broken.png",
+		"https://img.test/ok.png",
+	}
+
+	stored, err := imagestorage.NewThumbnails(storage, fakeFetcher{contents: contents}, testMaxSide, testMaxPixels).
+		Store(context.Background(), imageURLs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(stored) != 1 || stored[0].SourceURL() != "https://img.test/ok.png" {
+		t.Errorf("got %d images stored, want only the good one", len(stored))
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("got %d files stored, want 1", len(files))
+	}
+}
+
+func TestThumbnailsStoreFailsWhenInterrupted(t *testing.T) {
+	t.Parallel()
+
+	storage, err := imagestorage.NewLocalDisk(t.TempDir(), testBaseURL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = imagestorage.NewThumbnails(storage, fakeFetcher{contents: nil}, testMaxSide, testMaxPixels).
+		Store(ctx, []string{"https://img.test/a.png"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestNewLocalDiskFails(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := imagestorage.NewLocalDisk(filepath.Join(file, "images"), testBaseURL); err == nil {
+		t.Error("got no error for a directory inside a file, want one")
+	}
+}
+
+// s3Stub records the objects put to it and answers like an S3 storage, or fails with the status.
+type s3Stub struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newS3Stub(t *testing.T, status int) (*s3Stub, *minio.Client) {
+	t.Helper()
+
+	stub := &s3Stub{mu: sync.Mutex{}, objects: map[string][]byte{}, types: map[string]string{}}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, err := io.ReadAll(r.Body)
+		if err != nil || r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+
+			return
+		}
+
+		stub.mu.Lock()
+		stub.objects[r.URL.Path] = content
+		stub.types[r.URL.Path] = r.Header.Get("Content-Type")
+		stub.mu.Unlock()
+
+		w.Header().Set("ETag", `"etag"`)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := minio.New(serverURL.Host, &minio.Options{ //nolint:exhaustruct
+		Creds: credentials.NewStaticV4("access", "secret", ""),
+		// Over plain HTTP the payload is sent in signed chunks, over TLS it's sent as it is.
+		Secure:    true,
+		Transport: server.Client().Transport,
+		Region:    "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stub, client
+}
+
+func TestThumbnailsStoreOnS3(t *testing.T) {
+	t.Parallel()
+
+	stub, client := newS3Stub(t, http.StatusOK)
+	fetcher := fakeFetcher{contents: map[string][]byte{"https://img.test/a.png": encodePNG(t, testPicture(200, 100))}}
+
+	stored, err := imagestorage.NewThumbnails(
+		imagestorage.NewS3(client, "vocaboost", "http://localhost:9000/vocaboost/"), fetcher, testMaxSide, testMaxPixels,
+	).Store(context.Background(), []string{"https://img.test/a.png"})
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("got %d images stored and error %v, want the image stored", len(stored), err)
+	}
+
+	name := thumbnailName("https://img.test/a.png")
+	if stored[0].URL() != "http://localhost:9000/vocaboost/"+name || stored[0].FilePath() != "" {
+		t.Errorf("got image stored at %s, %s, want the public URL only", stored[0].URL(), stored[0].FilePath())
+	}
+
+	if _, exists := stub.objects["/vocaboost/"+name]; !exists || len(stub.objects) != 1 {
+		t.Fatalf("got %d objects put, want the thumbnail in the bucket", len(stub.objects))
+	}
+
+	if contentType := stub.types["/vocaboost/"+name]; contentType != "image/jpeg" {
+		t.Errorf("got content type %s, want image/jpeg", contentType)
+	}
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(stub.objects["/vocaboost/"+name]))
+	if err != nil || config.Width != testMaxSide || config.Height != testMaxSide/2 {
+		t.Errorf("got thumbnail %dx%d and error %v, want %dx%d", config.Width, config.Height, err, testMaxSide, testMaxSide/2)
+	}
+}
+
+func TestS3PutFails(t *testing.T) {
+	t.Parallel()
+
+	_, client := newS3Stub(t, http.StatusForbidden)
+
+	if _, _, err := imagestorage.NewS3(client, "vocaboost", testBaseURL).Put(
+		context.Background(), "a.jpg", "image/jpeg", []byte("content"),
+	); err == nil {
+		t.Error("got no error, want one")
+	}
+}
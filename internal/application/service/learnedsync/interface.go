@@ -0,0 +1,13 @@
+package learnedsync
+
+import (
+	"context"
+	"time"
+)
+
+// Interface is a place where words are learned, e.g. a spaced repetition service.
+type Interface interface {
+	Name() string
+	// LearnedWords returns the words learned since the time and the time to pass on the next call.
+	LearnedWords(ctx context.Context, since time.Time) ([]string, time.Time, error)
+}
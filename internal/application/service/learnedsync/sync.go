@@ -0,0 +1,79 @@
+package learnedsync
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+)
+
+// Sync saves words learned in the source as known, so they aren't offered as unknown anymore.
+// The source cursor is kept between runs, so every run asks only for the words learned since the previous one.
+type Sync struct {
+	source    Interface
+	wordsRepo repository.Interface
+	cursors   repository.SyncCursorsInterface
+}
+
+func NewSync(source Interface, wordsRepo repository.Interface, cursors repository.SyncCursorsInterface) *Sync {
+	return &Sync{source: source, wordsRepo: wordsRepo, cursors: cursors}
+}
+
+// Run saves the words learned since the previous run as known and returns how many words were saved.
+func (s Sync) Run(ctx context.Context) (int, error) {
+	cursor, err := s.cursors.SyncCursor(ctx, s.source.Name())
+	if err != nil {
+		return 0, fmt.Errorf("getting sync cursor error: %w", err)
+	}
+
+	learnedWords, nextCursor, err := s.source.LearnedWords(ctx, cursor)
+	if err != nil {
+		return 0, fmt.Errorf("getting learned words from `%s` error: %w", s.source.Name(), err)
+	}
+
+	words := make([]string, 0, len(learnedWords))
+	seen := make(map[string]bool, len(learnedWords))
+
+	for _, word := range learnedWords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+
+	if err = s.wordsRepo.SaveAsKnown(ctx, words); err != nil {
+		return 0, fmt.Errorf("saving learned words as known error: %w", err)
+	}
+
+	if nextCursor.After(cursor) {
+		if err = s.cursors.SaveSyncCursor(ctx, s.source.Name(), nextCursor); err != nil {
+			return 0, fmt.Errorf("saving sync cursor error: %w", err)
+		}
+	}
+
+	return len(words), nil
+}
+
+// RunPeriodically runs the sync at the interval until the context is done, failed runs are logged.
+func (s Sync) RunPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := s.Run(ctx); err != nil {
+			log.Printf("learned words sync error: %s", err)
+		} else if count > 0 {
+			log.Printf("%d learned words were synced from `%s`", count, s.source.Name())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
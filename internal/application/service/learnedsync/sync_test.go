@@ -0,0 +1,207 @@
+package learnedsync_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/application/service/learnedsync"
+)
+
+var errStorage = errors.New("storage error")
+
+// fakeSource returns the learned words of the run and the next cursor, the cursors it's asked with are recorded.
+type fakeSource struct {
+	runs   []fakeSourceRun
+	err    error
+	asked  []time.Time
+	called int
+}
+
+type fakeSourceRun struct {
+	words      []string
+	nextCursor time.Time
+}
+
+func (f *fakeSource) Name() string {
+	return "Notion"
+}
+
+func (f *fakeSource) LearnedWords(_ context.Context, since time.Time) ([]string, time.Time, error) {
+	f.asked = append(f.asked, since)
+
+	if f.err != nil {
+		return nil, since, f.err
+	}
+
+	run := f.runs[f.called]
+	f.called++
+
+	return run.words, run.nextCursor, nil
+}
+
+// fakeWordsRepo records the words saved as known, only saving them is used by the sync.
+type fakeWordsRepo struct {
+	repository.Interface
+	known [][]string
+	err   error
+}
+
+func (f *fakeWordsRepo) SaveAsKnown(_ context.Context, words []string) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	f.known = append(f.known, words)
+
+	return nil
+}
+
+type fakeCursors struct {
+	cursors map[string]time.Time
+	saves   int
+	readErr error
+	saveErr error
+}
+
+func (f *fakeCursors) SyncCursor(_ context.Context, sync string) (time.Time, error) {
+	return f.cursors[sync], f.readErr
+}
+
+func (f *fakeCursors) SaveSyncCursor(_ context.Context, sync string, cursor time.Time) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+
+	f.saves++
+	f.cursors[sync] = cursor
+
+	return nil
+}
+
+func TestSyncRunAdvancesCursor(t *testing.T) {
+	t.Parallel()
+
+	firstEdit := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+	source := &fakeSource{
+		runs: []fakeSourceRun{
+			{words: []string{" Cat ", "cat", "Dog", ""}, nextCursor: firstEdit},
+			// Notion returns the pages edited at the cursor again, the cursor stays.
+			{words: []string{"dog"}, nextCursor: firstEdit},
+			{words: []string{"owl"}, nextCursor: firstEdit.Add(time.Minute)},
+		},
+		err:    nil,
+		asked:  nil,
+		called: 0,
+	}
+	wordsRepo := &fakeWordsRepo{Interface: nil, known: nil, err: nil}
+	cursors := &fakeCursors{cursors: map[string]time.Time{}, saves: 0, readErr: nil, saveErr: nil}
+	sync := learnedsync.NewSync(source, wordsRepo, cursors)
+
+	counts := make([]int, 0, len(source.runs))
+
+	for range source.runs {
+		count, err := sync.Run(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		counts = append(counts, count)
+	}
+
+	if want := []int{2, 1, 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("got counts %v, want %v", counts, want)
+	}
+
+	if want := [][]string{{"cat", "dog"}, {"dog"}, {"owl"}}; !reflect.DeepEqual(wordsRepo.known, want) {
+		t.Errorf("got known words %q, want %q", wordsRepo.known, want)
+	}
+
+	if want := []time.Time{{}, firstEdit, firstEdit}; !reflect.DeepEqual(source.asked, want) {
+		t.Errorf("got the source asked since %v, want %v", source.asked, want)
+	}
+
+	if cursors.saves != 2 || !cursors.cursors["Notion"].Equal(firstEdit.Add(time.Minute)) {
+		t.Errorf("got the cursor %s saved %d times, want only the moves saved", cursors.cursors["Notion"], cursors.saves)
+	}
+}
+
+func TestSyncRunFails(t *testing.T) {
+	t.Parallel()
+
+	cursor := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+
+	for name, testCase := range map[string]struct {
+		sourceErr, saveKnownErr, readCursorErr, saveCursorErr error
+		wantKnown                                             bool
+	}{
+		"cursor can't be read":       {nil, nil, errStorage, nil, false},
+		"source fails":               {errStorage, nil, nil, nil, false},
+		"known words can't be saved": {nil, errStorage, nil, nil, false},
+		// The words are saved again on the next run, as the cursor stays.
+		"cursor can't be saved": {nil, nil, nil, errStorage, true},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			source := &fakeSource{
+				runs:   []fakeSourceRun{{words: []string{"cat"}, nextCursor: cursor.Add(time.Minute)}},
+				err:    testCase.sourceErr,
+				asked:  nil,
+				called: 0,
+			}
+			wordsRepo := &fakeWordsRepo{Interface: nil, known: nil, err: testCase.saveKnownErr}
+			cursors := &fakeCursors{
+				cursors: map[string]time.Time{"Notion": cursor},
+				saves:   0,
+				readErr: testCase.readCursorErr,
+				saveErr: testCase.saveCursorErr,
+			}
+
+			if _, err := learnedsync.NewSync(source, wordsRepo, cursors).Run(context.Background()); err == nil {
+				t.Error("got no error, want one")
+			}
+
+			if !cursors.cursors["Notion"].Equal(cursor) || cursors.saves != 0 {
+				t.Errorf("got the cursor %s, want it kept at %s", cursors.cursors["Notion"], cursor)
+			}
+
+			if (len(wordsRepo.known) > 0) != testCase.wantKnown {
+				t.Errorf("got known words %q", wordsRepo.known)
+			}
+		})
+	}
+}
+
+func TestSyncRunPeriodicallyStopsWithContext(t *testing.T) {
+	t.Parallel()
+
+	source := &fakeSource{runs: nil, err: errStorage, asked: nil, called: 0}
+	cursors := &fakeCursors{cursors: map[string]time.Time{}, saves: 0, readErr: nil, saveErr: nil}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		learnedsync.NewSync(source, &fakeWordsRepo{Interface: nil, known: nil, err: nil}, cursors).
+			RunPeriodically(ctx, time.Hour)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the sync didn't stop when the context was done")
+	}
+
+	if len(source.asked) != 1 {
+		t.Errorf("got %d runs, want the first one only", len(source.asked))
+	}
+}
@@ -0,0 +1,153 @@
+package pronunciation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	TTSCommandTextPlaceholder   = "{text}"
+	TTSCommandOutputPlaceholder = "{output}"
+
+	audioFileExtension = ".wav"
+	audioDirPerm       = 0o755
+	// tempAudioFilePrefix marks files being synthesized, they're renamed once the command succeeds.
+	tempAudioFilePrefix = ".tmp-"
+)
+
+var (
+	errEmptyTTSCommand        = errors.New("empty TTS command")
+	errNoTTSOutputPlaceholder = errors.New("TTS command has no output placeholder")
+
+	unsafeFileNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// TTSCommand synthesizes audio with a local text-to-speech command, e.g. `espeak-ng -w {output} {text}`
+// or `piper --model en_US-lessac-medium.onnx --output_file {output}`. If the command has no `{text}`
+// placeholder, the text is written to its stdin. Files are stored in the storage directory and are expected
+// to be served from the base URL.
+type TTSCommand struct {
+	command        []string
+	storagePath    string
+	storageBaseURL string
+}
+
+func NewTTSCommand(command, storagePath, storageBaseURL string) (*TTSCommand, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errEmptyTTSCommand
+	}
+
+	if !strings.Contains(command, TTSCommandOutputPlaceholder) {
+		return nil, fmt.Errorf("%w `%s`: %s", errNoTTSOutputPlaceholder, TTSCommandOutputPlaceholder, command)
+	}
+
+	if err := os.MkdirAll(storagePath, audioDirPerm); err != nil {
+		return nil, fmt.Errorf("creating audio storage directory error: %w", err)
+	}
+
+	return &TTSCommand{command: args, storagePath: storagePath, storageBaseURL: strings.TrimRight(storageBaseURL, "/")}, nil
+}
+
+// Audio skips the words the command fails for, so they're left without audio.
+func (tc TTSCommand) Audio(ctx context.Context, words []string) ([]*WordAudioDTO, error) {
+	wordsAudio := make([]*WordAudioDTO, 0, len(words))
+
+	for i := range words {
+		fileName := audioFileName(words[i])
+		filePath := filepath.Join(tc.storagePath, fileName)
+
+		if _, err := os.Stat(filePath); err != nil {
+			if err = tc.synthesize(ctx, words[i], filePath); err != nil {
+				if ctx.Err() != nil {
+					return nil, fmt.Errorf("synthesizing audio interrupted: %w", ctx.Err())
+				}
+
+				log.Printf("synthesizing audio for word `%s` error: %s", words[i], err)
+
+				continue
+			}
+		}
+
+		wordsAudio = append(wordsAudio, NewWordAudioDTO(words[i], tc.storageBaseURL+"/"+url.PathEscape(fileName), filePath))
+	}
+
+	return wordsAudio, nil
+}
+
+// synthesize writes the audio to a temporary file first, so a failed or interrupted command never leaves
+// a broken file which would be taken for the synthesized audio.
+func (tc TTSCommand) synthesize(ctx context.Context, text, filePath string) error {
+	tempFile, err := os.CreateTemp(tc.storagePath, tempAudioFilePrefix+"*-"+filepath.Base(filePath))
+	if err != nil {
+		return fmt.Errorf("creating temporary audio file error: %w", err)
+	}
+
+	tempFilePath := tempFile.Name()
+
+	if err = tempFile.Close(); err != nil {
+		log.Printf("temporary audio file closing error: %s", err.Error())
+	}
+
+	if err = tc.runCommand(ctx, text, tempFilePath); err != nil {
+		if removeErr := os.Remove(tempFilePath); removeErr != nil {
+			log.Printf("temporary audio file removing error: %s", removeErr.Error())
+		}
+
+		return err
+	}
+
+	if err = os.Rename(tempFilePath, filePath); err != nil {
+		return fmt.Errorf("moving synthesized audio file error: %w", err)
+	}
+
+	return nil
+}
+
+func (tc TTSCommand) runCommand(ctx context.Context, text, filePath string) error {
+	textAsArgument := false
+	args := make([]string, len(tc.command))
+
+	for i, arg := range tc.command {
+		if strings.Contains(arg, TTSCommandTextPlaceholder) {
+			textAsArgument = true
+		}
+
+		args[i] = strings.NewReplacer(TTSCommandTextPlaceholder, text, TTSCommandOutputPlaceholder, filePath).Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+
+	if !textAsArgument {
+		cmd.Stdin = strings.NewReader(text)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("command `%s` execution error: %w, stderr: %s", args[0], err, stderr.String())
+	}
+
+	return nil
+}
+
+// audioFileName is stable per word, the hash keeps words differing only in unsafe characters apart.
+func audioFileName(word string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(word))
+
+	return fmt.Sprintf(
+		"%s-%08x%s", unsafeFileNameChars.ReplaceAllString(strings.ToLower(word), "_"), hash.Sum32(), audioFileExtension,
+	)
+}
@@ -0,0 +1,64 @@
+package pronunciation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/pronunciation"
+)
+
+func TestTTSCommand(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		command   string
+		wantWords []string
+	}{
+		"synthesized": {command: "touch {output}", wantWords: []string{"cat", "purr"}},
+		"failed":      {command: "false {output} {text}", wantWords: []string{}},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			storagePath := filepath.Join(t.TempDir(), "audio")
+
+			ttsCommand, err := pronunciation.NewTTSCommand(testCase.command, storagePath, "https://audio.test/")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			wordsAudio, err := ttsCommand.Audio(context.Background(), []string{"cat", "purr"})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			words := make([]string, len(wordsAudio))
+			for i, wordAudio := range wordsAudio {
+				words[i] = wordAudio.Word()
+
+				if _, err = os.Stat(wordAudio.FilePath()); err != nil {
+					t.Errorf("got no audio file of `%s`: %s", wordAudio.Word(), err)
+				}
+			}
+
+			if !reflect.DeepEqual(words, testCase.wantWords) {
+				t.Errorf("got audio of %q, want %q", words, testCase.wantWords)
+			}
+
+			// Temporary files are renamed or removed, nothing but the synthesized audio stays.
+			files, err := os.ReadDir(storagePath)
+			if err != nil {
+				t.Fatalf("reading audio directory error: %s", err)
+			}
+
+			if len(files) != len(testCase.wantWords) {
+				t.Errorf("got %d files in the audio directory, want %d", len(files), len(testCase.wantWords))
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package pronunciation
+
+import "context"
+
+type WordAudioDTO struct {
+	word,
+	url,
+	filePath string
+}
+
+func (w WordAudioDTO) Word() string {
+	return w.word
+}
+
+// URL is a stable URL the audio is served from.
+func (w WordAudioDTO) URL() string {
+	return w.url
+}
+
+// FilePath is a path of the local audio file, targets able to embed files (e.g. Anki media) may use it instead of the URL.
+func (w WordAudioDTO) FilePath() string {
+	return w.filePath
+}
+
+func NewWordAudioDTO(word, url, filePath string) *WordAudioDTO {
+	return &WordAudioDTO{word: word, url: url, filePath: filePath}
+}
+
+type Interface interface {
+	// Audio returns the audio of the words it's synthesized for, words it can't be synthesized for are left out.
+	Audio(ctx context.Context, words []string) ([]*WordAudioDTO, error)
+}
@@ -0,0 +1,84 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+var (
+	errSchemeNotAllowed  = errors.New("only http and https URLs are allowed")
+	errAddressNotAllowed = errors.New("the address isn't public")
+)
+
+// Transport sends requests to public http(s) addresses only, so URLs which come from clients can't reach
+// the network of the service. Addresses are checked when connections are dialed, which covers redirects
+// and host names resolving to private addresses. Trusted hosts, e.g. a self-hosted storage, aren't checked.
+type Transport struct {
+	transport        http.RoundTripper
+	trustedTransport http.RoundTripper
+	trustedHosts     map[string]struct{}
+}
+
+// NewTransport creates the transport, the trusted hosts are host[:port] as they're written in URLs.
+func NewTransport(trustedHosts ...string) *Transport {
+	dialer := &net.Dialer{ //nolint:exhaustruct
+		Timeout:   dialTimeout,
+		KeepAlive: dialKeepAlive,
+		Control:   checkAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	// A proxy would be dialed instead of the requested hosts, so the hosts wouldn't be checked.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	hosts := make(map[string]struct{}, len(trustedHosts))
+	for _, host := range trustedHosts {
+		hosts[host] = struct{}{}
+	}
+
+	return &Transport{transport: transport, trustedTransport: http.DefaultTransport, trustedHosts: hosts}
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("%w, got `%s`", errSchemeNotAllowed, req.URL.Scheme)
+	}
+
+	transport := t.transport
+	if _, trusted := t.trustedHosts[req.URL.Host]; trusted {
+		transport = t.trustedTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("round trip error: %w", err)
+	}
+
+	return resp, nil
+}
+
+// checkAddress refuses to connect to loopback, private, link-local, multicast and unspecified addresses.
+func checkAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("splitting address `%s` error: %w", address, err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", errAddressNotAllowed, address)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTransportRefusesNonPublicAddresses(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport()} //nolint:exhaustruct
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, http.NoBody)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+	}
+
+	if !errors.Is(err, errAddressNotAllowed) {
+		t.Errorf("got error %v, want %v", err, errAddressNotAllowed)
+	}
+}
+
+func TestTransportTrustsHosts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client := &http.Client{Transport: NewTransport(serverURL.Host)} //nolint:exhaustruct
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, http.NoBody)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err = resp.Body.Close(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d and closing error %v, want 200", resp.StatusCode, err)
+	}
+}
+
+func TestTransportRefusesOtherSchemes(t *testing.T) {
+	t.Parallel()
+
+	for _, requestURL := range []string{"file:///etc/passwd", "ftp://example.com/cat.jpg", "gopher://example.com"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, requestURL, http.NoBody)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if _, err = NewTransport().RoundTrip(req); !errors.Is(err, errSchemeNotAllowed) { //nolint:bodyclose
+			t.Errorf("%s: got error %v, want %v", requestURL, err, errSchemeNotAllowed)
+		}
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	t.Parallel()
+
+	for address, allowed := range map[string]bool{
+		"93.184.216.34:443":         true,
+		"[2606:4700::1111]:443":     true,
+		"127.0.0.1:80":              false,
+		"[::1]:80":                  false,
+		"10.1.2.3:80":               false,
+		"172.16.0.1:80":             false,
+		"192.168.1.1:80":            false,
+		"169.254.169.254:80":        false,
+		"0.0.0.0:80":                false,
+		"[fe80::1]:80":              false,
+		"[fd00::1]:80":              false,
+		"[::ffff:192.168.1.1]:80":   false,
+		"224.0.0.1:80":              false,
+		"not an address":            false,
+		"[::ffff:93.184.216.34]:80": true,
+	} {
+		if err := checkAddress("tcp", address, nil); (err == nil) != allowed {
+			t.Errorf("%s: got error %v, want allowed %t", address, err, allowed)
+		}
+	}
+}
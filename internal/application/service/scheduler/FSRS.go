@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	fsrsMinDifficulty = 1
+	fsrsMaxDifficulty = 10
+	// fsrsDecayFactor comes from the forgetting curve R = (1 + t / (9 * S)) ^ -1.
+	fsrsDecayFactor = 9
+)
+
+// fsrsWeights are the default FSRS v4 parameters, trained on a large set of Anki reviews.
+var fsrsWeights = [17]float64{ //nolint:gochecknoglobals
+	0.4, 0.6, 2.4, 5.8, 4.93, 0.94, 0.86, 0.01, 1.49, 0.14, 0.94, 2.18, 0.05, 0.34, 1.26, 0.29, 2.61,
+}
+
+// FSRS is the Free Spaced Repetition Scheduler, it models the memory stability and the card difficulty
+// and schedules the card when the probability to recall it drops to the requested retention.
+type FSRS struct {
+	requestedRetention float64
+}
+
+func NewFSRS(requestedRetention float64) *FSRS {
+	return &FSRS{requestedRetention: requestedRetention}
+}
+
+func (f FSRS) Schedule(schedule *domain.Schedule, grade domain.Grade, now time.Time) *domain.Schedule {
+	var stability, difficulty float64
+
+	// Cards reviewed by another scheduler have no stability, they start over like new ones.
+	if schedule.LastReview().IsZero() || schedule.Stability() == 0 {
+		stability = fsrsWeights[grade-1]
+		difficulty = fsrsInitialDifficulty(grade)
+	} else {
+		elapsedDays := now.Sub(schedule.LastReview()).Hours() / 24 //nolint:gomnd
+		retrievability := math.Pow(1+elapsedDays/(fsrsDecayFactor*schedule.Stability()), -1)
+
+		difficulty = fsrsNextDifficulty(schedule.Difficulty(), grade)
+
+		if grade == domain.GradeAgain {
+			stability = fsrsWeights[11] *
+				math.Pow(schedule.Difficulty(), -fsrsWeights[12]) *
+				(math.Pow(schedule.Stability()+1, fsrsWeights[13]) - 1) *
+				math.Exp(fsrsWeights[14]*(1-retrievability))
+		} else {
+			stability = fsrsRecallStability(schedule.Stability(), schedule.Difficulty(), retrievability, grade)
+		}
+	}
+
+	if grade == domain.GradeAgain {
+		return domain.NewSchedule(
+			now.Add(relearningDelay), now, 0, 0, schedule.Lapses()+1,
+			schedule.EaseFactor(), stability, difficulty,
+		)
+	}
+
+	intervalDays := int(math.Round(fsrsDecayFactor * stability * (1/f.requestedRetention - 1)))
+	if intervalDays < 1 {
+		intervalDays = 1
+	}
+
+	if intervalDays > maxIntervalDays {
+		intervalDays = maxIntervalDays
+	}
+
+	return domain.NewSchedule(
+		now.Add(time.Duration(intervalDays)*day), now, intervalDays, schedule.Repetitions()+1, schedule.Lapses(),
+		schedule.EaseFactor(), stability, difficulty,
+	)
+}
+
+func fsrsInitialDifficulty(grade domain.Grade) float64 {
+	return fsrsClampDifficulty(fsrsWeights[4] - float64(grade-domain.GradeGood)*fsrsWeights[5])
+}
+
+// fsrsNextDifficulty shifts the difficulty by the grade and reverts it a bit to the initial difficulty of good.
+func fsrsNextDifficulty(difficulty float64, grade domain.Grade) float64 {
+	shifted := difficulty - fsrsWeights[6]*float64(grade-domain.GradeGood)
+
+	return fsrsClampDifficulty(fsrsWeights[7]*fsrsInitialDifficulty(domain.GradeGood) + (1-fsrsWeights[7])*shifted)
+}
+
+func fsrsRecallStability(stability, difficulty, retrievability float64, grade domain.Grade) float64 {
+	hardPenalty, easyBonus := 1.0, 1.0
+
+	switch grade {
+	case domain.GradeHard:
+		hardPenalty = fsrsWeights[15]
+	case domain.GradeEasy:
+		easyBonus = fsrsWeights[16]
+	case domain.GradeAgain, domain.GradeGood:
+	}
+
+	return stability * (1 + math.Exp(fsrsWeights[8])*
+		(11-difficulty)*
+		math.Pow(stability, -fsrsWeights[9])*
+		(math.Exp(fsrsWeights[10]*(1-retrievability))-1)*
+		hardPenalty*easyBonus)
+}
+
+func fsrsClampDifficulty(difficulty float64) float64 {
+	return math.Max(fsrsMinDifficulty, math.Min(fsrsMaxDifficulty, difficulty))
+}
@@ -0,0 +1,111 @@
+package scheduler_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/scheduler"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const fsrsTolerance = 5e-5
+
+// TestFSRS checks the FSRS v4 schedules with the default parameters: a new card starts with the stability
+// w[grade-1] and the difficulty w4 - (grade - 3) * w5, at the retention of 0.9 the interval equals the stability.
+// Further reviews follow the published stability and difficulty formulas, the values are rounded to 4 places.
+func TestFSRS(t *testing.T) {
+	t.Parallel()
+
+	again, hard, good, easy := domain.GradeAgain, domain.GradeHard, domain.GradeGood, domain.GradeEasy
+
+	for name, testCase := range map[string]struct {
+		retention    float64
+		grades       []domain.Grade
+		intervals    []int
+		stabilities  []float64
+		difficulties []float64
+	}{
+		"good": {
+			retention:    0.9,
+			grades:       []domain.Grade{good, good, good, good, good},
+			intervals:    []int{2, 7, 21, 58, 145},
+			stabilities:  []float64{2.4, 7.1416, 21.2685, 57.6295, 144.8795},
+			difficulties: []float64{4.93, 4.93, 4.93, 4.93, 4.93},
+		},
+		"easy": {
+			retention:    0.9,
+			grades:       []domain.Grade{easy, easy, easy},
+			intervals:    []int{6, 43, 271},
+			stabilities:  []float64{5.8, 43.2606, 270.7619},
+			difficulties: []float64{3.99, 3.148, 2.3144},
+		},
+		"hard": {
+			retention:    0.9,
+			grades:       []domain.Grade{hard, hard, hard},
+			intervals:    []int{1, 1, 2},
+			stabilities:  []float64{0.6, 1.2731, 1.8053},
+			difficulties: []float64{5.87, 6.712, 7.5456},
+		},
+		"lapse": {
+			retention:    0.9,
+			grades:       []domain.Grade{good, good, again, good, good},
+			intervals:    []int{2, 7, 0, 2, 6},
+			stabilities:  []float64{2.4, 7.1416, 2.3693, 2.3817, 5.8089},
+			difficulties: []float64{4.93, 4.93, 6.6328, 6.6158, 6.5989},
+		},
+		"lower retention": {
+			retention:    0.8,
+			grades:       []domain.Grade{good, good, good},
+			intervals:    []int{5, 30, 147},
+			stabilities:  []float64{2.4, 13.4514, 65.1272},
+			difficulties: []float64{4.93, 4.93, 4.93},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedules := review(scheduler.NewFSRS(testCase.retention), testCase.grades)
+
+			intervals := make([]int, len(schedules))
+			for i, schedule := range schedules {
+				intervals[i] = schedule.IntervalDays()
+
+				if math.Abs(schedule.Stability()-testCase.stabilities[i]) > fsrsTolerance ||
+					math.Abs(schedule.Difficulty()-testCase.difficulties[i]) > fsrsTolerance {
+					t.Errorf(
+						"review %d: got stability %v and difficulty %v, want %v and %v", i+1,
+						schedule.Stability(), schedule.Difficulty(), testCase.stabilities[i], testCase.difficulties[i],
+					)
+				}
+			}
+
+			if !reflect.DeepEqual(intervals, testCase.intervals) {
+				t.Errorf("got intervals %v, want %v", intervals, testCase.intervals)
+			}
+		})
+	}
+}
+
+func TestFSRSInitialStabilities(t *testing.T) {
+	t.Parallel()
+
+	for grade, want := range map[domain.Grade]struct {
+		stability, difficulty float64
+	}{
+		domain.GradeAgain: {stability: 0.4, difficulty: 6.81},
+		domain.GradeHard:  {stability: 0.6, difficulty: 5.87},
+		domain.GradeGood:  {stability: 2.4, difficulty: 4.93},
+		domain.GradeEasy:  {stability: 5.8, difficulty: 3.99},
+	} {
+		schedule := review(scheduler.NewFSRS(0.9), []domain.Grade{grade})[0] //nolint:gomnd
+
+		if math.Abs(schedule.Stability()-want.stability) > fsrsTolerance ||
+			math.Abs(schedule.Difficulty()-want.difficulty) > fsrsTolerance {
+			t.Errorf("grade %d: got stability %v and difficulty %v, want %+v",
+				grade, schedule.Stability(), schedule.Difficulty(), want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"math"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	sm2InitialEaseFactor = 2.5
+	sm2MinEaseFactor     = 1.3
+	sm2FirstInterval     = 1
+	sm2SecondInterval    = 6
+	sm2MaxQuality        = 5
+)
+
+// sm2Qualities maps grades to the 0-5 response qualities of SM-2, a quality below 3 is a failure.
+var sm2Qualities = map[domain.Grade]float64{ //nolint:gochecknoglobals
+	domain.GradeAgain: 1,
+	domain.GradeHard:  3,
+	domain.GradeGood:  4,
+	domain.GradeEasy:  5,
+}
+
+// SM2 is the SuperMemo 2 algorithm, the interval grows by the ease factor which is adjusted on every review.
+type SM2 struct{}
+
+func NewSM2() *SM2 {
+	return &SM2{}
+}
+
+func (s SM2) Schedule(schedule *domain.Schedule, grade domain.Grade, now time.Time) *domain.Schedule {
+	quality := sm2Qualities[grade]
+
+	easeFactor := schedule.EaseFactor()
+	if easeFactor == 0 {
+		easeFactor = sm2InitialEaseFactor
+	}
+
+	easeFactor += 0.1 - (sm2MaxQuality-quality)*(0.08+(sm2MaxQuality-quality)*0.02) //nolint:gomnd
+	if easeFactor < sm2MinEaseFactor {
+		easeFactor = sm2MinEaseFactor
+	}
+
+	if grade == domain.GradeAgain {
+		return domain.NewSchedule(
+			now.Add(relearningDelay), now, 0, 0, schedule.Lapses()+1,
+			easeFactor, schedule.Stability(), schedule.Difficulty(),
+		)
+	}
+
+	var intervalDays int
+
+	switch schedule.Repetitions() {
+	case 0:
+		intervalDays = sm2FirstInterval
+	case 1:
+		intervalDays = sm2SecondInterval
+	default:
+		intervalDays = int(math.Round(float64(schedule.IntervalDays()) * easeFactor))
+	}
+
+	if intervalDays > maxIntervalDays {
+		intervalDays = maxIntervalDays
+	}
+
+	return domain.NewSchedule(
+		now.Add(time.Duration(intervalDays)*day), now, intervalDays, schedule.Repetitions()+1, schedule.Lapses(),
+		easeFactor, schedule.Stability(), schedule.Difficulty(),
+	)
+}
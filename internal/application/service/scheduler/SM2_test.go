@@ -0,0 +1,106 @@
+package scheduler_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/service/scheduler"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	easeFactorTolerance = 1e-9
+	relearningDelay     = 10 * time.Minute
+)
+
+// review grades a new card in turn, every review happens when the card is due.
+func review(cardsScheduler scheduler.Interface, grades []domain.Grade) []*domain.Schedule {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	schedule := domain.NewUnreviewedSchedule(now)
+	schedules := make([]*domain.Schedule, len(grades))
+
+	for i, grade := range grades {
+		schedule = cardsScheduler.Schedule(schedule, grade, schedule.Due())
+		schedules[i] = schedule
+	}
+
+	return schedules
+}
+
+// TestSM2 checks the intervals I(1) = 1, I(2) = 6, I(n) = I(n-1) * EF and the ease factor
+// EF' = EF + (0.1 - (5 - q) * (0.08 + (5 - q) * 0.02)) of the published SM-2 algorithm.
+func TestSM2(t *testing.T) {
+	t.Parallel()
+
+	again, hard, good, easy := domain.GradeAgain, domain.GradeHard, domain.GradeGood, domain.GradeEasy
+
+	for name, testCase := range map[string]struct {
+		grades      []domain.Grade
+		intervals   []int
+		easeFactors []float64
+	}{
+		"good": {
+			grades:      []domain.Grade{good, good, good, good, good},
+			intervals:   []int{1, 6, 15, 38, 95},
+			easeFactors: []float64{2.5, 2.5, 2.5, 2.5, 2.5},
+		},
+		"easy": {
+			grades:      []domain.Grade{easy, easy, easy, easy},
+			intervals:   []int{1, 6, 17, 49},
+			easeFactors: []float64{2.6, 2.7, 2.8, 2.9},
+		},
+		"hard": {
+			grades:      []domain.Grade{hard, hard, hard, hard},
+			intervals:   []int{1, 6, 12, 23},
+			easeFactors: []float64{2.36, 2.22, 2.08, 1.94},
+		},
+		"again restarts the repetitions": {
+			grades:      []domain.Grade{good, good, again, good, good},
+			intervals:   []int{1, 6, 0, 1, 6},
+			easeFactors: []float64{2.5, 2.5, 1.96, 1.96, 1.96},
+		},
+		"ease factor floor": {
+			grades:      []domain.Grade{again, again, again, good},
+			intervals:   []int{0, 0, 0, 1},
+			easeFactors: []float64{1.96, 1.42, 1.3, 1.3},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedules := review(scheduler.NewSM2(), testCase.grades)
+
+			intervals := make([]int, len(schedules))
+			for i, schedule := range schedules {
+				intervals[i] = schedule.IntervalDays()
+
+				if math.Abs(schedule.EaseFactor()-testCase.easeFactors[i]) > easeFactorTolerance {
+					t.Errorf("review %d: got ease factor %v, want %v", i+1, schedule.EaseFactor(), testCase.easeFactors[i])
+				}
+			}
+
+			if !reflect.DeepEqual(intervals, testCase.intervals) {
+				t.Errorf("got intervals %v, want %v", intervals, testCase.intervals)
+			}
+		})
+	}
+}
+
+func TestSM2Again(t *testing.T) {
+	t.Parallel()
+
+	schedules := review(scheduler.NewSM2(), []domain.Grade{domain.GradeGood, domain.GradeGood, domain.GradeAgain})
+	lapsed := schedules[2]
+
+	if lapsed.Repetitions() != 0 || lapsed.Lapses() != 1 {
+		t.Errorf("got %d repetitions and %d lapses, want 0 and 1", lapsed.Repetitions(), lapsed.Lapses())
+	}
+
+	if delay := lapsed.Due().Sub(lapsed.LastReview()); delay != relearningDelay {
+		t.Errorf("got the card due in %s, want %s", delay, relearningDelay)
+	}
+}
@@ -0,0 +1,19 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	// relearningDelay is when a forgotten card is shown again, within the same review session.
+	relearningDelay = 10 * time.Minute
+	day             = 24 * time.Hour
+	maxIntervalDays = 36500
+)
+
+type Interface interface {
+	// Schedule returns the schedule after a review of the card with the grade at the time.
+	Schedule(schedule *domain.Schedule, grade domain.Grade, now time.Time) *domain.Schedule
+}
@@ -0,0 +1,473 @@
+package spacedrepetition
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1" //nolint:gosec // media names only need to be stable.
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	ankiTarget            = "Anki"
+	ankiPackageExtension  = ".apkg"
+	ankiCollectionFile    = "collection.anki2"
+	ankiMediaFile         = "media"
+	ankiMediaPrefix       = "vocaboost-"
+	ankiDefaultImageExt   = ".jpg"
+	ankiDefaultAudioExt   = ".mp3"
+	exportDirPerm         = 0o755
+	ankiPackageNameLayout = "20060102-150405.000000000"
+)
+
+var errMediaTooLarge = errors.New("the media file is too large")
+
+// ankiWordNoteType is the note type of word cards, the fields are filled by ankiWordFields.
+var ankiWordNoteType = &ankiNoteType{ //nolint:gochecknoglobals
+	id:     ankiStableID("vocaboost word"),
+	name:   "Vocaboost Word",
+	fields: []string{"Word", "Pronunciation", "Definitions", "Examples", "Images", "Context"},
+	templates: [][2]string{{
+		`<div class="word">{{Word}}</div><div class="pronunciation">{{Pronunciation}}</div>`,
+		`{{FrontSide}}<hr id="answer"><div class="images">{{Images}}</div>{{Definitions}}{{Examples}}` +
+			`{{#Context}}<div class="context">{{Context}}</div>{{/Context}}`,
+	}},
+	css: `.card { font-family: arial; font-size: 20px; text-align: center; color: black; background-color: white; }
+.word { font-size: 32px; font-weight: bold; }
+.pronunciation { color: gray; }
+.images img { max-height: 150px; margin: 4px; }
+.definitions, .examples { text-align: left; }
+.translation { color: #2a6fdb; }
+.related { color: gray; font-size: 16px; }
+.examples { font-style: italic; }
+.context { margin-top: 12px; color: #555; }`,
+	cloze: false,
+}
+
+// ankiClozeNoteType is the note type of cloze cards, the fields are filled by ankiClozeFields.
+var ankiClozeNoteType = &ankiNoteType{ //nolint:gochecknoglobals
+	id:     ankiStableID("vocaboost cloze"),
+	name:   "Vocaboost Cloze",
+	fields: []string{"Text", "Word", "Extra"},
+	templates: [][2]string{{
+		`<div class="cloze-text">{{cloze:Text}}</div>`,
+		`<div class="cloze-text">{{cloze:Text}}</div><hr id="answer"><div class="word">{{Word}}</div>{{Extra}}`,
+	}},
+	css: `.card { font-family: arial; font-size: 20px; text-align: center; color: black; background-color: white; }
+.cloze { font-weight: bold; color: #2a6fdb; }
+.word { font-size: 28px; font-weight: bold; }
+.definitions { text-align: left; }
+.translation { color: #2a6fdb; }
+.related { color: gray; font-size: 16px; }`,
+	cloze: true,
+}
+
+// Anki writes the words into an Anki package file, the package is available at the download URL of the report.
+// Images and audio go into the package as media, local files are taken as they are, others are downloaded.
+type Anki struct {
+	deck            ankiDeck
+	tags            []string
+	exportDir       string
+	downloadBaseURL string
+	httpClient      *http.Client
+	userAgent       string
+	maxMediaBytes   int64
+}
+
+func NewAnki(
+	deckName string,
+	tags []string,
+	exportDir, downloadBaseURL string,
+	httpClient *http.Client,
+	userAgent string,
+	maxMediaBytes int64,
+) (*Anki, error) {
+	if err := os.MkdirAll(exportDir, exportDirPerm); err != nil {
+		return nil, fmt.Errorf("creating export dir `%s` error: %w", exportDir, err)
+	}
+
+	return &Anki{
+		deck:            ankiDeck{id: ankiStableID(deckName), name: deckName},
+		tags:            tags,
+		exportDir:       exportDir,
+		downloadBaseURL: strings.TrimRight(downloadBaseURL, "/"),
+		httpClient:      httpClient,
+		userAgent:       userAgent,
+		maxMediaBytes:   maxMediaBytes,
+	}, nil
+}
+
+func (a Anki) Name() string {
+	return ankiTarget
+}
+
+func (a Anki) UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	media := newAnkiMedia()
+	noteTypes := map[*ankiNoteType][]ankiNote{ankiWordNoteType: make([]ankiNote, len(words))}
+	results := make([]*WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		noteTypes[ankiWordNoteType][i] = ankiNote{
+			guid:   ankiGUID(ankiWordNoteType.name, word.Word()),
+			fields: ankiWordFields(word, a.fetchMedia(ctx, media, word)),
+			tags:   ankiNoteTags(a.tags, word.Tags()),
+			clozes: 0,
+		}
+
+		if len(word.Clozes()) > 0 {
+			noteTypes[ankiClozeNoteType] = append(noteTypes[ankiClozeNoteType], ankiNote{
+				guid:   ankiGUID(ankiClozeNoteType.name, word.Word()),
+				fields: ankiClozeFields(word),
+				tags:   ankiNoteTags(a.tags, word.Tags()),
+				clozes: len(word.Clozes()),
+			})
+		}
+
+		results[i] = NewWordUploadResultDTO(word.Word(), UploadStatusCreated, nil)
+	}
+
+	fileName := "vocaboost-" + time.Now().Format(ankiPackageNameLayout) + ankiPackageExtension
+	if err := a.writePackage(filepath.Join(a.exportDir, fileName), noteTypes, media); err != nil {
+		return nil, fmt.Errorf("writing Anki package error: %w", err)
+	}
+
+	return []*UploadReportDTO{
+		NewUploadReportDTO(ankiTarget, results, a.downloadBaseURL+"/"+url.PathEscape(fileName)),
+	}, nil
+}
+
+// ankiMediaSource is where a media file comes from, the file path is empty if it isn't stored locally.
+// The default extension is taken if neither the file path nor the URL has one.
+type ankiMediaSource struct {
+	url, filePath, defaultExtension string
+}
+
+// ankiMediaSources are the images and the audio of the word.
+func ankiMediaSources(word *domain.Word) []ankiMediaSource {
+	sources := make([]ankiMediaSource, 0, len(word.Images())+1)
+
+	for _, image := range word.Images() {
+		sources = append(sources, ankiMediaSource{
+			url:              image.URL(),
+			filePath:         image.FilePath(),
+			defaultExtension: ankiDefaultImageExt,
+		})
+	}
+
+	if word.Pronunciation() != nil && word.Pronunciation().AudioURL() != "" {
+		sources = append(sources, ankiMediaSource{
+			url:              word.Pronunciation().AudioURL(),
+			filePath:         word.Pronunciation().AudioFilePath(),
+			defaultExtension: ankiDefaultAudioExt,
+		})
+	}
+
+	return sources
+}
+
+type ankiMedia struct {
+	names   []string
+	content map[string][]byte
+}
+
+func newAnkiMedia() *ankiMedia {
+	return &ankiMedia{names: make([]string, 0), content: make(map[string][]byte)}
+}
+
+// fetchMedia gets the word images and audio, the result maps URLs to media names.
+// Media which can't be fetched are left out of the note.
+func (a Anki) fetchMedia(ctx context.Context, media *ankiMedia, word *domain.Word) map[string]string {
+	sources := ankiMediaSources(word)
+	names := make(map[string]string, len(sources))
+
+	for _, source := range sources {
+		name := ankiMediaName(source)
+		if _, exists := media.content[name]; !exists {
+			content, err := a.readMedia(ctx, source)
+			if err != nil {
+				log.Printf("media `%s` of word `%s` is left out: %s", source.url, word.Word(), err)
+
+				continue
+			}
+
+			media.names = append(media.names, name)
+			media.content[name] = content
+		}
+
+		names[source.url] = name
+	}
+
+	return names
+}
+
+// ankiMediaName is stable per URL, so reimports don't duplicate files.
+func ankiMediaName(source ankiMediaSource) string {
+	hash := sha1.Sum([]byte(source.url)) //nolint:gosec
+
+	extension := strings.ToLower(filepath.Ext(source.filePath))
+	if extension == "" {
+		if parsedURL, err := url.Parse(source.url); err == nil {
+			extension = strings.ToLower(path.Ext(parsedURL.Path))
+		}
+	}
+
+	if extension == "" {
+		extension = source.defaultExtension
+	}
+
+	return ankiMediaPrefix + hex.EncodeToString(hash[:8]) + extension
+}
+
+func (a Anki) readMedia(ctx context.Context, source ankiMediaSource) ([]byte, error) {
+	if source.filePath != "" {
+		content, err := os.ReadFile(source.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading file `%s` error: %w", source.filePath, err)
+		}
+
+		return content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creation request error: %w", err)
+	}
+
+	if a.userAgent != "" {
+		req.Header.Set("User-Agent", a.userAgent)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request execution error: %w", err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, a.maxMediaBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response error: %w", err)
+	}
+
+	if int64(len(content)) > a.maxMediaBytes {
+		return nil, fmt.Errorf("%w, the limit is %d bytes", errMediaTooLarge, a.maxMediaBytes)
+	}
+
+	return content, nil
+}
+
+// writePackage zips the collection and the media, the media files are numbered and the `media` file maps
+// the numbers to the names.
+func (a Anki) writePackage(packagePath string, noteTypes map[*ankiNoteType][]ankiNote, media *ankiMedia) (err error) {
+	tempDir, err := os.MkdirTemp("", "vocaboost-anki-")
+	if err != nil {
+		return fmt.Errorf("creating temp dir error: %w", err)
+	}
+
+	defer func() {
+		if removeErr := os.RemoveAll(tempDir); removeErr != nil {
+			log.Printf("temp dir removing error: %s", removeErr.Error())
+		}
+	}()
+
+	collectionPath := filepath.Join(tempDir, ankiCollectionFile)
+
+	if err = writeAnkiCollection(collectionPath, a.deck, noteTypes); err != nil {
+		return err
+	}
+
+	collection, err := os.ReadFile(collectionPath)
+	if err != nil {
+		return fmt.Errorf("reading collection error: %w", err)
+	}
+
+	mediaMap := make(map[string]string, len(media.names))
+	for i, name := range media.names {
+		mediaMap[strconv.Itoa(i)] = name
+	}
+
+	mediaJSON, err := json.Marshal(mediaMap)
+	if err != nil {
+		return fmt.Errorf("media map encoding error: %w", err)
+	}
+
+	files := map[string][]byte{ankiCollectionFile: collection, ankiMediaFile: mediaJSON}
+	for i, name := range media.names {
+		files[strconv.Itoa(i)] = media.content[name]
+	}
+
+	return writeZip(packagePath, files)
+}
+
+func writeZip(zipPath string, files map[string][]byte) (err error) {
+	file, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("creating file `%s` error: %w", zipPath, err)
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("closing file `%s` error: %w", zipPath, closeErr)
+		}
+	}()
+
+	zipWriter := zip.NewWriter(file)
+
+	for name, content := range files {
+		entry, err := zipWriter.Create(name)
+		if err != nil {
+			return fmt.Errorf("creating zip entry `%s` error: %w", name, err)
+		}
+
+		if _, err = entry.Write(content); err != nil {
+			return fmt.Errorf("writing zip entry `%s` error: %w", name, err)
+		}
+	}
+
+	if err = zipWriter.Close(); err != nil {
+		return fmt.Errorf("closing zip error: %w", err)
+	}
+
+	return nil
+}
+
+// ankiWordFields renders the fields of ankiWordNoteType, the media names map URLs to the fetched media.
+func ankiWordFields(word *domain.Word, mediaNames map[string]string) []string {
+	return []string{
+		html.EscapeString(word.Word()),
+		ankiPronunciationField(word.Pronunciation(), mediaNames),
+		ankiDefinitionsField(word),
+		ankiExamplesField(word.Examples()),
+		ankiImagesField(word.ImageURLs(), mediaNames),
+		html.EscapeString(word.SourceSentence()),
+	}
+}
+
+// ankiClozeFields renders the fields of ankiClozeNoteType, every cloze is a separate deletion, so it gets its own card.
+func ankiClozeFields(word *domain.Word) []string {
+	texts := make([]string, len(word.Clozes()))
+
+	for i, cloze := range word.Clozes() {
+		texts[i] = html.EscapeString(cloze.Format(func(answer string) string {
+			return fmt.Sprintf("{{c%d::%s}}", i+1, answer)
+		}))
+	}
+
+	return []string{strings.Join(texts, "<br>"), html.EscapeString(word.Word()), ankiDefinitionsField(word)}
+}
+
+func ankiPronunciationField(pronunciation *domain.Pronunciation, mediaNames map[string]string) string {
+	if pronunciation == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, 3) //nolint:gomnd
+	if pronunciation.IPA() != "" {
+		parts = append(parts, html.EscapeString("/"+strings.Trim(pronunciation.IPA(), "/")+"/"))
+	}
+
+	if len(pronunciation.Syllables()) > 0 {
+		parts = append(parts, html.EscapeString(strings.Join(pronunciation.Syllables(), "·")))
+	}
+
+	if name, exists := mediaNames[pronunciation.AudioURL()]; exists {
+		parts = append(parts, "[sound:"+name+"]")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func ankiDefinitionsField(word *domain.Word) string {
+	var field strings.Builder
+
+	if word.Translation() != "" {
+		field.WriteString(`<div class="translation"><b>` + html.EscapeString(word.Translation()) + `</b></div>`)
+	}
+
+	for _, group := range word.DefinitionsByPartOfSpeech() {
+		field.WriteString(`<div class="definitions">`)
+
+		if group.PartOfSpeech() != "" {
+			field.WriteString(`<i>` + html.EscapeString(group.PartOfSpeech()) + `</i>`)
+		}
+
+		field.WriteString(`<ul>`)
+
+		for _, definition := range group.Definitions() {
+			field.WriteString(`<li>` + html.EscapeString(definition.Gloss()))
+
+			if definition.Translation() != "" {
+				field.WriteString(` <span class="translation">— ` + html.EscapeString(definition.Translation()) + `</span>`)
+			}
+
+			if len(definition.Synonyms()) > 0 {
+				field.WriteString(` <span class="related">(synonyms: ` +
+					html.EscapeString(strings.Join(definition.Synonyms(), ", ")) + `)</span>`)
+			}
+
+			if len(definition.Antonyms()) > 0 {
+				field.WriteString(` <span class="related">(antonyms: ` +
+					html.EscapeString(strings.Join(definition.Antonyms(), ", ")) + `)</span>`)
+			}
+
+			field.WriteString(`</li>`)
+		}
+
+		field.WriteString(`</ul></div>`)
+	}
+
+	return field.String()
+}
+
+func ankiExamplesField(examples []string) string {
+	if len(examples) == 0 {
+		return ""
+	}
+
+	var field strings.Builder
+
+	field.WriteString(`<ul class="examples">`)
+
+	for _, example := range examples {
+		field.WriteString(`<li>` + html.EscapeString(example) + `</li>`)
+	}
+
+	field.WriteString(`</ul>`)
+
+	return field.String()
+}
+
+func ankiImagesField(imageURLs []string, mediaNames map[string]string) string {
+	var field strings.Builder
+
+	for _, imageURL := range imageURLs {
+		if name, exists := mediaNames[imageURL]; exists {
+			field.WriteString(`<img src="` + html.EscapeString(name) + `">`)
+		}
+	}
+
+	return field.String()
+}
@@ -0,0 +1,373 @@
+package spacedrepetition_test
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const testAnkiMaxMediaBytes = 16
+
+// ankiPackage is an unzipped Anki package, the media map the file numbers to the media names.
+type ankiPackage struct {
+	files map[string][]byte
+	media map[string]string
+	db    *sql.DB
+}
+
+// ankiNoteRow is a note of the collection with its cards, the fields are separated by `|`.
+type ankiNoteRow struct {
+	guid, noteType, fields, tags string
+	cardDecks                    []string
+}
+
+// newAnkiMediaServer serves the media by the path, the user agents of the requests are recorded.
+func newAnkiMediaServer(t *testing.T, media map[string]string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mu         sync.Mutex
+		userAgents []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		userAgents = append(userAgents, r.UserAgent())
+		mu.Unlock()
+
+		content, exists := media[r.URL.Path]
+		if !exists {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, _ = io.WriteString(w, content)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &userAgents
+}
+
+func newTestAnki(t *testing.T, exportDir string, httpClient *http.Client) *spacedrepetition.Anki {
+	t.Helper()
+
+	anki, err := spacedrepetition.NewAnki(
+		"English words", []string{"vocaboost"}, exportDir, "https://vocaboost.example/exports/", httpClient,
+		"vocaboost-test", testAnkiMaxMediaBytes,
+	)
+	if err != nil {
+		t.Fatalf("creating Anki error: %s", err)
+	}
+
+	return anki
+}
+
+func newAnkiTestWords(t *testing.T, mediaURL string) []*domain.Word {
+	t.Helper()
+
+	imagePath := filepath.Join(t.TempDir(), "cat.png")
+	if err := os.WriteFile(imagePath, []byte("local png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cat := domain.NewWord(
+		"cat",
+		"кошка",
+		"The cat sat on the mat.",
+		[]*domain.Definition{domain.NewDefinition("noun", "a small animal", "", nil, nil)},
+		[]string{"a black cat"},
+		[]*domain.Image{
+			domain.NewImage(mediaURL+"/cat.jpg", ""),
+			domain.NewImage("https://vocaboost.example/images/cat.png", imagePath),
+			domain.NewImage(mediaURL+"/missing.jpg", ""),
+			domain.NewImage(mediaURL+"/large.jpg", ""),
+		},
+		domain.NewPronunciation("kæt", nil, mediaURL+"/audio?word=cat", ""),
+		0,
+		[]string{"animals home"},
+	).WithClozes([]*domain.Cloze{
+		domain.NewCloze([]string{"The ", " sat."}, []string{"cat"}),
+		domain.NewCloze([]string{"A black ", "."}, []string{"cat"}),
+	})
+	// The dog shares the image with the cat, the image is packed once.
+	dog := domain.NewWord(
+		"dog", "", "", nil, nil, []*domain.Image{domain.NewImage(mediaURL+"/cat.jpg", "")}, nil, 0, nil,
+	)
+
+	return []*domain.Word{cat, dog}
+}
+
+// uploadAnkiPackage uploads the words and opens the package written at the download URL of the report.
+func uploadAnkiPackage(t *testing.T, anki *spacedrepetition.Anki, exportDir string, words []*domain.Word) *ankiPackage {
+	t.Helper()
+
+	reports, err := anki.UploadWords(context.Background(), words)
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	for _, result := range reports[0].Words() {
+		if result.Status() != spacedrepetition.UploadStatusCreated {
+			t.Errorf("got word `%s` status `%s`, want created", result.Word(), result.Status())
+		}
+	}
+
+	downloadURL, err := url.Parse(reports[0].DownloadURL())
+	if err != nil || path.Dir(downloadURL.Path) != "/exports" || path.Ext(downloadURL.Path) != ".apkg" {
+		t.Fatalf("got download URL `%s`, want a package in the exports", reports[0].DownloadURL())
+	}
+
+	return openAnkiPackage(t, filepath.Join(exportDir, path.Base(downloadURL.Path)))
+}
+
+func openAnkiPackage(t *testing.T, packagePath string) *ankiPackage {
+	t.Helper()
+
+	zipReader, err := zip.OpenReader(packagePath)
+	if err != nil {
+		t.Fatalf("opening package error: %s", err)
+	}
+
+	defer zipReader.Close()
+
+	apkg := &ankiPackage{files: make(map[string][]byte), media: nil, db: nil}
+
+	for _, file := range zipReader.File {
+		entry, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if apkg.files[file.Name], err = io.ReadAll(entry); err != nil {
+			t.Fatal(err)
+		}
+
+		_ = entry.Close()
+	}
+
+	if err = json.Unmarshal(apkg.files["media"], &apkg.media); err != nil {
+		t.Fatalf("decoding media map error: %s", err)
+	}
+
+	collectionPath := filepath.Join(t.TempDir(), "collection.anki2")
+	if err = os.WriteFile(collectionPath, apkg.files["collection.anki2"], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if apkg.db, err = sql.Open("sqlite", collectionPath); err != nil {
+		t.Fatalf("opening collection error: %s", err)
+	}
+
+	t.Cleanup(func() { _ = apkg.db.Close() })
+
+	return apkg
+}
+
+// notes reads the notes ordered by the note type and the sort field, with the names of the note types
+// and the decks of the cards taken from the collection.
+func (p *ankiPackage) notes(t *testing.T) []ankiNoteRow {
+	t.Helper()
+
+	var modelsJSON, decksJSON string
+	if err := p.db.QueryRow(`SELECT models, decks FROM col`).Scan(&modelsJSON, &decksJSON); err != nil {
+		t.Fatalf("reading collection error: %s", err)
+	}
+
+	var models, decks map[string]struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(modelsJSON), &models); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal([]byte(decksJSON), &decks); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := p.db.Query(`SELECT id, guid, mid, flds, tags FROM notes ORDER BY mid, sfld`)
+	if err != nil {
+		t.Fatalf("reading notes error: %s", err)
+	}
+	defer rows.Close()
+
+	notes := make([]ankiNoteRow, 0)
+	noteIDs := make([]int64, 0)
+
+	for rows.Next() {
+		var (
+			note    ankiNoteRow
+			noteID  int64
+			modelID string
+		)
+		if err = rows.Scan(&noteID, &note.guid, &modelID, &note.fields, &note.tags); err != nil {
+			t.Fatal(err)
+		}
+
+		note.noteType = models[modelID].Name
+		note.fields = strings.ReplaceAll(note.fields, "\x1f", "|")
+		notes = append(notes, note)
+		noteIDs = append(noteIDs, noteID)
+	}
+
+	if err = rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, noteID := range noteIDs {
+		cards, err := p.db.Query(`SELECT did FROM cards WHERE nid = ? ORDER BY ord`, noteID)
+		if err != nil {
+			t.Fatalf("reading cards error: %s", err)
+		}
+
+		for cards.Next() {
+			var deckID string
+			if err = cards.Scan(&deckID); err != nil {
+				t.Fatal(err)
+			}
+
+			notes[i].cardDecks = append(notes[i].cardDecks, decks[deckID].Name)
+		}
+
+		_ = cards.Close()
+	}
+
+	return notes
+}
+
+func TestAnkiUploadWordsWritesPackage(t *testing.T) {
+	t.Parallel()
+
+	server, userAgents := newAnkiMediaServer(t, map[string]string{
+		"/cat.jpg":   "remote jpg",
+		"/audio":     "remote mp3",
+		"/large.jpg": strings.Repeat("x", testAnkiMaxMediaBytes+1),
+	})
+	exportDir := filepath.Join(t.TempDir(), "exports")
+	apkg := uploadAnkiPackage(t, newTestAnki(t, exportDir, server.Client()), exportDir, newAnkiTestWords(t, server.URL))
+
+	// The missing and the too large images are left out, the shared image is packed once.
+	mediaContent := make([]string, 0, len(apkg.media))
+	fileNames := []string{"collection.anki2", "media"}
+
+	for number, name := range apkg.media {
+		fileNames = append(fileNames, number)
+		mediaContent = append(mediaContent, path.Ext(name)+" "+string(apkg.files[number]))
+
+		if !strings.HasPrefix(name, "vocaboost-") {
+			t.Errorf("got media name `%s`, want it prefixed", name)
+		}
+	}
+
+	sort.Strings(mediaContent)
+
+	if want := []string{".jpg remote jpg", ".mp3 remote mp3", ".png local png"}; !reflect.DeepEqual(mediaContent, want) {
+		t.Errorf("got media %q, want %q", mediaContent, want)
+	}
+
+	zipNames := make([]string, 0, len(apkg.files))
+	for name := range apkg.files {
+		zipNames = append(zipNames, name)
+	}
+
+	sort.Strings(fileNames)
+	sort.Strings(zipNames)
+
+	if !reflect.DeepEqual(zipNames, fileNames) {
+		t.Errorf("got package files %q, want %q", zipNames, fileNames)
+	}
+
+	for _, userAgent := range *userAgents {
+		if userAgent != "vocaboost-test" {
+			t.Errorf("got media requested by `%s`, want the user agent", userAgent)
+		}
+	}
+
+	notes := apkg.notes(t)
+	if len(notes) != 3 {
+		t.Fatalf("got %d notes, want the cat and the dog words and the cat clozes", len(notes))
+	}
+
+	cat, dog, clozes := notes[0], notes[1], notes[2]
+	if cat.noteType != "Vocaboost Word" || !strings.HasPrefix(cat.fields, "cat|/kæt/ [sound:vocaboost-") ||
+		strings.Count(cat.fields, `<img src="vocaboost-`) != 2 || !strings.HasSuffix(cat.fields, "|The cat sat on the mat.") {
+		t.Errorf("got the cat note %+v", cat)
+	}
+
+	if cat.tags != " vocaboost animals_home " || !reflect.DeepEqual(cat.cardDecks, []string{"English words"}) {
+		t.Errorf("got the cat tags %q and cards in decks %q", cat.tags, cat.cardDecks)
+	}
+
+	if dog.noteType != "Vocaboost Word" || strings.Count(dog.fields, `<img src="vocaboost-`) != 1 {
+		t.Errorf("got the dog note %+v", dog)
+	}
+
+	if clozes.noteType != "Vocaboost Cloze" ||
+		!strings.HasPrefix(clozes.fields, "The {{c1::cat}} sat.<br>A black {{c2::cat}}.|cat|") ||
+		!reflect.DeepEqual(clozes.cardDecks, []string{"English words", "English words"}) {
+		t.Errorf("got the clozes note %+v, want a card per cloze", clozes)
+	}
+}
+
+func TestAnkiUploadWordsKeepsGUIDsStable(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newAnkiMediaServer(t, map[string]string{"/cat.jpg": "remote jpg", "/audio": "remote mp3"})
+	words := newAnkiTestWords(t, server.URL)
+
+	firstDir, secondDir := t.TempDir(), t.TempDir()
+	first := uploadAnkiPackage(t, newTestAnki(t, firstDir, server.Client()), firstDir, words)
+	second := uploadAnkiPackage(t, newTestAnki(t, secondDir, server.Client()), secondDir, words[:1])
+
+	firstNotes, secondNotes := first.notes(t), second.notes(t)
+	guids := map[string]bool{}
+
+	for _, note := range firstNotes {
+		guids[note.guid] = true
+	}
+
+	if len(guids) != len(firstNotes) {
+		t.Errorf("got GUIDs %v, want a GUID per note", guids)
+	}
+
+	// The cat notes of the second package update the ones imported from the first one.
+	if firstNotes[0].guid != secondNotes[0].guid || firstNotes[2].guid != secondNotes[1].guid {
+		t.Errorf("got the cat GUIDs %q and %q, want them stable",
+			[]string{firstNotes[0].guid, firstNotes[2].guid}, []string{secondNotes[0].guid, secondNotes[1].guid})
+	}
+
+	if firstNotes[0].fields != secondNotes[0].fields {
+		t.Errorf("got the cat fields %q and %q, want the same media names", firstNotes[0].fields, secondNotes[0].fields)
+	}
+}
+
+func TestNewAnkiFailsWithoutExportDir(t *testing.T) {
+	t.Parallel()
+
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := spacedrepetition.NewAnki(
+		"English words", nil, filepath.Join(notDir, "exports"), "", http.DefaultClient, "", testAnkiMaxMediaBytes,
+	); err == nil {
+		t.Error("got no error, want one when the export dir can't be created")
+	}
+}
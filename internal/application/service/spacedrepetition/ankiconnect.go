@@ -0,0 +1,304 @@
+package spacedrepetition
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	ankiConnectTarget  = "AnkiConnect"
+	ankiConnectVersion = 6
+)
+
+var errAnkiConnect = errors.New("AnkiConnect error")
+
+// AnkiConnect adds the words as notes to a running Anki through the AnkiConnect add-on.
+// The deck and the note type are created if they are missing, words which already have a note are skipped.
+type AnkiConnect struct {
+	httpClient *http.Client
+	url        string
+	deckName   string
+	tags       []string
+}
+
+func NewAnkiConnect(httpClient *http.Client, url, deckName string, tags []string) *AnkiConnect {
+	return &AnkiConnect{httpClient: httpClient, url: url, deckName: deckName, tags: tags}
+}
+
+type ankiConnectRequest struct {
+	Action  string      `json:"action"`
+	Version int         `json:"version"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type ankiConnectResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *string         `json:"error"`
+}
+
+type ankiConnectCardTemplate struct {
+	Name  string `json:"Name"`  //nolint:tagliatelle
+	Front string `json:"Front"` //nolint:tagliatelle
+	Back  string `json:"Back"`  //nolint:tagliatelle
+}
+
+type ankiConnectNote struct {
+	DeckName  string                 `json:"deckName"`  //nolint:tagliatelle
+	ModelName string                 `json:"modelName"` //nolint:tagliatelle
+	Fields    map[string]string      `json:"fields"`
+	Tags      []string               `json:"tags"`
+	Options   ankiConnectNoteOptions `json:"options"`
+}
+
+type ankiConnectNoteOptions struct {
+	AllowDuplicate bool   `json:"allowDuplicate"` //nolint:tagliatelle
+	DuplicateScope string `json:"duplicateScope"` //nolint:tagliatelle
+}
+
+func (ac AnkiConnect) Name() string {
+	return ankiConnectTarget
+}
+
+func (ac AnkiConnect) UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	if err := ac.prepareDeck(ctx, words); err != nil {
+		return nil, err
+	}
+
+	results := make([]*WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		status, err := ac.addWord(ctx, word)
+		if err != nil {
+			log.Printf("adding word `%s` to Anki error: %s", word.Word(), err)
+
+			status = UploadStatusFailed
+		}
+
+		results[i] = NewWordUploadResultDTO(word.Word(), status, err)
+	}
+
+	return []*UploadReportDTO{NewUploadReportDTO(ankiConnectTarget, results, "")}, nil
+}
+
+// prepareDeck creates the deck and the note types, creating an existing deck is a no-op in AnkiConnect.
+// The cloze note type is created only if some word has clozes.
+func (ac AnkiConnect) prepareDeck(ctx context.Context, words []*domain.Word) error {
+	if err := ac.call(ctx, "createDeck", map[string]string{"deck": ac.deckName}, nil); err != nil {
+		return fmt.Errorf("creating deck `%s` error: %w", ac.deckName, err)
+	}
+
+	var existingNoteTypes []string
+	if err := ac.call(ctx, "modelNames", nil, &existingNoteTypes); err != nil {
+		return fmt.Errorf("getting note types error: %w", err)
+	}
+
+	noteTypes := []*ankiNoteType{ankiWordNoteType}
+
+	for _, word := range words {
+		if len(word.Clozes()) > 0 {
+			noteTypes = append(noteTypes, ankiClozeNoteType)
+
+			break
+		}
+	}
+
+	for _, noteType := range noteTypes {
+		if err := ac.createNoteType(ctx, noteType, existingNoteTypes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ac AnkiConnect) createNoteType(ctx context.Context, noteType *ankiNoteType, existingNoteTypes []string) error {
+	for _, existingNoteType := range existingNoteTypes {
+		if existingNoteType == noteType.name {
+			return nil
+		}
+	}
+
+	templates := make([]ankiConnectCardTemplate, len(noteType.templates))
+	for i, template := range noteType.templates {
+		templates[i] = ankiConnectCardTemplate{Name: fmt.Sprintf("Card %d", i+1), Front: template[0], Back: template[1]}
+	}
+
+	if err := ac.call(ctx, "createModel", map[string]interface{}{
+		"modelName":     noteType.name,
+		"inOrderFields": noteType.fields,
+		"css":           noteType.css,
+		"isCloze":       noteType.cloze,
+		"cardTemplates": templates,
+	}, nil); err != nil {
+		return fmt.Errorf("creating note type `%s` error: %w", noteType.name, err)
+	}
+
+	return nil
+}
+
+// addWord adds the word note and the cloze note of the word, the word is skipped if both notes exist.
+func (ac AnkiConnect) addWord(ctx context.Context, word *domain.Word) (UploadStatus, error) {
+	wordNoteExists, err := ac.noteExists(ctx, ankiWordNoteType, word.Word())
+	if err != nil {
+		return "", err
+	}
+
+	status := UploadStatusSkipped
+
+	if !wordNoteExists {
+		if err = ac.addNote(ctx, ankiWordNoteType, word, ankiWordFields(word, ac.storeMedia(ctx, word))); err != nil {
+			return "", err
+		}
+
+		status = UploadStatusCreated
+	}
+
+	if len(word.Clozes()) == 0 {
+		return status, nil
+	}
+
+	clozeNoteExists, err := ac.noteExists(ctx, ankiClozeNoteType, word.Word())
+	if err != nil || clozeNoteExists {
+		return status, err
+	}
+
+	if err = ac.addNote(ctx, ankiClozeNoteType, word, ankiClozeFields(word)); err != nil {
+		return "", err
+	}
+
+	return UploadStatusCreated, nil
+}
+
+func (ac AnkiConnect) noteExists(ctx context.Context, noteType *ankiNoteType, word string) (bool, error) {
+	var noteIDs []int64
+	if err := ac.call(ctx, "findNotes", map[string]string{"query": ac.wordQuery(noteType, word)}, &noteIDs); err != nil {
+		return false, fmt.Errorf("finding notes error: %w", err)
+	}
+
+	return len(noteIDs) > 0, nil
+}
+
+func (ac AnkiConnect) addNote(ctx context.Context, noteType *ankiNoteType, word *domain.Word, fieldValues []string) error {
+	fields := make(map[string]string, len(fieldValues))
+	for i, value := range fieldValues {
+		fields[noteType.fields[i]] = value
+	}
+
+	var noteID int64
+	if err := ac.call(ctx, "addNote", map[string]ankiConnectNote{"note": {
+		DeckName:  ac.deckName,
+		ModelName: noteType.name,
+		Fields:    fields,
+		Tags:      ankiNoteTags(ac.tags, word.Tags()),
+		Options:   ankiConnectNoteOptions{AllowDuplicate: false, DuplicateScope: "deck"},
+	}}, &noteID); err != nil {
+		return fmt.Errorf("adding note error: %w", err)
+	}
+
+	return nil
+}
+
+// wordQuery finds notes of the word in the deck, quotes and wildcards are escaped for Anki search.
+func (ac AnkiConnect) wordQuery(noteType *ankiNoteType, word string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `*`, `\*`, `_`, `\_`).Replace
+
+	return fmt.Sprintf(
+		`"deck:%s" "note:%s" "Word:%s"`,
+		escape(ac.deckName), escape(noteType.name), escape(word),
+	)
+}
+
+// storeMedia stores the word images and audio in the Anki media folder, the result maps URLs to media names.
+// Local files are sent as they are, others are downloaded by Anki. Media which can't be stored are left out
+// of the note.
+func (ac AnkiConnect) storeMedia(ctx context.Context, word *domain.Word) map[string]string {
+	sources := ankiMediaSources(word)
+	names := make(map[string]string, len(sources))
+
+	for _, source := range sources {
+		params := map[string]string{"filename": ankiMediaName(source)}
+
+		if source.filePath != "" {
+			content, err := os.ReadFile(source.filePath)
+			if err != nil {
+				log.Printf("media `%s` of word `%s` is left out: %s", source.url, word.Word(), err)
+
+				continue
+			}
+
+			params["data"] = base64.StdEncoding.EncodeToString(content)
+		} else {
+			params["url"] = source.url
+		}
+
+		var name string
+		if err := ac.call(ctx, "storeMediaFile", params, &name); err != nil {
+			log.Printf("media `%s` of word `%s` is left out: %s", source.url, word.Word(), err)
+
+			continue
+		}
+
+		names[source.url] = name
+	}
+
+	return names
+}
+
+// call runs the AnkiConnect action, the result is decoded into the result argument unless it's nil.
+func (ac AnkiConnect) call(ctx context.Context, action string, params, result interface{}) error {
+	body, err := json.Marshal(ankiConnectRequest{Action: action, Version: ankiConnectVersion, Params: params})
+	if err != nil {
+		return fmt.Errorf("request encoding error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ac.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creation request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ac.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request execution error: %w", err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
+	var response ankiConnectResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("response decoding error: %w", err)
+	}
+
+	if response.Error != nil {
+		return fmt.Errorf("%w: %s", errAnkiConnect, *response.Error)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err = json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("result decoding error: %w", err)
+	}
+
+	return nil
+}
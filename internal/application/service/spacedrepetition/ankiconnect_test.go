@@ -0,0 +1,372 @@
+package spacedrepetition_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// ankiConnectStub keeps decks, note types, media and notes in memory and answers the AnkiConnect actions
+// the target uses. Notes are found by the `"note:<note type>"` and the `"Word:<word>"` terms of the query only.
+type ankiConnectStub struct {
+	mu          sync.Mutex
+	actions     []string
+	decks       map[string]bool
+	models      map[string][]string
+	clozeModels map[string]bool
+	media       map[string]map[string]string
+	notes       []map[string]interface{}
+	failMedia   bool
+}
+
+func newAnkiConnectStub(t *testing.T) (*ankiConnectStub, *httptest.Server) {
+	t.Helper()
+
+	stub := &ankiConnectStub{
+		decks:       map[string]bool{"Default": true},
+		models:      map[string][]string{"Basic": {"Front", "Back"}},
+		clozeModels: map[string]bool{},
+		media:       map[string]map[string]string{},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(server.Close)
+
+	return stub, server
+}
+
+func (s *ankiConnectStub) serve(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Action  string                     `json:"action"`
+		Version int                        `json:"version"`
+		Params  map[string]json.RawMessage `json:"params"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Version != 6 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actions = append(s.actions, request.Action)
+
+	result, errMessage := s.handle(request.Action, request.Params)
+
+	response := map[string]interface{}{"result": result, "error": nil}
+	if errMessage != "" {
+		response["error"] = errMessage
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *ankiConnectStub) handle(action string, params map[string]json.RawMessage) (interface{}, string) {
+	switch action {
+	case "createDeck":
+		var deck string
+		_ = json.Unmarshal(params["deck"], &deck)
+		s.decks[deck] = true
+
+		return 1, ""
+	case "modelNames":
+		names := make([]string, 0, len(s.models))
+		for name := range s.models {
+			names = append(names, name)
+		}
+
+		return names, ""
+	case "createModel":
+		var name string
+
+		var fields []string
+
+		var isCloze bool
+
+		_ = json.Unmarshal(params["modelName"], &name)
+		_ = json.Unmarshal(params["inOrderFields"], &fields)
+		_ = json.Unmarshal(params["isCloze"], &isCloze)
+		s.models[name] = fields
+		s.clozeModels[name] = isCloze
+
+		return map[string]interface{}{"name": name}, ""
+	case "storeMediaFile":
+		file := map[string]string{}
+		for key, value := range params {
+			var str string
+			_ = json.Unmarshal(value, &str)
+			file[key] = str
+		}
+
+		if s.failMedia {
+			return nil, "media storing failed"
+		}
+
+		s.media[file["filename"]] = file
+
+		return file["filename"], ""
+	case "findNotes":
+		var query string
+		_ = json.Unmarshal(params["query"], &query)
+
+		ids := make([]int, 0)
+
+		for i, note := range s.notes {
+			fields, _ := note["fields"].(map[string]interface{})
+			if strings.Contains(query, `"note:`+note["modelName"].(string)+`"`) &&
+				strings.Contains(query, `"Word:`+fields["Word"].(string)+`"`) {
+				ids = append(ids, i+1)
+			}
+		}
+
+		return ids, ""
+	case "addNote":
+		var note map[string]interface{}
+		_ = json.Unmarshal(params["note"], &note)
+
+		if !s.decks[note["deckName"].(string)] {
+			return nil, "deck was not found"
+		}
+
+		if _, exists := s.models[note["modelName"].(string)]; !exists {
+			return nil, "model was not found"
+		}
+
+		s.notes = append(s.notes, note)
+
+		return len(s.notes), ""
+	default:
+		return nil, "unsupported action"
+	}
+}
+
+func newTestWord(t *testing.T, word string, tags []string) *domain.Word {
+	t.Helper()
+
+	imagePath := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(imagePath, []byte("png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return domain.NewWord(
+		word,
+		"",
+		"The "+word+" sat on the mat.",
+		[]*domain.Definition{domain.NewDefinition("noun", "a small animal", "", nil, nil)},
+		[]string{"a " + word + " example"},
+		[]*domain.Image{
+			domain.NewImage("https://images.example/"+word+"/remote.jpg", ""),
+			domain.NewImage("https://vocaboost.example/images/"+word+".png", imagePath),
+		},
+		domain.NewPronunciation("kæt", nil, "https://audio.example/"+word+".mp3", ""),
+		0,
+		tags,
+	)
+}
+
+func TestAnkiConnectUploadWords(t *testing.T) {
+	t.Parallel()
+
+	stub, server := newAnkiConnectStub(t)
+	ankiConnect := spacedrepetition.NewAnkiConnect(server.Client(), server.URL, "English words", []string{"vocaboost"})
+
+	reports, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{
+		newTestWord(t, "cat", []string{"animals home"}),
+		newTestWord(t, "dog", nil),
+	})
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	if !stub.decks["English words"] {
+		t.Error("the deck wasn't created")
+	}
+
+	if fields := stub.models["Vocaboost Word"]; len(fields) == 0 || fields[0] != "Word" {
+		t.Errorf("the note type wasn't created as expected: %v", fields)
+	}
+
+	if len(reports) != 1 || len(reports[0].Words()) != 2 {
+		t.Fatalf("unexpected reports: %v", reports)
+	}
+
+	for _, result := range reports[0].Words() {
+		if result.Status() != spacedrepetition.UploadStatusCreated {
+			t.Errorf("word `%s` status is `%s`, error: %v", result.Word(), result.Status(), result.Err())
+		}
+	}
+
+	if len(stub.notes) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(stub.notes))
+	}
+
+	note := stub.notes[0]
+	fields, _ := note["fields"].(map[string]interface{})
+
+	if fields["Context"] != "The cat sat on the mat." {
+		t.Errorf("unexpected context field: %v", fields["Context"])
+	}
+
+	if tags, _ := note["tags"].([]interface{}); len(tags) != 2 || tags[0] != "vocaboost" || tags[1] != "animals_home" {
+		t.Errorf("unexpected tags: %v", note["tags"])
+	}
+
+	var mediaFields strings.Builder
+
+	for _, note := range stub.notes {
+		noteFields, _ := note["fields"].(map[string]interface{})
+		mediaFields.WriteString(noteFields["Images"].(string) + noteFields["Pronunciation"].(string))
+	}
+
+	// Remote images and audio are downloaded by Anki, local files are sent as they are.
+	var remoteImages, localImages, audio int
+
+	for name, file := range stub.media {
+		switch {
+		case file["url"] != "" && strings.HasSuffix(name, ".jpg"):
+			remoteImages++
+		case file["data"] != "" && strings.HasSuffix(name, ".png"):
+			localImages++
+		case file["url"] != "" && strings.HasSuffix(name, ".mp3"):
+			audio++
+		}
+
+		if !strings.Contains(mediaFields.String(), name) {
+			t.Errorf("media `%s` isn't referenced by the notes", name)
+		}
+	}
+
+	if remoteImages != 2 || localImages != 2 || audio != 2 {
+		t.Errorf("unexpected media: %v", stub.media)
+	}
+}
+
+func TestAnkiConnectUploadWordsSkipsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	stub, server := newAnkiConnectStub(t)
+	ankiConnect := spacedrepetition.NewAnkiConnect(server.Client(), server.URL, "English words", nil)
+
+	if _, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{newTestWord(t, "cat", nil)}); err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	reports, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{
+		newTestWord(t, "cat", nil),
+		newTestWord(t, "dog", nil),
+	})
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	statuses := map[string]spacedrepetition.UploadStatus{}
+	for _, result := range reports[0].Words() {
+		statuses[result.Word()] = result.Status()
+	}
+
+	if statuses["cat"] != spacedrepetition.UploadStatusSkipped || statuses["dog"] != spacedrepetition.UploadStatusCreated {
+		t.Errorf("unexpected statuses: %v", statuses)
+	}
+
+	if len(stub.notes) != 2 {
+		t.Errorf("expected 2 notes, got %d", len(stub.notes))
+	}
+
+	createModels := 0
+
+	for _, action := range stub.actions {
+		if action == "createModel" {
+			createModels++
+		}
+	}
+
+	if createModels != 1 {
+		t.Errorf("the note type was created %d times", createModels)
+	}
+}
+
+func TestAnkiConnectUploadWordsAddsClozes(t *testing.T) {
+	t.Parallel()
+
+	stub, server := newAnkiConnectStub(t)
+	ankiConnect := spacedrepetition.NewAnkiConnect(server.Client(), server.URL, "English words", nil)
+
+	cat := newTestWord(t, "cat", nil).WithClozes([]*domain.Cloze{
+		domain.NewCloze([]string{"The ", " sat on the mat."}, []string{"cat"}),
+		domain.NewCloze([]string{"", " & dogs"}, []string{"Cats"}),
+	})
+
+	if _, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{cat}); err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	if !stub.clozeModels["Vocaboost Cloze"] {
+		t.Fatal("the cloze note type wasn't created")
+	}
+
+	if len(stub.notes) != 2 {
+		t.Fatalf("expected a word note and a cloze note, got %d notes", len(stub.notes))
+	}
+
+	fields, _ := stub.notes[1]["fields"].(map[string]interface{})
+	if fields["Text"] != "The {{c1::cat}} sat on the mat.<br>{{c2::Cats}} &amp; dogs" || fields["Word"] != "cat" {
+		t.Errorf("unexpected cloze note fields: %v", fields)
+	}
+
+	reports, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{cat})
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	if status := reports[0].Words()[0].Status(); status != spacedrepetition.UploadStatusSkipped || len(stub.notes) != 2 {
+		t.Errorf("the word was uploaded again with status `%s`", status)
+	}
+}
+
+func TestAnkiConnectUploadWordsLeavesOutFailedMedia(t *testing.T) {
+	t.Parallel()
+
+	stub, server := newAnkiConnectStub(t)
+	stub.failMedia = true
+	ankiConnect := spacedrepetition.NewAnkiConnect(server.Client(), server.URL, "English words", nil)
+
+	reports, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{newTestWord(t, "cat", nil)})
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	if status := reports[0].Words()[0].Status(); status != spacedrepetition.UploadStatusCreated {
+		t.Fatalf("unexpected status `%s`", status)
+	}
+
+	fields, _ := stub.notes[0]["fields"].(map[string]interface{})
+	if fields["Images"] != "" || strings.Contains(fields["Pronunciation"].(string), "[sound:") {
+		t.Errorf("failed media are referenced: %v", fields)
+	}
+}
+
+func TestAnkiConnectUploadWordsFailsWithoutAnki(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	ankiConnect := spacedrepetition.NewAnkiConnect(server.Client(), server.URL, "English words", nil)
+
+	if _, err := ankiConnect.UploadWords(context.Background(), []*domain.Word{newTestWord(t, "cat", nil)}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,294 @@
+package spacedrepetition
+
+import (
+	"crypto/sha1" //nolint:gosec // Anki checksums are sha1 based.
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the pure Go SQLite driver, so builds need no cgo
+)
+
+// ankiCollectionSchema is the legacy collection schema (version 11), every Anki version imports it.
+const ankiCollectionSchema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null, scm integer not null, ver integer not null,
+	dty integer not null, usn integer not null, ls integer not null, conf text not null, models text not null,
+	decks text not null, dconf text not null, tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null, mod integer not null, usn integer not null,
+	tags text not null, flds text not null, sfld integer not null, csum integer not null, flags integer not null,
+	data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null, ord integer not null, mod integer not null,
+	usn integer not null, type integer not null, queue integer not null, due integer not null, ivl integer not null,
+	factor integer not null, reps integer not null, lapses integer not null, left integer not null,
+	odue integer not null, odid integer not null, flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null, ease integer not null, ivl integer not null,
+	lastIvl integer not null, factor integer not null, time integer not null, type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn on notes (usn);
+CREATE INDEX ix_cards_usn on cards (usn);
+CREATE INDEX ix_revlog_usn on revlog (usn);
+CREATE INDEX ix_cards_nid on cards (nid);
+CREATE INDEX ix_cards_sched on cards (did, queue, due);
+CREATE INDEX ix_revlog_cid on revlog (cid);
+CREATE INDEX ix_notes_csum on notes (csum);
+`
+
+const (
+	ankiSchemaVersion   = 11
+	ankiFieldSeparator  = "\x1f"
+	ankiDefaultDeckID   = 1
+	ankiDefaultConfigID = 1
+
+	ankiCollectionConf = `{"activeDecks":[1],"curDeck":1,"newSpread":0,"collapseTime":1200,"timeLim":0,` +
+		`"estTimes":true,"dueCounts":true,"curModel":null,"nextPos":1,"sortType":"noteFld",` +
+		`"sortBackwards":false,"addToCur":true}`
+	ankiDeckConf = `{"1":{"id":1,"name":"Default","replayq":true,"timer":0,"maxTaken":60,"usn":0,"mod":0,` +
+		`"autoplay":true,"lapse":{"leechFails":8,"minInt":1,"delays":[10],"leechAction":0,"mult":0},` +
+		`"rev":{"perDay":100,"fuzz":0.05,"ivlFct":1,"maxIvl":36500,"ease4":1.3,"bury":true,"minSpace":1},` +
+		`"new":{"perDay":20,"delays":[1,10],"separate":true,"ints":[1,4,7],"initialFactor":2500,"bury":true,` +
+		`"order":1}}}`
+)
+
+var ankiHTMLTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// ankiNoteType is a note type, the ID is fixed, so imports of later packages reuse the note type.
+type ankiNoteType struct {
+	id     int64
+	name   string
+	fields []string
+	// templates are pairs of the question and the answer formats, one card is generated per template.
+	templates [][2]string
+	css       string
+	// cloze note types get a card per cloze deletion of the note instead of a card per template.
+	cloze bool
+}
+
+// ankiNote is a note, the GUID is stable for the word, so reimporting the word updates the note
+// instead of adding a duplicate.
+type ankiNote struct {
+	guid   string
+	fields []string
+	tags   []string
+	// clozes is the number of cloze deletions, it's zero for notes of regular note types.
+	clozes int
+}
+
+type ankiDeck struct {
+	id   int64
+	name string
+}
+
+// ankiStableID derives a positive ID from the name, so the same deck or note type gets the same ID in every package.
+func ankiStableID(name string) int64 {
+	hash := sha1.Sum([]byte(name)) //nolint:gosec
+
+	return int64(binary.BigEndian.Uint64(hash[:8]) >> 12) //nolint:gomnd // keeps IDs in the JavaScript safe range.
+}
+
+// ankiGUID is stable per word, Anki matches imported notes with existing ones by it.
+func ankiGUID(noteTypeName, word string) string {
+	hash := sha1.Sum([]byte(noteTypeName + ":" + word)) //nolint:gosec
+
+	return hex.EncodeToString(hash[:10])
+}
+
+// writeAnkiCollection writes the notes into a new collection database at the path.
+func writeAnkiCollection(path string, deck ankiDeck, noteTypes map[*ankiNoteType][]ankiNote) (err error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("opening collection error: %w", err)
+	}
+
+	defer func() {
+		if closeErr := db.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("closing collection error: %w", closeErr)
+		}
+	}()
+
+	if _, err = db.Exec(ankiCollectionSchema); err != nil {
+		return fmt.Errorf("creating collection schema error: %w", err)
+	}
+
+	now := time.Now()
+
+	models, err := ankiModelsJSON(deck, noteTypes, now)
+	if err != nil {
+		return err
+	}
+
+	decks, err := ankiDecksJSON(deck, now)
+	if err != nil {
+		return err
+	}
+
+	if _, err = db.Exec(
+		`INSERT INTO col VALUES (1, ?, ?, ?, ?, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		now.Unix(), now.UnixMilli(), now.UnixMilli(), ankiSchemaVersion, ankiCollectionConf, models, decks, ankiDeckConf,
+	); err != nil {
+		return fmt.Errorf("inserting collection error: %w", err)
+	}
+
+	return insertAnkiNotes(db, deck, noteTypes, now)
+}
+
+func insertAnkiNotes(db *sql.DB, deck ankiDeck, noteTypes map[*ankiNoteType][]ankiNote, now time.Time) error {
+	// IDs are creation times in milliseconds, consecutive ones keep them unique within the package.
+	nextID := now.UnixMilli()
+	position := 0
+
+	for noteType, notes := range noteTypes {
+		for _, note := range notes {
+			noteID := nextID
+			nextID++
+
+			sortField := ankiStripHTML(note.fields[0])
+
+			if _, err := db.Exec(
+				`INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')`,
+				noteID,
+				note.guid,
+				noteType.id,
+				now.Unix(),
+				ankiTags(note.tags),
+				strings.Join(note.fields, ankiFieldSeparator),
+				sortField,
+				ankiChecksum(sortField),
+			); err != nil {
+				return fmt.Errorf("inserting note `%s` error: %w", sortField, err)
+			}
+
+			cards := len(noteType.templates)
+			if noteType.cloze {
+				cards = note.clozes
+			}
+
+			for ord := 0; ord < cards; ord++ {
+				position++
+
+				if _, err := db.Exec(
+					`INSERT INTO cards VALUES (?, ?, ?, ?, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+					nextID, noteID, deck.id, ord, now.Unix(), position,
+				); err != nil {
+					return fmt.Errorf("inserting card of note `%s` error: %w", sortField, err)
+				}
+
+				nextID++
+			}
+		}
+	}
+
+	return nil
+}
+
+func ankiModelsJSON(deck ankiDeck, noteTypes map[*ankiNoteType][]ankiNote, now time.Time) (string, error) {
+	models := make(map[string]interface{}, len(noteTypes))
+
+	for noteType := range noteTypes {
+		fields := make([]map[string]interface{}, len(noteType.fields))
+		for i, name := range noteType.fields {
+			fields[i] = map[string]interface{}{
+				"name": name, "ord": i, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{},
+			}
+		}
+
+		templates := make([]map[string]interface{}, len(noteType.templates))
+		requirements := make([][]interface{}, len(noteType.templates))
+
+		for i, template := range noteType.templates {
+			templates[i] = map[string]interface{}{
+				"name": fmt.Sprintf("Card %d", i+1), "ord": i, "qfmt": template[0], "afmt": template[1],
+				"did": nil, "bqfmt": "", "bafmt": "",
+			}
+			requirements[i] = []interface{}{i, "any", []int{0}}
+		}
+
+		modelType := 0
+		if noteType.cloze {
+			modelType = 1
+		}
+
+		models[strconv.FormatInt(noteType.id, 10)] = map[string]interface{}{
+			"id": noteType.id, "name": noteType.name, "type": modelType, "mod": now.Unix(), "usn": -1, "sortf": 0,
+			"did": deck.id, "tmpls": templates, "flds": fields, "css": noteType.css, "tags": []string{},
+			"vers": []string{}, "req": requirements,
+			"latexPre": "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n" +
+				"\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+		}
+	}
+
+	encoded, err := json.Marshal(models)
+	if err != nil {
+		return "", fmt.Errorf("note types encoding error: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+func ankiDecksJSON(deck ankiDeck, now time.Time) (string, error) {
+	newDeck := func(id int64, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": name, "mod": now.Unix(), "usn": -1, "lrnToday": []int{0, 0}, "revToday": []int{0, 0},
+			"newToday": []int{0, 0}, "timeToday": []int{0, 0}, "collapsed": false, "desc": "", "dyn": 0,
+			"conf": ankiDefaultConfigID, "extendNew": 10, "extendRev": 50,
+		}
+	}
+
+	encoded, err := json.Marshal(map[string]interface{}{
+		strconv.Itoa(ankiDefaultDeckID): newDeck(ankiDefaultDeckID, "Default"),
+		strconv.FormatInt(deck.id, 10):  newDeck(deck.id, deck.name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("decks encoding error: %w", err)
+	}
+
+	return string(encoded), nil
+}
+
+// ankiNoteTags merges the tags, spaces inside tags become underscores as tags are space separated in Anki.
+func ankiNoteTags(tagLists ...[]string) []string {
+	tags := make([]string, 0)
+
+	for _, tagList := range tagLists {
+		for _, tag := range tagList {
+			tags = append(tags, strings.Join(strings.Fields(tag), "_"))
+		}
+	}
+
+	return tags
+}
+
+// ankiTags are surrounded by spaces in the collection.
+func ankiTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(tags, " ") + " "
+}
+
+func ankiStripHTML(field string) string {
+	return html.UnescapeString(ankiHTMLTagRegexp.ReplaceAllString(field, ""))
+}
+
+// ankiChecksum is the first 8 hex digits of the sort field sha1, Anki finds duplicates by it.
+func ankiChecksum(sortField string) int64 {
+	hash := sha1.Sum([]byte(sortField)) //nolint:gosec
+
+	return int64(binary.BigEndian.Uint32(hash[:4]))
+}
@@ -0,0 +1,70 @@
+package spacedrepetition
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+// Fanout uploads the words to all the targets at once and collects the reports of every target.
+// A failed target doesn't stop the others, all its words are reported as failed with the target error.
+type Fanout struct {
+	targets []Interface
+}
+
+func NewFanout(targets ...Interface) *Fanout {
+	return &Fanout{targets: targets}
+}
+
+func (f Fanout) Name() string {
+	names := make([]string, len(f.targets))
+	for i := range f.targets {
+		names[i] = f.targets[i].Name()
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func (f Fanout) UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	targetsReports := make([][]*UploadReportDTO, len(f.targets))
+
+	var waitGroup sync.WaitGroup
+
+	for i, target := range f.targets {
+		waitGroup.Add(1)
+
+		go func(i int, target Interface) {
+			defer waitGroup.Done()
+
+			reports, err := target.UploadWords(ctx, words)
+			if err != nil {
+				log.Printf("uploading words to %s error: %s", target.Name(), err)
+
+				reports = []*UploadReportDTO{failedUploadReport(target.Name(), words, err)}
+			}
+
+			targetsReports[i] = reports
+		}(i, target)
+	}
+
+	waitGroup.Wait()
+
+	allReports := make([]*UploadReportDTO, 0, len(f.targets))
+	for _, reports := range targetsReports {
+		allReports = append(allReports, reports...)
+	}
+
+	return allReports, nil
+}
+
+func failedUploadReport(target string, words []*domain.Word, err error) *UploadReportDTO {
+	results := make([]*WordUploadResultDTO, len(words))
+	for i := range words {
+		results[i] = NewWordUploadResultDTO(words[i].Word(), UploadStatusFailed, err)
+	}
+
+	return NewUploadReportDTO(target, results, "")
+}
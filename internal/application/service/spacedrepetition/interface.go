@@ -6,6 +6,66 @@ import (
 	"github.com/r-erema/vocaboost/internal/domain"
 )
 
+type UploadStatus string
+
+const (
+	UploadStatusCreated  UploadStatus = "created"
+	UploadStatusUpdated  UploadStatus = "updated"
+	UploadStatusAppended UploadStatus = "appended"
+	UploadStatusSkipped  UploadStatus = "skipped"
+	UploadStatusFailed   UploadStatus = "failed"
+)
+
+// WordUploadResultDTO is the outcome of a word upload, the error is set only if the upload failed.
+type WordUploadResultDTO struct {
+	word   string
+	status UploadStatus
+	err    error
+}
+
+func (w WordUploadResultDTO) Word() string {
+	return w.word
+}
+
+func (w WordUploadResultDTO) Status() UploadStatus {
+	return w.status
+}
+
+func (w WordUploadResultDTO) Err() error {
+	return w.err
+}
+
+func NewWordUploadResultDTO(word string, status UploadStatus, err error) *WordUploadResultDTO {
+	return &WordUploadResultDTO{word: word, status: status, err: err}
+}
+
+// UploadReportDTO is the outcome of an upload to a target, the download URL is set if the target is a file to download.
+type UploadReportDTO struct {
+	target      string
+	words       []*WordUploadResultDTO
+	downloadURL string
+}
+
+func (u UploadReportDTO) Target() string {
+	return u.target
+}
+
+func (u UploadReportDTO) Words() []*WordUploadResultDTO {
+	return u.words
+}
+
+func (u UploadReportDTO) DownloadURL() string {
+	return u.downloadURL
+}
+
+func NewUploadReportDTO(target string, words []*WordUploadResultDTO, downloadURL string) *UploadReportDTO {
+	return &UploadReportDTO{target: target, words: words, downloadURL: downloadURL}
+}
+
 type Interface interface {
-	UploadWords(ctx context.Context, words []*domain.Word) error
+	// Name is the target name the reports of the target are under.
+	Name() string
+	// UploadWords keeps going past failures of single words and reports the outcome of every word,
+	// there is a report per target the words went to.
+	UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error)
 }
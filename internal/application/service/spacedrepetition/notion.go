@@ -2,72 +2,483 @@ package spacedrepetition
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jomei/notionapi"
 	"github.com/r-erema/vocaboost/internal/domain"
 )
 
+const blockTypeAudio notionapi.BlockType = "audio"
+
+// audioBlock is missing in the notionapi package.
+type audioBlock struct {
+	notionapi.BasicBlock
+	Audio notionapi.BlockFile `json:"audio"`
+}
+
+// ExistingPages tells what to do with a word which already has a page in the database.
+type ExistingPages string
+
+const (
+	// ExistingPagesSkip leaves the page as it is, so uploading the same words again is harmless.
+	ExistingPagesSkip ExistingPages = "skip"
+	// ExistingPagesUpdate replaces the page blocks with the new material.
+	ExistingPagesUpdate ExistingPages = "update"
+	// ExistingPagesAppend adds the new material below a divider after the page blocks.
+	ExistingPagesAppend ExistingPages = "append"
+)
+
+const (
+	notionTarget = "Notion"
+
+	notionAPIBlockChildrenURL = "https://api.notion.com/v1/blocks/%s/children"
+	// notionAPIVersion is the version the notionapi package uses.
+	notionAPIVersion      = "2022-02-22"
+	notionMaxPageSize     = 100
+	notionStartCursorFlag = "start_cursor"
+)
+
+const (
+	notionMaxTextLength      = 2000
+	notionOptionSeparator    = ","
+	notionOptionSeparatorFix = " "
+)
+
+var (
+	errUnknownExistingPages = errors.New("unknown existing pages mode")
+	errUnexpectedStatus     = errors.New("unexpected response status")
+	errSchemaMismatch       = errors.New("the database schema doesn't match the properties")
+	errBodyNotRewindable    = errors.New("the request body can't be read again for a retry")
+)
+
+// NotionProperties are names of the database properties the word fields go to, empty names aren't filled.
+// The date added and the status are set only on new pages, so the status progress made in Notion is kept.
+// The learned checkbox and the learned statuses aren't filled, they tell which words are learned in Notion.
+type NotionProperties struct {
+	title,
+	partOfSpeech,
+	sourceText,
+	dateAdded,
+	frequencyRank,
+	tags,
+	status,
+	newStatus,
+	learned string
+	learnedStatuses []string
+}
+
+// NewNotionProperties maps the properties, the title is required, the new status is the status of new pages.
+// A word is learned if its learned checkbox is checked or its status is one of the learned statuses.
+func NewNotionProperties(
+	title, partOfSpeech, sourceText, dateAdded, frequencyRank, tags, status, newStatus, learned string,
+	learnedStatuses []string,
+) *NotionProperties {
+	return &NotionProperties{
+		title:           title,
+		partOfSpeech:    partOfSpeech,
+		sourceText:      sourceText,
+		dateAdded:       dateAdded,
+		frequencyRank:   frequencyRank,
+		tags:            tags,
+		status:          status,
+		newStatus:       newStatus,
+		learned:         learned,
+		learnedStatuses: learnedStatuses,
+	}
+}
+
+// types lists the expected type of every mapped property.
+func (np NotionProperties) types() map[string]notionapi.PropertyConfigType {
+	types := map[string]notionapi.PropertyConfigType{np.title: notionapi.PropertyConfigTypeTitle}
+
+	for name, propertyType := range map[string]notionapi.PropertyConfigType{
+		np.partOfSpeech:  notionapi.PropertyConfigTypeMultiSelect,
+		np.sourceText:    notionapi.PropertyConfigTypeRichText,
+		np.dateAdded:     notionapi.PropertyConfigTypeDate,
+		np.frequencyRank: notionapi.PropertyConfigTypeNumber,
+		np.tags:          notionapi.PropertyConfigTypeMultiSelect,
+		np.status:        notionapi.PropertyConfigTypeSelect,
+		np.learned:       notionapi.PropertyConfigTypeCheckbox,
+	} {
+		if name != "" {
+			types[name] = propertyType
+		}
+	}
+
+	return types
+}
+
+func ParseExistingPages(mode string) (ExistingPages, error) {
+	switch existingPages := ExistingPages(mode); existingPages {
+	case ExistingPagesSkip, ExistingPagesUpdate, ExistingPagesAppend:
+		return existingPages, nil
+	default:
+		return "", fmt.Errorf("%w: `%s`", errUnknownExistingPages, mode)
+	}
+}
+
 type Notion struct {
-	client     *notionapi.Client
-	databaseID notionapi.DatabaseID
+	client        *notionapi.Client
+	httpClient    *http.Client
+	databaseID    notionapi.DatabaseID
+	properties    *NotionProperties
+	existingPages ExistingPages
+	layout        *NotionLayout
 }
 
-func NewNotion(client *notionapi.Client, databaseID notionapi.DatabaseID) *Notion {
-	return &Notion{client: client, databaseID: databaseID}
+// NewNotion creates the service, the HTTP client is used for the calls the notionapi package can't make,
+// it should be the one the notionapi client uses, so all the calls share the throttling.
+// The layout sets out the page body.
+func NewNotion(
+	client *notionapi.Client,
+	httpClient *http.Client,
+	databaseID notionapi.DatabaseID,
+	properties *NotionProperties,
+	existingPages ExistingPages,
+	layout *NotionLayout,
+) *Notion {
+	return &Notion{
+		client:        client,
+		httpClient:    httpClient,
+		databaseID:    databaseID,
+		properties:    properties,
+		existingPages: existingPages,
+		layout:        layout,
+	}
 }
 
-func (n Notion) UploadWords(ctx context.Context, words []*domain.Word) error {
-	for _, word := range words {
-		if _, err := n.client.Page.Create(ctx, n.buildPageCreateRequest(word)); err != nil {
-			return fmt.Errorf("the Notion page for word `%s` creation error: %w", word.Word(), err)
+// ValidateSchema checks the database has all the mapped properties of the expected types.
+func (n Notion) ValidateSchema(ctx context.Context) error {
+	database, err := n.client.Database.Get(ctx, n.databaseID)
+	if err != nil {
+		return fmt.Errorf("getting the database error: %w", err)
+	}
+
+	problems := make([]string, 0)
+
+	for name, expectedType := range n.properties.types() {
+		property, exists := database.Properties[name]
+		if !exists {
+			problems = append(problems, fmt.Sprintf("property `%s` of type `%s` is missing", name, expectedType))
+
+			continue
 		}
+
+		if property.GetType() != expectedType {
+			problems = append(problems, fmt.Sprintf(
+				"property `%s` is of type `%s`, expected `%s`", name, property.GetType(), expectedType,
+			))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+
+		return fmt.Errorf("%w: %s", errSchemaMismatch, strings.Join(problems, "; "))
 	}
 
 	return nil
 }
 
-func (n Notion) buildPageCreateRequest(word *domain.Word) *notionapi.PageCreateRequest {
-	blocks := make([]notionapi.Block, 0)
+func (n Notion) Name() string {
+	return notionTarget
+}
+
+func (n Notion) UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	results := make([]*WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		status, err := n.uploadWord(ctx, word)
+		if err != nil {
+			log.Printf("the Notion page for word `%s` uploading error: %s", word.Word(), err)
+
+			status = UploadStatusFailed
+		}
+
+		results[i] = NewWordUploadResultDTO(word.Word(), status, err)
+	}
+
+	return []*UploadReportDTO{NewUploadReportDTO(notionTarget, results, "")}, nil
+}
+
+func (n Notion) uploadWord(ctx context.Context, word *domain.Word) (UploadStatus, error) {
+	pageID, found, err := n.findPage(ctx, word.Word())
+	if err != nil {
+		return "", fmt.Errorf("page search error: %w", err)
+	}
+
+	if !found {
+		if _, err = n.client.Page.Create(ctx, n.buildPageCreateRequest(word)); err != nil {
+			return "", fmt.Errorf("page creation error: %w", err)
+		}
+
+		return UploadStatusCreated, nil
+	}
+
+	switch n.existingPages {
+	case ExistingPagesUpdate:
+		if err = n.updatePage(ctx, pageID, word); err != nil {
+			return "", fmt.Errorf("page update error: %w", err)
+		}
+
+		return UploadStatusUpdated, nil
+	case ExistingPagesAppend:
+		blocks := append([]notionapi.Block{dividerBlock()}, n.layout.blocks(word)...)
+		if err = n.appendBlocks(ctx, pageID, blocks); err != nil {
+			return "", fmt.Errorf("page append error: %w", err)
+		}
+
+		return UploadStatusAppended, nil
+	default:
+		return UploadStatusSkipped, nil
+	}
+}
+
+// findPage looks the word up by the page title, the first page is taken if there are several.
+func (n Notion) findPage(ctx context.Context, word string) (notionapi.BlockID, bool, error) {
+	resp, err := n.client.Database.Query(ctx, n.databaseID, &notionapi.DatabaseQueryRequest{
+		Filter: notionapi.PropertyFilter{ //nolint:exhaustruct
+			Property: n.properties.title,
+			// Notion applies the rich text condition to title properties as well.
+			RichText: &notionapi.TextFilterCondition{Equals: word}, //nolint:exhaustruct
+		},
+		Sorts:       nil,
+		StartCursor: "",
+		PageSize:    1,
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("database query error: %w", err)
+	}
+
+	if len(resp.Results) == 0 {
+		return "", false, nil
+	}
+
+	return notionapi.BlockID(resp.Results[0].ID), true, nil
+}
+
+func (n Notion) updatePage(ctx context.Context, pageID notionapi.BlockID, word *domain.Word) error {
+	if _, err := n.client.Page.Update(ctx, notionapi.PageID(pageID), &notionapi.PageUpdateRequest{
+		Properties: n.pageProperties(word, false),
+		Archived:   false,
+		Icon:       nil,
+		Cover:      nil,
+	}); err != nil {
+		return fmt.Errorf("updating properties error: %w", err)
+	}
+
+	return n.replaceBlocks(ctx, pageID, n.layout.blocks(word))
+}
+
+func (n Notion) replaceBlocks(ctx context.Context, pageID notionapi.BlockID, blocks []notionapi.Block) error {
+	blockIDs, err := n.childBlockIDs(ctx, pageID)
+	if err != nil {
+		return err
+	}
+
+	for _, blockID := range blockIDs {
+		if _, err = n.client.Block.Delete(ctx, blockID); err != nil {
+			return fmt.Errorf("block `%s` deletion error: %w", blockID, err)
+		}
+	}
+
+	return n.appendBlocks(ctx, pageID, blocks)
+}
+
+func (n Notion) appendBlocks(ctx context.Context, pageID notionapi.BlockID, blocks []notionapi.Block) error {
+	if _, err := n.client.Block.AppendChildren(ctx, pageID, &notionapi.AppendBlockChildrenRequest{
+		Children: blocks,
+	}); err != nil {
+		return fmt.Errorf("appending blocks error: %w", err)
+	}
+
+	return nil
+}
+
+type blockChildrenResponse struct {
+	Results []struct {
+		ID notionapi.BlockID `json:"id"`
+	} `json:"results"`
+	HasMore    bool   `json:"has_more"`    //nolint:tagliatelle
+	NextCursor string `json:"next_cursor"` //nolint:tagliatelle
+}
+
+// childBlockIDs lists IDs of the page blocks with a plain API call, since the notionapi package drops IDs
+// of the blocks it doesn't know, e.g. audio.
+func (n Notion) childBlockIDs(ctx context.Context, pageID notionapi.BlockID) ([]notionapi.BlockID, error) {
+	blockIDs := make([]notionapi.BlockID, 0)
+	query := url.Values{"page_size": {strconv.Itoa(notionMaxPageSize)}}
+
+	for {
+		req, err := http.NewRequestWithContext(
+			ctx,
+			http.MethodGet,
+			fmt.Sprintf(notionAPIBlockChildrenURL, pageID)+"?"+query.Encode(),
+			http.NoBody,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creation request error: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+n.client.Token.String())
+		req.Header.Set("Notion-Version", notionAPIVersion)
+
+		children, err := n.blockChildren(req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range children.Results {
+			blockIDs = append(blockIDs, result.ID)
+		}
 
-	for _, imageURL := range word.ImageURLs() {
-		blocks = append(blocks, imageBlock(imageURL))
+		if !children.HasMore {
+			return blockIDs, nil
+		}
+
+		query.Set(notionStartCursorFlag, children.NextCursor)
+	}
+}
+
+func (n Notion) blockChildren(req *http.Request) (*blockChildrenResponse, error) {
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request execution error: %w", err)
 	}
 
-	for _, definition := range word.Definitions() {
-		blocks = append(blocks, definitionBlock(definition))
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
 	}
 
-	for _, example := range word.Examples() {
-		blocks = append(blocks, exampleBlock(example))
+	children := new(blockChildrenResponse)
+	if err = json.NewDecoder(resp.Body).Decode(children); err != nil {
+		return nil, fmt.Errorf("response decoding error: %w", err)
 	}
 
+	return children, nil
+}
+
+func (n Notion) buildPageCreateRequest(word *domain.Word) *notionapi.PageCreateRequest {
 	return &notionapi.PageCreateRequest{
 		Parent: notionapi.Parent{
 			Type:       notionapi.ParentTypeDatabaseID,
 			DatabaseID: n.databaseID,
 			PageID:     "",
 		},
-		Properties: map[string]notionapi.Property{
-			"Name": notionapi.TitleProperty{
-				ID:   "",
-				Type: "",
-				Title: []notionapi.RichText{
-					{Text: notionapi.Text{Content: word.Word(), Link: nil}},
-				},
-			},
+		Properties: n.pageProperties(word, true),
+		Children:   n.layout.blocks(word),
+		Icon:       nil,
+		Cover:      nil,
+	}
+}
+
+// pageProperties fills the mapped properties, the date added and the status are filled only for new pages.
+// Empty tags and unknown ranks are left out, so updating a page doesn't clear them.
+func (n Notion) pageProperties(word *domain.Word, isNewPage bool) notionapi.Properties {
+	properties := notionapi.Properties{
+		n.properties.title: notionapi.TitleProperty{
+			ID:    "",
+			Type:  "",
+			Title: []notionapi.RichText{plainRichText(word.Word())},
 		},
-		Children: blocks,
-		Icon:     nil,
-		Cover:    nil,
 	}
+
+	if n.properties.partOfSpeech != "" {
+		properties[n.properties.partOfSpeech] = multiSelectProperty(word.PartsOfSpeech())
+	}
+
+	if n.properties.sourceText != "" && word.SourceSentence() != "" {
+		properties[n.properties.sourceText] = notionapi.RichTextProperty{
+			ID:       "",
+			Type:     "",
+			RichText: []notionapi.RichText{plainRichText(truncate(word.SourceSentence(), notionMaxTextLength))},
+		}
+	}
+
+	if n.properties.frequencyRank != "" && word.FrequencyRank() > 0 {
+		properties[n.properties.frequencyRank] = notionapi.NumberProperty{
+			ID:     "",
+			Type:   "",
+			Number: float64(word.FrequencyRank()),
+		}
+	}
+
+	if n.properties.tags != "" && len(word.Tags()) > 0 {
+		properties[n.properties.tags] = multiSelectProperty(word.Tags())
+	}
+
+	if !isNewPage {
+		return properties
+	}
+
+	if n.properties.dateAdded != "" {
+		now := notionapi.Date(time.Now())
+		properties[n.properties.dateAdded] = notionapi.DateProperty{
+			ID:   "",
+			Type: "",
+			Date: &notionapi.DateObject{Start: &now, End: nil},
+		}
+	}
+
+	if n.properties.status != "" && n.properties.newStatus != "" {
+		properties[n.properties.status] = notionapi.SelectProperty{
+			ID:     "",
+			Type:   "",
+			Select: notionapi.Option{ID: "", Name: n.properties.newStatus, Color: ""},
+		}
+	}
+
+	return properties
 }
 
-func imageBlock(imageURL string) notionapi.ImageBlock {
-	return notionapi.ImageBlock{
+// multiSelectProperty replaces commas in the option names, Notion doesn't allow them there.
+func multiSelectProperty(names []string) notionapi.MultiSelectProperty {
+	options := make([]notionapi.Option, len(names))
+	for i := range names {
+		options[i] = notionapi.Option{
+			ID:    "",
+			Name:  strings.ReplaceAll(names[i], notionOptionSeparator, notionOptionSeparatorFix),
+			Color: "",
+		}
+	}
+
+	return notionapi.MultiSelectProperty{ID: "", Type: "", MultiSelect: options}
+}
+
+func plainRichText(content string) notionapi.RichText {
+	return notionapi.RichText{Text: notionapi.Text{Content: content, Link: nil}}
+}
+
+// truncate cuts the text to the max length in characters, Notion limits the length of text objects.
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+
+	return string(runes[:maxLength])
+}
+
+func dividerBlock() notionapi.DividerBlock {
+	return notionapi.DividerBlock{
 		BasicBlock: notionapi.BasicBlock{
 			Object:         notionapi.ObjectTypeBlock,
-			Type:           notionapi.BlockTypeImage,
+			Type:           notionapi.BlockTypeDivider,
 			ID:             "",
 			CreatedTime:    nil,
 			LastEditedTime: nil,
@@ -76,23 +487,15 @@ func imageBlock(imageURL string) notionapi.ImageBlock {
 			HasChildren:    false,
 			Archived:       false,
 		},
-		Image: notionapi.Image{
-			Type: notionapi.FileTypeExternal,
-			External: &notionapi.FileObject{
-				URL:        imageURL,
-				ExpiryTime: nil,
-			},
-			Caption: nil,
-			File:    nil,
-		},
+		Divider: notionapi.Divider{},
 	}
 }
 
-func definitionBlock(definition string) notionapi.BulletedListItemBlock {
-	return notionapi.BulletedListItemBlock{
+func imageBlock(imageURL string) notionapi.ImageBlock {
+	return notionapi.ImageBlock{
 		BasicBlock: notionapi.BasicBlock{
 			Object:         notionapi.ObjectTypeBlock,
-			Type:           notionapi.BlockTypeBulletedListItem,
+			Type:           notionapi.BlockTypeImage,
 			ID:             "",
 			CreatedTime:    nil,
 			LastEditedTime: nil,
@@ -101,23 +504,23 @@ func definitionBlock(definition string) notionapi.BulletedListItemBlock {
 			HasChildren:    false,
 			Archived:       false,
 		},
-		BulletedListItem: notionapi.ListItem{
-			RichText: []notionapi.RichText{
-				{
-					Text: notionapi.Text{Content: definition, Link: nil},
-				},
+		Image: notionapi.Image{
+			Type: notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{
+				URL:        imageURL,
+				ExpiryTime: nil,
 			},
-			Children: nil,
-			Color:    "",
+			Caption: nil,
+			File:    nil,
 		},
 	}
 }
 
-func exampleBlock(example string) notionapi.ParagraphBlock {
-	return notionapi.ParagraphBlock{
+func pronunciationAudioBlock(audioURL string) audioBlock {
+	return audioBlock{
 		BasicBlock: notionapi.BasicBlock{
 			Object:         notionapi.ObjectTypeBlock,
-			Type:           notionapi.BlockTypeParagraph,
+			Type:           blockTypeAudio,
 			ID:             "",
 			CreatedTime:    nil,
 			LastEditedTime: nil,
@@ -126,22 +529,14 @@ func exampleBlock(example string) notionapi.ParagraphBlock {
 			HasChildren:    false,
 			Archived:       false,
 		},
-		Paragraph: notionapi.Paragraph{
-			RichText: []notionapi.RichText{
-				{
-					Text: notionapi.Text{Content: example, Link: nil},
-					Annotations: &notionapi.Annotations{
-						Italic:        true,
-						Bold:          false,
-						Strikethrough: false,
-						Underline:     false,
-						Code:          false,
-						Color:         "",
-					},
-				},
+		Audio: notionapi.BlockFile{
+			Type: notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{
+				URL:        audioURL,
+				ExpiryTime: nil,
 			},
-			Children: nil,
-			Color:    "",
+			Caption: nil,
+			File:    nil,
 		},
 	}
 }
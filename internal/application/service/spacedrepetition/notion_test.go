@@ -0,0 +1,521 @@
+package spacedrepetition_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const testNotionDatabaseID = "db"
+
+// notionStub keeps the pages of one database in memory and answers the Notion API calls the target makes.
+// The requests are recorded as `METHOD /path?query` lines along with their bodies, the failing ones and
+// the queries starting at the failing cursor are answered with an API error. Pages are found by the title only, queries without a title filter get
+// the learned pages. The learned pages and the blocks are listed a page of the page size at a time.
+type notionStub struct {
+	mu            sync.Mutex
+	requests      []string
+	bodies        map[string][]map[string]interface{}
+	failing       map[string]bool
+	failingCursor string
+	properties    map[string]string
+	pageIDs       map[string]string
+	learnedPages  []interface{}
+	children      map[string][]string
+	pageSize      int
+}
+
+// hostRewriter sends the requests to the stub, the target calls the Notion API by absolute URLs.
+type hostRewriter struct {
+	target *url.URL
+}
+
+func (h hostRewriter) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = h.target.Scheme, h.target.Host
+
+	return http.DefaultTransport.RoundTrip(req) //nolint:wrapcheck
+}
+
+func newNotionStub(t *testing.T) (*notionStub, *http.Client) {
+	t.Helper()
+
+	stub := &notionStub{
+		bodies:        map[string][]map[string]interface{}{},
+		failing:       map[string]bool{},
+		failingCursor: "",
+		properties:    map[string]string{},
+		pageIDs:       map[string]string{},
+		learnedPages:  nil,
+		children:      map[string][]string{},
+		pageSize:      2,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stub, &http.Client{Transport: hostRewriter{target: target}} //nolint:exhaustruct
+}
+
+func newTestNotion(httpClient *http.Client, existingPages spacedrepetition.ExistingPages) *spacedrepetition.Notion {
+	return spacedrepetition.NewNotion(
+		notionapi.NewClient("secret", notionapi.WithHTTPClient(httpClient)),
+		httpClient,
+		testNotionDatabaseID,
+		spacedrepetition.NewNotionProperties(
+			"Name", "Part of speech", "Source", "Added", "Rank", "Tags", "Status", "New", "Learned", []string{"Known"},
+		),
+		existingPages,
+		spacedrepetition.DefaultNotionLayout(),
+	)
+}
+
+func newNotionTestWord(word string, frequencyRank int, tags []string) *domain.Word {
+	return domain.NewWord(
+		word,
+		"",
+		"The "+word+" sat on the mat.",
+		[]*domain.Definition{domain.NewDefinition("noun", "a small animal", "", nil, nil)},
+		[]string{"a " + word + " example"},
+		nil,
+		nil,
+		frequencyRank,
+		tags,
+	)
+}
+
+func (s *notionStub) serve(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request := r.Method + " " + r.URL.RequestURI()
+	s.requests = append(s.requests, request)
+	s.bodies[request] = append(s.bodies[request], body)
+
+	if s.failing[request] || (s.failingCursor != "" && body["start_cursor"] == s.failingCursor) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"object": "error", "status": http.StatusBadRequest, "code": "validation_error", "message": "rejected",
+		})
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(s.handle(r.Method, strings.Split(strings.Trim(r.URL.Path, "/"), "/"), r.URL.Query()))
+}
+
+func (s *notionStub) handle(method string, path []string, query url.Values) interface{} {
+	switch {
+	case method == http.MethodGet && len(path) == 3 && path[1] == "databases":
+		properties := make(map[string]interface{}, len(s.properties))
+		for name, propertyType := range s.properties {
+			properties[name] = map[string]interface{}{"id": name, "type": propertyType, propertyType: struct{}{}}
+		}
+
+		return map[string]interface{}{"object": "database", "id": path[2], "properties": properties}
+	case method == http.MethodPost && len(path) == 4 && path[3] == "query":
+		request := s.requests[len(s.requests)-1]
+		body := s.bodies[request][len(s.bodies[request])-1]
+		filter, _ := body["filter"].(map[string]interface{})
+
+		title, isTitleFilter := filter["rich_text"].(map[string]interface{})
+		if !isTitleFilter {
+			cursor, _ := body["start_cursor"].(string)
+
+			return s.list(s.learnedPages, cursor)
+		}
+
+		results := make([]interface{}, 0, 1)
+		if pageID, exists := s.pageIDs[title["equals"].(string)]; exists {
+			results = append(results, notionStubPage(pageID))
+		}
+
+		return map[string]interface{}{"object": "list", "results": results, "has_more": false}
+	case method == http.MethodPost && len(path) == 2:
+		return notionStubPage("new-page")
+	case method == http.MethodPatch && len(path) == 3 && path[1] == "pages":
+		return notionStubPage(path[2])
+	case method == http.MethodGet && len(path) == 4:
+		children := make([]interface{}, len(s.children[path[2]]))
+		for i, blockID := range s.children[path[2]] {
+			children[i] = notionStubBlock(blockID)
+		}
+
+		return s.list(children, query.Get("start_cursor"))
+	case method == http.MethodDelete:
+		return notionStubBlock(path[2])
+	default:
+		return map[string]interface{}{"object": "list", "results": []interface{}{}}
+	}
+}
+
+// list returns a page of the results from the cursor, the cursor is the index of the first result.
+func (s *notionStub) list(results []interface{}, cursor string) map[string]interface{} {
+	start, _ := strconv.Atoi(cursor)
+
+	end := start + s.pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return map[string]interface{}{
+		"object": "list", "results": results[start:end], "has_more": end < len(results), "next_cursor": strconv.Itoa(end),
+	}
+}
+
+func notionStubPage(pageID string) map[string]interface{} {
+	return map[string]interface{}{"object": "page", "id": pageID, "properties": map[string]interface{}{}}
+}
+
+func notionStubBlock(blockID string) map[string]interface{} {
+	return map[string]interface{}{
+		"object": "block", "id": blockID, "type": "paragraph", "paragraph": map[string]interface{}{"rich_text": nil},
+	}
+}
+
+func TestParseExistingPages(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"skip", "update", "append"} {
+		if existingPages, err := spacedrepetition.ParseExistingPages(mode); err != nil || string(existingPages) != mode {
+			t.Errorf("got %q and error %v for %q, want it parsed", existingPages, err, mode)
+		}
+	}
+
+	if _, err := spacedrepetition.ParseExistingPages("replace"); err == nil {
+		t.Error("got no error for an unknown mode, want one")
+	}
+}
+
+func TestNotionUploadWordsExistingPages(t *testing.T) {
+	t.Parallel()
+
+	const (
+		query         = "POST /v1/databases/db/query"
+		create        = "POST /v1/pages"
+		appendToCat   = "PATCH /v1/blocks/cat-page/children"
+		listCatBlocks = "GET /v1/blocks/cat-page/children?page_size=100"
+	)
+
+	for name, testCase := range map[string]struct {
+		existingPages  spacedrepetition.ExistingPages
+		wantStatus     spacedrepetition.UploadStatus
+		wantRequests   []string
+		wantFirstBlock string
+	}{
+		"skip": {
+			existingPages:  spacedrepetition.ExistingPagesSkip,
+			wantStatus:     spacedrepetition.UploadStatusSkipped,
+			wantRequests:   []string{query, query, create},
+			wantFirstBlock: "",
+		},
+		"update": {
+			existingPages: spacedrepetition.ExistingPagesUpdate,
+			wantStatus:    spacedrepetition.UploadStatusUpdated,
+			wantRequests: []string{
+				query,
+				"PATCH /v1/pages/cat-page",
+				listCatBlocks,
+				listCatBlocks + "&start_cursor=2",
+				"DELETE /v1/blocks/block-1",
+				"DELETE /v1/blocks/block-2",
+				"DELETE /v1/blocks/block-3",
+				appendToCat,
+				query,
+				create,
+			},
+			wantFirstBlock: "heading_3",
+		},
+		"append": {
+			existingPages:  spacedrepetition.ExistingPagesAppend,
+			wantStatus:     spacedrepetition.UploadStatusAppended,
+			wantRequests:   []string{query, appendToCat, query, create},
+			wantFirstBlock: "divider",
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stub, httpClient := newNotionStub(t)
+			stub.pageIDs["cat"] = "cat-page"
+			stub.children["cat-page"] = []string{"block-1", "block-2", "block-3"}
+
+			reports, err := newTestNotion(httpClient, testCase.existingPages).UploadWords(
+				context.Background(), []*domain.Word{newNotionTestWord("cat", 0, nil), newNotionTestWord("dog", 0, nil)},
+			)
+			if err != nil {
+				t.Fatalf("uploading words error: %s", err)
+			}
+
+			results := reports[0].Words()
+			if results[0].Status() != testCase.wantStatus || results[1].Status() != spacedrepetition.UploadStatusCreated {
+				t.Errorf("got statuses %q and %q, want %q and created", results[0].Status(), results[1].Status(),
+					testCase.wantStatus)
+			}
+
+			if !reflect.DeepEqual(stub.requests, testCase.wantRequests) {
+				t.Errorf("got requests\n%s\nwant\n%s",
+					strings.Join(stub.requests, "\n"), strings.Join(testCase.wantRequests, "\n"))
+			}
+
+			filter, _ := stub.bodies[query][0]["filter"].(map[string]interface{})
+			if title, _ := filter["rich_text"].(map[string]interface{}); filter["property"] != "Name" ||
+				title["equals"] != "cat" || stub.bodies[query][0]["page_size"] != 1.0 {
+				t.Errorf("got the page searched with %v, want the title equal to the word", stub.bodies[query][0])
+			}
+
+			if testCase.wantFirstBlock == "" {
+				return
+			}
+
+			children, _ := stub.bodies[appendToCat][0]["children"].([]interface{})
+			if firstBlock, _ := children[0].(map[string]interface{}); firstBlock["type"] != testCase.wantFirstBlock {
+				t.Errorf("got the first appended block %v, want %q", children[0], testCase.wantFirstBlock)
+			}
+		})
+	}
+}
+
+func TestNotionUploadWordsReportsFailedWords(t *testing.T) {
+	t.Parallel()
+
+	stub, httpClient := newNotionStub(t)
+	stub.pageIDs["cat"] = "cat-page"
+	stub.children["cat-page"] = []string{"block-1", "block-2", "block-3"}
+	stub.failing["DELETE /v1/blocks/block-2"] = true
+
+	reports, err := newTestNotion(httpClient, spacedrepetition.ExistingPagesUpdate).UploadWords(
+		context.Background(), []*domain.Word{newNotionTestWord("cat", 0, nil), newNotionTestWord("dog", 0, nil)},
+	)
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	cat, dog := reports[0].Words()[0], reports[0].Words()[1]
+	if cat.Status() != spacedrepetition.UploadStatusFailed || cat.Err() == nil {
+		t.Errorf("got cat status %q with error %v, want it failed", cat.Status(), cat.Err())
+	}
+
+	if dog.Status() != spacedrepetition.UploadStatusCreated {
+		t.Errorf("got dog status %q with error %v, want the next words uploaded", dog.Status(), dog.Err())
+	}
+}
+
+// notionPropertyValues renders the property values of a page request as `name: value` lines, multi-select
+// values are joined with `|`.
+func notionPropertyValues(t *testing.T, body map[string]interface{}) []string {
+	t.Helper()
+
+	properties, _ := body["properties"].(map[string]interface{})
+	values := make([]string, 0, len(properties))
+
+	for name, property := range properties {
+		encoded, err := json.Marshal(property)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var value struct {
+			Title       []notionapi.RichText `json:"title"`
+			RichText    []notionapi.RichText `json:"rich_text"`    //nolint:tagliatelle
+			MultiSelect []notionapi.Option   `json:"multi_select"` //nolint:tagliatelle
+			Select      *notionapi.Option    `json:"select"`
+			Number      *float64             `json:"number"`
+			Date        *struct {
+				Start string `json:"start"`
+			} `json:"date"`
+		}
+		if err = json.Unmarshal(encoded, &value); err != nil {
+			t.Fatal(err)
+		}
+
+		options := make([]string, 0, len(value.MultiSelect))
+		for _, option := range value.MultiSelect {
+			options = append(options, option.Name)
+		}
+
+		switch {
+		case value.Title != nil:
+			values = append(values, name+": "+value.Title[0].Text.Content)
+		case value.RichText != nil:
+			values = append(values, name+": "+value.RichText[0].Text.Content)
+		case value.MultiSelect != nil:
+			values = append(values, name+": "+strings.Join(options, "|"))
+		case value.Select != nil:
+			values = append(values, name+": "+value.Select.Name)
+		case value.Number != nil:
+			values = append(values, name+": "+strconv.FormatFloat(*value.Number, 'f', -1, 64))
+		case value.Date != nil && value.Date.Start != "":
+			values = append(values, name+": a date")
+		default:
+			values = append(values, name+": "+string(encoded))
+		}
+	}
+
+	sort.Strings(values)
+
+	return values
+}
+
+func TestNotionUploadWordsFillsProperties(t *testing.T) {
+	t.Parallel()
+
+	stub, httpClient := newNotionStub(t)
+	stub.pageIDs["dog"] = "dog-page"
+
+	_, err := newTestNotion(httpClient, spacedrepetition.ExistingPagesUpdate).UploadWords(
+		context.Background(),
+		[]*domain.Word{newNotionTestWord("cat", 1200, []string{"pets", "Tom, Jerry"}), newNotionTestWord("dog", 0, nil)},
+	)
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	created := stub.bodies["POST /v1/pages"][0]
+	if parent, _ := created["parent"].(map[string]interface{}); parent["database_id"] != testNotionDatabaseID {
+		t.Errorf("got the page created in %v, want the database", created["parent"])
+	}
+
+	if got, want := notionPropertyValues(t, created), []string{
+		"Added: a date",
+		"Name: cat",
+		"Part of speech: noun",
+		"Rank: 1200",
+		"Source: The cat sat on the mat.",
+		"Status: New",
+		"Tags: pets|Tom  Jerry",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got the new page properties\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The date added and the status are kept on updates, unknown ranks and empty tags don't clear the page ones.
+	updated := stub.bodies["PATCH /v1/pages/dog-page"][0]
+	if got, want := notionPropertyValues(t, updated), []string{
+		"Name: dog",
+		"Part of speech: noun",
+		"Source: The dog sat on the mat.",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got the updated page properties\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNotionUploadWordsFillsOnlyMappedProperties(t *testing.T) {
+	t.Parallel()
+
+	stub, httpClient := newNotionStub(t)
+	notion := spacedrepetition.NewNotion(
+		notionapi.NewClient("secret", notionapi.WithHTTPClient(httpClient)),
+		httpClient,
+		testNotionDatabaseID,
+		spacedrepetition.NewNotionProperties("Word", "", "", "", "", "", "Status", "", "", nil),
+		spacedrepetition.ExistingPagesSkip,
+		spacedrepetition.DefaultNotionLayout(),
+	)
+
+	if _, err := notion.UploadWords(
+		context.Background(), []*domain.Word{newNotionTestWord("cat", 1200, []string{"pets"})},
+	); err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	// The status isn't set without the status of new pages.
+	if got, want := notionPropertyValues(t, stub.bodies["POST /v1/pages"][0]), []string{"Word: cat"}; !reflect.DeepEqual(
+		got, want,
+	) {
+		t.Errorf("got properties %q, want %q", got, want)
+	}
+}
+
+func TestNotionValidateSchema(t *testing.T) {
+	t.Parallel()
+
+	allProperties := map[string]string{
+		"Name":           "title",
+		"Part of speech": "multi_select",
+		"Source":         "rich_text",
+		"Added":          "date",
+		"Rank":           "number",
+		"Tags":           "multi_select",
+		"Status":         "select",
+		"Learned":        "checkbox",
+		"Notes":          "rich_text",
+	}
+
+	for name, testCase := range map[string]struct {
+		properties map[string]string
+		wantErr    string
+	}{
+		"matching schema": {
+			properties: allProperties,
+			wantErr:    "",
+		},
+		"missing and mistyped properties": {
+			properties: map[string]string{
+				"Name":           "title",
+				"Part of speech": "multi_select",
+				"Source":         "rich_text",
+				"Added":          "date",
+				"Tags":           "select",
+				"Status":         "rich_text",
+				"Learned":        "checkbox",
+			},
+			wantErr: "the database schema doesn't match the properties: " +
+				"property `Rank` of type `number` is missing; " +
+				"property `Status` is of type `rich_text`, expected `select`; " +
+				"property `Tags` is of type `select`, expected `multi_select`",
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stub, httpClient := newNotionStub(t)
+			stub.properties = testCase.properties
+
+			err := newTestNotion(httpClient, spacedrepetition.ExistingPagesSkip).ValidateSchema(context.Background())
+			if (err == nil && testCase.wantErr != "") || (err != nil && err.Error() != testCase.wantErr) {
+				t.Errorf("got error %v, want %q", err, testCase.wantErr)
+			}
+
+			if want := []string{"GET /v1/databases/db"}; !reflect.DeepEqual(stub.requests, want) {
+				t.Errorf("got requests %q, want %q", stub.requests, want)
+			}
+		})
+	}
+}
+
+func TestNotionValidateSchemaFailsWithoutDatabase(t *testing.T) {
+	t.Parallel()
+
+	stub, httpClient := newNotionStub(t)
+	stub.failing["GET /v1/databases/db"] = true
+
+	if err := newTestNotion(httpClient, spacedrepetition.ExistingPagesSkip).ValidateSchema(
+		context.Background(),
+	); err == nil {
+		t.Error("got no error, want one when the database can't be got")
+	}
+}
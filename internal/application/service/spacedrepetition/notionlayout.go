@@ -0,0 +1,561 @@
+package spacedrepetition
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/r-erema/vocaboost/internal/domain"
+	"gopkg.in/yaml.v2"
+)
+
+// NotionContent is the word field a layout section shows.
+type NotionContent string
+
+const (
+	NotionContentImages        NotionContent = "images"
+	NotionContentPronunciation NotionContent = "pronunciation"
+	NotionContentAudio         NotionContent = "audio"
+	NotionContentTranslation   NotionContent = "translation"
+	NotionContentDefinitions   NotionContent = "definitions"
+	NotionContentExamples      NotionContent = "examples"
+	NotionContentClozes        NotionContent = "clozes"
+	NotionContentContext       NotionContent = "context"
+	NotionContentDivider       NotionContent = "divider"
+)
+
+// NotionBlock is the block type the items of a text section are rendered as.
+type NotionBlock string
+
+const (
+	NotionBlockParagraph NotionBlock = "paragraph"
+	NotionBlockBulleted  NotionBlock = "bulleted_list_item"
+	NotionBlockNumbered  NotionBlock = "numbered_list_item"
+	NotionBlockQuote     NotionBlock = "quote"
+	NotionBlockCallout   NotionBlock = "callout"
+	// NotionBlockToggle hides the answers of a cloze inside a toggle, it's for clozes only.
+	NotionBlockToggle NotionBlock = "toggle"
+)
+
+const (
+	notionMinHeadingLevel = 1
+	notionMaxHeadingLevel = 3
+	notionMinColumns      = 2
+)
+
+var (
+	errUnknownNotionContent = errors.New("unknown section content")
+	errUnknownNotionBlock   = errors.New("unknown block type")
+	errUnknownNotionColor   = errors.New("unknown color")
+	errBadNotionSection     = errors.New("bad section")
+	errNoNotionSections     = errors.New("no sections")
+)
+
+// notionColors are the colors Notion accepts for blocks.
+var notionColors = []notionapi.Color{ //nolint:gochecknoglobals
+	notionapi.ColorDefault, notionapi.ColorGray, notionapi.ColorBrown, notionapi.ColorOrange, notionapi.ColorYellow,
+	notionapi.ColorGreen, notionapi.ColorBlue, notionapi.ColorPurple, notionapi.ColorPink, notionapi.ColorRed,
+	notionapi.ColorDefaultBackground, notionapi.ColorGrayBackground, notionapi.ColorBrownBackground,
+	notionapi.ColorOrangeBackground, notionapi.ColorYellowBackground, notionapi.ColorGreenBackground,
+	notionapi.ColorBlueBackground, notionapi.ColorPurpleBackground, notionapi.ColorPinkBackground,
+	notionapi.ColorRedBackground,
+}
+
+// NotionLayout is the order and the look of the sections of the page body.
+type NotionLayout struct {
+	sections []*NotionSection
+}
+
+// NotionSection is a part of the page body showing a word field. The section is headed by the heading if it's set,
+// or it's folded into a toggle titled by the heading. Text items are rendered as blocks of the block type,
+// images are laid out side by side if there are several columns. Sections without items are left out.
+type NotionSection struct {
+	content      NotionContent
+	heading      string
+	headingLevel int
+	toggle       bool
+	block        NotionBlock
+	color        notionapi.Color
+	icon         string
+	columns      int
+}
+
+func NewNotionLayout(sections []*NotionSection) (*NotionLayout, error) {
+	if len(sections) == 0 {
+		return nil, errNoNotionSections
+	}
+
+	return &NotionLayout{sections: sections}, nil
+}
+
+// DefaultNotionLayout is images, pronunciation, audio, translation, definitions grouped by part of speech,
+// italic examples and clozes in toggles.
+func DefaultNotionLayout() *NotionLayout {
+	contents := []NotionContent{
+		NotionContentImages,
+		NotionContentPronunciation,
+		NotionContentAudio,
+		NotionContentTranslation,
+		NotionContentDefinitions,
+		NotionContentExamples,
+		NotionContentClozes,
+	}
+
+	sections := make([]*NotionSection, len(contents))
+	for i, content := range contents {
+		sections[i] = &NotionSection{
+			content:      content,
+			heading:      "",
+			headingLevel: notionMaxHeadingLevel,
+			toggle:       false,
+			block:        defaultNotionBlock(content),
+			color:        "",
+			icon:         "",
+			columns:      0,
+		}
+	}
+
+	return &NotionLayout{sections: sections}
+}
+
+// NewNotionSection checks the section options fit the content. The heading level is 3 if it's zero,
+// the block type is the default one of the content if it's empty.
+func NewNotionSection(
+	content NotionContent,
+	heading string,
+	headingLevel int,
+	toggle bool,
+	block NotionBlock,
+	color notionapi.Color,
+	icon string,
+	columns int,
+) (*NotionSection, error) {
+	switch content {
+	case NotionContentImages, NotionContentPronunciation, NotionContentAudio, NotionContentTranslation,
+		NotionContentDefinitions, NotionContentExamples, NotionContentClozes, NotionContentContext,
+		NotionContentDivider:
+	default:
+		return nil, fmt.Errorf("%w `%s`", errUnknownNotionContent, content)
+	}
+
+	if err := checkNotionBlock(content, block); err != nil {
+		return nil, err
+	}
+
+	if block == "" {
+		block = defaultNotionBlock(content)
+	}
+
+	if headingLevel == 0 {
+		headingLevel = notionMaxHeadingLevel
+	}
+
+	if headingLevel < notionMinHeadingLevel || headingLevel > notionMaxHeadingLevel {
+		return nil, fmt.Errorf("%w `%s`: heading level %d isn't 1, 2 or 3", errBadNotionSection, content, headingLevel)
+	}
+
+	if toggle && heading == "" {
+		return nil, fmt.Errorf("%w `%s`: a toggle needs a heading", errBadNotionSection, content)
+	}
+
+	if color != "" && !isNotionColor(color) {
+		return nil, fmt.Errorf("%w `%s`", errUnknownNotionColor, color)
+	}
+
+	if columns != 0 && content != NotionContentImages {
+		return nil, fmt.Errorf("%w `%s`: only images are laid out in columns", errBadNotionSection, content)
+	}
+
+	// Notion creates blocks nested two levels deep at most, a toggle with columns would be the third level.
+	if columns >= notionMinColumns && toggle {
+		return nil, fmt.Errorf("%w `%s`: images in columns can't be in a toggle", errBadNotionSection, content)
+	}
+
+	return &NotionSection{
+		content:      content,
+		heading:      heading,
+		headingLevel: headingLevel,
+		toggle:       toggle,
+		block:        block,
+		color:        color,
+		icon:         icon,
+		columns:      columns,
+	}, nil
+}
+
+// checkNotionBlock allows block types for text contents only, the toggle is for clozes only.
+func checkNotionBlock(content NotionContent, block NotionBlock) error {
+	switch block {
+	case "":
+		return nil
+	case NotionBlockParagraph, NotionBlockBulleted, NotionBlockNumbered, NotionBlockQuote, NotionBlockCallout,
+		NotionBlockToggle:
+	default:
+		return fmt.Errorf("%w `%s`", errUnknownNotionBlock, block)
+	}
+
+	switch content {
+	case NotionContentImages, NotionContentAudio, NotionContentDivider:
+		return fmt.Errorf("%w `%s`: it isn't rendered as text blocks", errBadNotionSection, content)
+	case NotionContentPronunciation, NotionContentTranslation, NotionContentDefinitions, NotionContentExamples,
+		NotionContentContext:
+		if block == NotionBlockToggle {
+			return fmt.Errorf("%w `%s`: toggle blocks are for clozes only", errBadNotionSection, content)
+		}
+	case NotionContentClozes:
+	}
+
+	return nil
+}
+
+func defaultNotionBlock(content NotionContent) NotionBlock {
+	switch content {
+	case NotionContentDefinitions:
+		return NotionBlockBulleted
+	case NotionContentClozes:
+		return NotionBlockToggle
+	case NotionContentImages, NotionContentAudio, NotionContentDivider:
+		return ""
+	default:
+		return NotionBlockParagraph
+	}
+}
+
+func isNotionColor(color notionapi.Color) bool {
+	for _, notionColor := range notionColors {
+		if color == notionColor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notionLayoutSpec is the YAML layout file, the keys are camel case like the flashcards file columns.
+type notionLayoutSpec struct {
+	Sections []struct {
+		Content      string `yaml:"content"`
+		Heading      string `yaml:"heading"`
+		HeadingLevel int    `yaml:"headingLevel"`
+		Toggle       bool   `yaml:"toggle"`
+		Block        string `yaml:"block"`
+		Color        string `yaml:"color"`
+		Icon         string `yaml:"icon"`
+		Columns      int    `yaml:"columns"`
+	} `yaml:"sections"`
+}
+
+// ParseNotionLayout parses a YAML layout, unknown keys are errors, so typos don't go unnoticed.
+func ParseNotionLayout(data []byte) (*NotionLayout, error) {
+	var spec notionLayoutSpec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, fmt.Errorf("layout decoding error: %w", err)
+	}
+
+	sections := make([]*NotionSection, len(spec.Sections))
+
+	for i, sectionSpec := range spec.Sections {
+		section, err := NewNotionSection(
+			NotionContent(strings.TrimSpace(sectionSpec.Content)),
+			sectionSpec.Heading,
+			sectionSpec.HeadingLevel,
+			sectionSpec.Toggle,
+			NotionBlock(strings.TrimSpace(sectionSpec.Block)),
+			notionapi.Color(strings.TrimSpace(sectionSpec.Color)),
+			sectionSpec.Icon,
+			sectionSpec.Columns,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("section %d error: %w", i+1, err)
+		}
+
+		sections[i] = section
+	}
+
+	return NewNotionLayout(sections)
+}
+
+func LoadNotionLayout(path string) (*NotionLayout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading layout file `%s` error: %w", path, err)
+	}
+
+	return ParseNotionLayout(data)
+}
+
+// blocks lays out the page body of the word.
+func (nl NotionLayout) blocks(word *domain.Word) []notionapi.Block {
+	blocks := make([]notionapi.Block, 0)
+
+	for _, section := range nl.sections {
+		blocks = append(blocks, section.blocks(word)...)
+	}
+
+	return blocks
+}
+
+func (ns NotionSection) blocks(word *domain.Word) []notionapi.Block {
+	items := ns.items(word)
+	if len(items) == 0 || ns.heading == "" {
+		return items
+	}
+
+	if ns.toggle {
+		return []notionapi.Block{notionapi.ToggleBlock{
+			BasicBlock: notionBasicBlock(notionapi.BlockTypeToggle, true),
+			Toggle: notionapi.Toggle{
+				RichText: []notionapi.RichText{plainRichText(ns.heading)},
+				Children: items,
+				Color:    string(ns.color),
+			},
+		}}
+	}
+
+	return append([]notionapi.Block{headingBlock(ns.headingLevel, ns.heading, ns.color)}, items...)
+}
+
+func (ns NotionSection) items(word *domain.Word) []notionapi.Block {
+	items := make([]notionapi.Block, 0)
+
+	switch ns.content {
+	case NotionContentImages:
+		return imageBlocks(word.ImageURLs(), ns.columns)
+	case NotionContentPronunciation:
+		if pronunciation := word.Pronunciation(); pronunciation != nil &&
+			(pronunciation.IPA() != "" || len(pronunciation.Syllables()) > 0) {
+			items = append(items, ns.textBlock(pronunciationRichText(pronunciation)))
+		}
+	case NotionContentAudio:
+		if word.Pronunciation() != nil && word.Pronunciation().AudioURL() != "" {
+			items = append(items, pronunciationAudioBlock(word.Pronunciation().AudioURL()))
+		}
+	case NotionContentTranslation:
+		if word.Translation() != "" {
+			items = append(items, ns.textBlock(translationRichText(word.Translation())))
+		}
+	case NotionContentDefinitions:
+		for _, group := range word.DefinitionsByPartOfSpeech() {
+			if group.PartOfSpeech() != "" {
+				items = append(items, headingBlock(notionMaxHeadingLevel, group.PartOfSpeech(), ""))
+			}
+
+			for _, definition := range group.Definitions() {
+				items = append(items, ns.textBlock(definitionRichText(definition)))
+			}
+		}
+	case NotionContentExamples:
+		for _, example := range word.Examples() {
+			items = append(items, ns.textBlock(exampleRichText(example)))
+		}
+	case NotionContentClozes:
+		for _, cloze := range word.Clozes() {
+			items = append(items, ns.clozeBlock(cloze))
+		}
+	case NotionContentContext:
+		if word.SourceSentence() != "" {
+			items = append(items, ns.textBlock([]notionapi.RichText{
+				plainRichText(truncate(word.SourceSentence(), notionMaxTextLength)),
+			}))
+		}
+	case NotionContentDivider:
+		items = append(items, dividerBlock())
+	}
+
+	return items
+}
+
+// textBlock renders the rich text as a block of the section block type.
+func (ns NotionSection) textBlock(richText []notionapi.RichText) notionapi.Block {
+	switch ns.block {
+	case NotionBlockBulleted:
+		return notionapi.BulletedListItemBlock{
+			BasicBlock:       notionBasicBlock(notionapi.BlockTypeBulletedListItem, false),
+			BulletedListItem: notionapi.ListItem{RichText: richText, Children: nil, Color: string(ns.color)},
+		}
+	case NotionBlockNumbered:
+		return notionapi.NumberedListItemBlock{
+			BasicBlock:       notionBasicBlock(notionapi.BlockTypeNumberedListItem, false),
+			NumberedListItem: notionapi.ListItem{RichText: richText, Children: nil, Color: string(ns.color)},
+		}
+	case NotionBlockQuote:
+		return notionapi.QuoteBlock{
+			BasicBlock: notionBasicBlock(notionapi.BlockQuote, false),
+			Quote:      notionapi.Quote{RichText: richText, Children: nil, Color: string(ns.color)},
+		}
+	case NotionBlockCallout:
+		var icon *notionapi.Icon
+		if ns.icon != "" {
+			emoji := notionapi.Emoji(ns.icon)
+			icon = &notionapi.Icon{Type: "emoji", Emoji: &emoji, File: nil, External: nil}
+		}
+
+		return notionapi.CalloutBlock{
+			BasicBlock: notionBasicBlock(notionapi.BlockCallout, false),
+			Callout:    notionapi.Callout{RichText: richText, Icon: icon, Children: nil, Color: string(ns.color)},
+		}
+	case NotionBlockParagraph, NotionBlockToggle:
+	}
+
+	return notionapi.ParagraphBlock{
+		BasicBlock: notionBasicBlock(notionapi.BlockTypeParagraph, false),
+		Paragraph:  notionapi.Paragraph{RichText: richText, Children: nil, Color: string(ns.color)},
+	}
+}
+
+// clozeBlock is a toggle with the blanked sentence and the answers inside, blocks of other types show the answers
+// after the sentence.
+func (ns NotionSection) clozeBlock(cloze *domain.Cloze) notionapi.Block {
+	answers := strings.Join(cloze.Answers(), ", ")
+
+	if ns.block != NotionBlockToggle {
+		return ns.textBlock([]notionapi.RichText{
+			plainRichText(cloze.Blanked()),
+			annotatedRichText(" ("+answers+")", false, false, false, notionapi.ColorGray),
+		})
+	}
+
+	return notionapi.ToggleBlock{
+		BasicBlock: notionBasicBlock(notionapi.BlockTypeToggle, true),
+		Toggle: notionapi.Toggle{
+			RichText: []notionapi.RichText{plainRichText(cloze.Blanked())},
+			Children: notionapi.Blocks{notionapi.ParagraphBlock{
+				BasicBlock: notionBasicBlock(notionapi.BlockTypeParagraph, false),
+				Paragraph: notionapi.Paragraph{
+					RichText: []notionapi.RichText{annotatedRichText(answers, true, false, false, "")},
+					Children: nil,
+					Color:    "",
+				},
+			}},
+			Color: string(ns.color),
+		},
+	}
+}
+
+// imageBlocks lays the images out in columns, the images are spread over the columns in turn.
+// Notion needs at least two columns, so fewer images or columns give plain image blocks.
+func imageBlocks(imageURLs []string, columns int) []notionapi.Block {
+	if columns > len(imageURLs) {
+		columns = len(imageURLs)
+	}
+
+	if columns < notionMinColumns {
+		blocks := make([]notionapi.Block, len(imageURLs))
+		for i, imageURL := range imageURLs {
+			blocks[i] = imageBlock(imageURL)
+		}
+
+		return blocks
+	}
+
+	columnImages := make([]notionapi.Blocks, columns)
+	for i, imageURL := range imageURLs {
+		columnImages[i%columns] = append(columnImages[i%columns], imageBlock(imageURL))
+	}
+
+	columnBlocks := make(notionapi.Blocks, columns)
+	for i := range columnImages {
+		columnBlocks[i] = notionapi.ColumnBlock{
+			BasicBlock: notionBasicBlock(notionapi.BlockTypeColumn, true),
+			Column:     notionapi.Column{Children: columnImages[i]},
+		}
+	}
+
+	return []notionapi.Block{notionapi.ColumnListBlock{
+		BasicBlock: notionBasicBlock(notionapi.BlockTypeColumnList, true),
+		ColumnList: notionapi.ColumnList{Children: columnBlocks},
+	}}
+}
+
+func headingBlock(level int, text string, color notionapi.Color) notionapi.Block {
+	heading := notionapi.Heading{RichText: []notionapi.RichText{plainRichText(text)}, Children: nil, Color: string(color)}
+
+	switch level {
+	case notionMinHeadingLevel:
+		return notionapi.Heading1Block{BasicBlock: notionBasicBlock(notionapi.BlockTypeHeading1, false), Heading1: heading}
+	case notionMinHeadingLevel + 1:
+		return notionapi.Heading2Block{BasicBlock: notionBasicBlock(notionapi.BlockTypeHeading2, false), Heading2: heading}
+	default:
+		return notionapi.Heading3Block{BasicBlock: notionBasicBlock(notionapi.BlockTypeHeading3, false), Heading3: heading}
+	}
+}
+
+func notionBasicBlock(blockType notionapi.BlockType, hasChildren bool) notionapi.BasicBlock {
+	return notionapi.BasicBlock{
+		Object:         notionapi.ObjectTypeBlock,
+		Type:           blockType,
+		ID:             "",
+		CreatedTime:    nil,
+		LastEditedTime: nil,
+		CreatedBy:      nil,
+		LastEditedBy:   nil,
+		HasChildren:    hasChildren,
+		Archived:       false,
+	}
+}
+
+func pronunciationRichText(pronunciation *domain.Pronunciation) []notionapi.RichText {
+	parts := make([]string, 0, 2) //nolint:gomnd
+	if pronunciation.IPA() != "" {
+		parts = append(parts, fmt.Sprintf("/%s/", strings.Trim(pronunciation.IPA(), "/")))
+	}
+
+	if len(pronunciation.Syllables()) > 0 {
+		parts = append(parts, strings.Join(pronunciation.Syllables(), "·"))
+	}
+
+	richText := annotatedRichText(strings.Join(parts, "  "), false, false, true, "")
+
+	return []notionapi.RichText{richText}
+}
+
+func translationRichText(translation string) []notionapi.RichText {
+	return []notionapi.RichText{annotatedRichText(translation, true, false, false, notionapi.ColorBlue)}
+}
+
+func definitionRichText(definition *domain.Definition) []notionapi.RichText {
+	richText := []notionapi.RichText{plainRichText(definition.Gloss())}
+
+	if definition.Translation() != "" {
+		richText = append(
+			richText,
+			annotatedRichText(" — "+definition.Translation(), false, false, false, notionapi.ColorBlue),
+		)
+	}
+
+	if len(definition.Synonyms()) > 0 {
+		richText = append(richText, relatedWordsRichText("synonyms", definition.Synonyms()))
+	}
+
+	if len(definition.Antonyms()) > 0 {
+		richText = append(richText, relatedWordsRichText("antonyms", definition.Antonyms()))
+	}
+
+	return richText
+}
+
+func relatedWordsRichText(title string, words []string) notionapi.RichText {
+	return annotatedRichText(
+		fmt.Sprintf(" (%s: %s)", title, strings.Join(words, ", ")), false, false, false, notionapi.ColorGray,
+	)
+}
+
+func exampleRichText(example string) []notionapi.RichText {
+	return []notionapi.RichText{annotatedRichText(example, false, true, false, "")}
+}
+
+func annotatedRichText(content string, bold, italic, code bool, color notionapi.Color) notionapi.RichText {
+	return notionapi.RichText{
+		Text: notionapi.Text{Content: content, Link: nil},
+		Annotations: &notionapi.Annotations{
+			Bold:          bold,
+			Italic:        italic,
+			Strikethrough: false,
+			Underline:     false,
+			Code:          code,
+			Color:         color,
+		},
+	}
+}
@@ -0,0 +1,310 @@
+package spacedrepetition
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+func testNotionWord() *domain.Word {
+	return domain.NewWord(
+		"cat",
+		"кошка",
+		"The cat purrs.",
+		[]*domain.Definition{
+			domain.NewDefinition("noun", "a small animal", "животное", []string{"feline"}, nil),
+			domain.NewDefinition("verb", "to vomit", "", nil, []string{"swallow"}),
+		},
+		[]string{"a black cat"},
+		[]*domain.Image{
+			domain.NewImage("https://img.test/1.jpg", ""),
+			domain.NewImage("https://img.test/2.jpg", ""),
+			domain.NewImage("https://img.test/3.jpg", ""),
+		},
+		domain.NewPronunciation("/kæt/", []string{"cat"}, "https://audio.test/cat.mp3", ""),
+		1200, //nolint:gomnd
+		nil,
+	).WithClozes([]*domain.Cloze{domain.NewCloze([]string{"The ", " purrs."}, []string{"cat"})})
+}
+
+// describeBlocks renders blocks as `type(color):text` lines, children are indented under their parents,
+// so expected layouts read like the page.
+func describeBlocks(blocks []notionapi.Block) []string {
+	lines := make([]string, 0, len(blocks))
+
+	for _, block := range blocks {
+		var (
+			richText []notionapi.RichText
+			children notionapi.Blocks
+			color    string
+			extra    string
+		)
+
+		switch typed := block.(type) {
+		case notionapi.ParagraphBlock:
+			richText, children, color = typed.Paragraph.RichText, typed.Paragraph.Children, typed.Paragraph.Color
+		case notionapi.Heading1Block:
+			richText, color = typed.Heading1.RichText, typed.Heading1.Color
+		case notionapi.Heading2Block:
+			richText, color = typed.Heading2.RichText, typed.Heading2.Color
+		case notionapi.Heading3Block:
+			richText, color = typed.Heading3.RichText, typed.Heading3.Color
+		case notionapi.BulletedListItemBlock:
+			richText, color = typed.BulletedListItem.RichText, typed.BulletedListItem.Color
+		case notionapi.NumberedListItemBlock:
+			richText, color = typed.NumberedListItem.RichText, typed.NumberedListItem.Color
+		case notionapi.QuoteBlock:
+			richText, color = typed.Quote.RichText, typed.Quote.Color
+		case notionapi.CalloutBlock:
+			richText, color = typed.Callout.RichText, typed.Callout.Color
+			if typed.Callout.Icon != nil {
+				extra = " icon " + string(*typed.Callout.Icon.Emoji)
+			}
+		case notionapi.ToggleBlock:
+			richText, children, color = typed.Toggle.RichText, typed.Toggle.Children, typed.Toggle.Color
+		case notionapi.ColumnListBlock:
+			children = typed.ColumnList.Children
+		case notionapi.ColumnBlock:
+			children = typed.Column.Children
+		case notionapi.ImageBlock:
+			extra = typed.Image.External.URL
+		case audioBlock:
+			extra = typed.Audio.External.URL
+		}
+
+		line := string(block.GetType())
+		if color != "" {
+			line += "(" + color + ")"
+		}
+
+		line += ":" + richTextContent(richText) + extra
+		lines = append(lines, line)
+
+		for _, child := range describeBlocks(children) {
+			lines = append(lines, "  "+child)
+		}
+	}
+
+	return lines
+}
+
+func richTextContent(richText []notionapi.RichText) string {
+	var content strings.Builder
+	for _, text := range richText {
+		content.WriteString(text.Text.Content)
+	}
+
+	return content.String()
+}
+
+func TestLoadNotionLayout(t *testing.T) {
+	t.Parallel()
+
+	layout, err := LoadNotionLayout(filepath.Join("..", "..", "..", "..", "notion_layout.example.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []*NotionSection{
+		{NotionContentImages, "", 3, false, "", "", "", 3},
+		{NotionContentPronunciation, "", 3, false, NotionBlockParagraph, "", "", 0},
+		{NotionContentAudio, "", 3, false, "", "", "", 0},
+		{NotionContentTranslation, "", 3, false, NotionBlockCallout, notionapi.ColorBlueBackground, "💡", 0},
+		{NotionContentDefinitions, "Definitions", 2, false, NotionBlockBulleted, "", "", 0},
+		{NotionContentExamples, "Examples", 3, true, NotionBlockQuote, "", "", 0},
+		{NotionContentContext, "Where I saw it", 3, false, NotionBlockParagraph, notionapi.ColorGray, "", 0},
+		{NotionContentClozes, "Fill the gap", 2, false, NotionBlockToggle, "", "", 0},
+	}
+	if !reflect.DeepEqual(layout.sections, want) {
+		t.Errorf("got sections %+v, want %+v", layout.sections, want)
+	}
+
+	if _, err = LoadNotionLayout(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("got no error for a missing file, want one")
+	}
+}
+
+func TestParseNotionLayoutFails(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		layout  string
+		wantErr error
+	}{
+		"unknown content":    {"sections: [{content: synonyms}]", errUnknownNotionContent},
+		"unknown block":      {"sections: [{content: examples, block: table}]", errUnknownNotionBlock},
+		"unknown color":      {"sections: [{content: examples, color: teal}]", errUnknownNotionColor},
+		"heading level":      {"sections: [{content: examples, heading: E, headingLevel: 4}]", errBadNotionSection},
+		"toggle, no heading": {"sections: [{content: examples, toggle: true}]", errBadNotionSection},
+		"columns of text":    {"sections: [{content: examples, columns: 2}]", errBadNotionSection},
+		"columns in toggle":  {"sections: [{content: images, heading: I, toggle: true, columns: 3}]", errBadNotionSection},
+		"block of images":    {"sections: [{content: images, block: quote}]", errBadNotionSection},
+		"block of divider":   {"sections: [{content: divider, block: paragraph}]", errBadNotionSection},
+		"toggled examples":   {"sections: [{content: examples, block: toggle}]", errBadNotionSection},
+		"no sections":        {"sections: []", errNoNotionSections},
+		"unknown key":        {"sections: [{content: examples, colour: gray}]", nil},
+		"malformed YAML":     {"sections: [", nil},
+		"not a list":         {"sections: {content: examples}", nil},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseNotionLayout([]byte(testCase.layout))
+			if err == nil || (testCase.wantErr != nil && !errors.Is(err, testCase.wantErr)) {
+				t.Errorf("got error %v, want %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotionLayoutBlocks(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		layout string
+		want   []string
+	}{
+		"default layout": {
+			layout: "",
+			want: []string{
+				"image:https://img.test/1.jpg",
+				"image:https://img.test/2.jpg",
+				"image:https://img.test/3.jpg",
+				"paragraph:/kæt/  cat",
+				"audio:https://audio.test/cat.mp3",
+				"paragraph:кошка",
+				"heading_3:noun",
+				"bulleted_list_item:a small animal — животное (synonyms: feline)",
+				"heading_3:verb",
+				"bulleted_list_item:to vomit (antonyms: swallow)",
+				"paragraph:a black cat",
+				"toggle:The _____ purrs.",
+				"  paragraph:cat",
+			},
+		},
+		"headings and toggles": {
+			layout: `sections:
+  - {content: translation, heading: Translation, headingLevel: 1, color: red}
+  - {content: examples, heading: Examples, headingLevel: 2, toggle: true, color: gray}
+  - {content: context, heading: Context}`,
+			want: []string{
+				"heading_1(red):Translation",
+				"paragraph(red):кошка",
+				"toggle(gray):Examples",
+				"  paragraph(gray):a black cat",
+				"heading_3:Context",
+				"paragraph:The cat purrs.",
+			},
+		},
+		"text block types": {
+			layout: `sections:
+  - {content: examples, block: numbered_list_item}
+  - {content: examples, block: quote}
+  - {content: translation, block: callout, icon: "💡"}
+  - {content: context, block: bulleted_list_item}
+  - {content: divider}`,
+			want: []string{
+				"numbered_list_item:a black cat",
+				"quote:a black cat",
+				"callout:кошка icon 💡",
+				"bulleted_list_item:The cat purrs.",
+				"divider:",
+			},
+		},
+		"clozes as text": {
+			layout: "sections: [{content: clozes, block: paragraph}]",
+			want:   []string{"paragraph:The _____ purrs. (cat)"},
+		},
+		"images in columns": {
+			layout: "sections: [{content: images, heading: Images, columns: 2}]",
+			want: []string{
+				"heading_3:Images",
+				"column_list:",
+				"  column:",
+				"    image:https://img.test/1.jpg",
+				"    image:https://img.test/3.jpg",
+				"  column:",
+				"    image:https://img.test/2.jpg",
+			},
+		},
+		"more columns than images": {
+			layout: "sections: [{content: images, columns: 5}]",
+			want: []string{
+				"column_list:",
+				"  column:",
+				"    image:https://img.test/1.jpg",
+				"  column:",
+				"    image:https://img.test/2.jpg",
+				"  column:",
+				"    image:https://img.test/3.jpg",
+			},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			layout := DefaultNotionLayout()
+
+			if testCase.layout != "" {
+				var err error
+				if layout, err = ParseNotionLayout([]byte(testCase.layout)); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if got := describeBlocks(layout.blocks(testNotionWord())); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("got blocks\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(testCase.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestNotionLayoutLeavesEmptySectionsOut(t *testing.T) {
+	t.Parallel()
+
+	layout, err := ParseNotionLayout([]byte(`sections:
+  - {content: images, heading: Images, columns: 3}
+  - {content: pronunciation, heading: Pronunciation}
+  - {content: audio, heading: Audio}
+  - {content: translation, heading: Translation}
+  - {content: definitions, heading: Definitions}
+  - {content: examples, heading: Examples, toggle: true}
+  - {content: clozes, heading: Clozes}
+  - {content: context, heading: Context}
+  - {content: divider}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	word := domain.NewWord("cat", "", "", nil, nil, nil, nil, 0, nil)
+
+	if got, want := describeBlocks(layout.blocks(word)), []string{"divider:"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got blocks %q, want only %q", got, want)
+	}
+}
+
+func TestNotionLayoutTruncatesLongContext(t *testing.T) {
+	t.Parallel()
+
+	sentence := strings.Repeat("я", notionMaxTextLength+10) //nolint:gomnd
+	word := domain.NewWord("cat", "", sentence, nil, nil, nil, nil, 0, nil)
+
+	layout, err := ParseNotionLayout([]byte("sections: [{content: context}]"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lines := describeBlocks(layout.blocks(word))
+	if len(lines) != 1 || len([]rune(lines[0])) != len([]rune("paragraph:"))+notionMaxTextLength {
+		t.Errorf("got %d blocks of %d characters, want one block of the max length", len(lines), len([]rune(lines[0])))
+	}
+}
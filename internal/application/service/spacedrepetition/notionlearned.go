@@ -0,0 +1,111 @@
+package spacedrepetition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+)
+
+var errNoLearnedProperties = errors.New("neither the learned checkbox nor the learned statuses are set")
+
+// LearnedWords returns titles of the pages marked as learned which were edited since the time, the zero time
+// takes all of them. The returned time is the last edit time of the latest edited page, it's the time to pass
+// on the next call. Notion rounds edit times down to minutes, so pages edited at the passed time come again.
+func (n Notion) LearnedWords(ctx context.Context, since time.Time) ([]string, time.Time, error) {
+	filter, err := n.learnedFilter(since)
+	if err != nil {
+		return nil, since, err
+	}
+
+	words := make([]string, 0)
+	latestEdit := since
+	request := &notionapi.DatabaseQueryRequest{
+		Filter: filter,
+		Sorts: []notionapi.SortObject{
+			{Property: "", Timestamp: notionapi.TimestampLastEdited, Direction: notionapi.SortOrderASC},
+		},
+		StartCursor: "",
+		PageSize:    notionMaxPageSize,
+	}
+
+	for {
+		resp, err := n.client.Database.Query(ctx, n.databaseID, request)
+		if err != nil {
+			return nil, since, fmt.Errorf("database query error: %w", err)
+		}
+
+		for i := range resp.Results {
+			if title := pageTitle(resp.Results[i], n.properties.title); title != "" {
+				words = append(words, title)
+			}
+
+			if resp.Results[i].LastEditedTime.After(latestEdit) {
+				latestEdit = resp.Results[i].LastEditedTime
+			}
+		}
+
+		if !resp.HasMore {
+			return words, latestEdit, nil
+		}
+
+		request.StartCursor = resp.NextCursor
+	}
+}
+
+// learnedFilter matches pages with the learned checkbox checked or a learned status, edited since the time.
+func (n Notion) learnedFilter(since time.Time) (notionapi.Filter, error) {
+	learned := make(notionapi.OrCompoundFilter, 0, len(n.properties.learnedStatuses)+1)
+
+	if n.properties.learned != "" {
+		learned = append(learned, notionapi.PropertyFilter{ //nolint:exhaustruct
+			Property: n.properties.learned,
+			Checkbox: &notionapi.CheckboxFilterCondition{Equals: true, DoesNotEqual: false},
+		})
+	}
+
+	if n.properties.status != "" {
+		for _, status := range n.properties.learnedStatuses {
+			learned = append(learned, notionapi.PropertyFilter{ //nolint:exhaustruct
+				Property: n.properties.status,
+				Select:   &notionapi.SelectFilterCondition{Equals: status}, //nolint:exhaustruct
+			})
+		}
+	}
+
+	if len(learned) == 0 {
+		return nil, errNoLearnedProperties
+	}
+
+	if since.IsZero() {
+		return learned, nil
+	}
+
+	sinceDate := notionapi.Date(since)
+
+	return notionapi.AndCompoundFilter{
+		notionapi.TimestampFilter{
+			Timestamp:      notionapi.TimestampLastEdited,
+			CreatedTime:    nil,
+			LastEditedTime: &notionapi.DateFilterCondition{OnOrAfter: &sinceDate}, //nolint:exhaustruct
+		},
+		learned,
+	}, nil
+}
+
+func pageTitle(page notionapi.Page, titleProperty string) string {
+	property, ok := page.Properties[titleProperty].(*notionapi.TitleProperty)
+	if !ok {
+		return ""
+	}
+
+	var title strings.Builder
+	for _, text := range property.Title {
+		title.WriteString(text.PlainText)
+	}
+
+	return strings.TrimSpace(title.String())
+}
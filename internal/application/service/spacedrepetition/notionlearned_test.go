@@ -0,0 +1,147 @@
+package spacedrepetition_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+)
+
+const learnedQuery = "POST /v1/databases/db/query"
+
+func notionStubLearnedPage(title string, lastEdited time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"object":           "page",
+		"id":               title + "-page",
+		"last_edited_time": lastEdited.Format(time.RFC3339),
+		"properties": map[string]interface{}{
+			"Name": map[string]interface{}{
+				"id":    "title",
+				"type":  "title",
+				"title": []interface{}{map[string]interface{}{"type": "text", "plain_text": title}},
+			},
+		},
+	}
+}
+
+// encodedFilter is the filter of the learned words query as JSON, for comparing it with the expected one.
+func encodedFilter(t *testing.T, body map[string]interface{}) string {
+	t.Helper()
+
+	filter, err := json.Marshal(body["filter"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(filter)
+}
+
+func TestNotionLearnedWordsReadsAllPages(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+
+	stub, httpClient := newNotionStub(t)
+	stub.learnedPages = []interface{}{
+		notionStubLearnedPage(" cat ", start.Add(time.Minute)),
+		notionStubLearnedPage("", start.Add(4*time.Minute)),
+		notionStubLearnedPage("dog", start.Add(3*time.Minute)),
+		notionStubLearnedPage("owl", start.Add(2*time.Minute)),
+	}
+
+	words, latestEdit, err := newTestNotion(httpClient, spacedrepetition.ExistingPagesSkip).LearnedWords(
+		context.Background(), time.Time{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Pages without a title are left out, their edits still move the cursor.
+	if want := []string{"cat", "dog", "owl"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("got words %q, want %q", words, want)
+	}
+
+	if want := start.Add(4 * time.Minute); !latestEdit.Equal(want) {
+		t.Errorf("got the latest edit %s, want %s", latestEdit, want)
+	}
+
+	bodies := stub.bodies[learnedQuery]
+	if len(bodies) != 2 || bodies[0]["start_cursor"] != nil || bodies[1]["start_cursor"] != "2" {
+		t.Fatalf("got queries %v, want the second one to start at the cursor of the first one", bodies)
+	}
+
+	wantFilter := `{"or":[{"checkbox":{"equals":true},"property":"Learned"},` +
+		`{"property":"Status","select":{"equals":"Known"}}]}`
+	if filter := encodedFilter(t, bodies[0]); filter != wantFilter {
+		t.Errorf("got filter %s, want %s", filter, wantFilter)
+	}
+
+	wantSorts := []interface{}{map[string]interface{}{"timestamp": "last_edited_time", "direction": "ascending"}}
+	if !reflect.DeepEqual(bodies[0]["sorts"], wantSorts) || bodies[0]["page_size"] != 100.0 {
+		t.Errorf("got sorts %v and page size %v, want the oldest edits first by 100", bodies[0]["sorts"],
+			bodies[0]["page_size"])
+	}
+}
+
+func TestNotionLearnedWordsSinceCursor(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+
+	stub, httpClient := newNotionStub(t)
+
+	words, latestEdit, err := newTestNotion(httpClient, spacedrepetition.ExistingPagesSkip).LearnedWords(
+		context.Background(), since,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(words) != 0 || !latestEdit.Equal(since) {
+		t.Errorf("got words %q and the latest edit %s, want none and the cursor kept", words, latestEdit)
+	}
+
+	wantFilter := `{"and":[{"last_edited_time":{"on_or_after":"2026-10-18T10:00:00Z"},"timestamp":"last_edited_time"},` +
+		`{"or":[{"checkbox":{"equals":true},"property":"Learned"},{"property":"Status","select":{"equals":"Known"}}]}]}`
+	if filter := encodedFilter(t, stub.bodies[learnedQuery][0]); filter != wantFilter {
+		t.Errorf("got filter %s, want %s", filter, wantFilter)
+	}
+}
+
+func TestNotionLearnedWordsFails(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, time.October, 18, 10, 0, 0, 0, time.UTC)
+
+	stub, httpClient := newNotionStub(t)
+	stub.learnedPages = []interface{}{
+		notionStubLearnedPage("cat", since.Add(time.Minute)),
+		notionStubLearnedPage("dog", since.Add(time.Minute)),
+		notionStubLearnedPage("owl", since.Add(time.Minute)),
+	}
+	// The second page of the results fails, the cursor mustn't move past the words which weren't returned.
+	stub.failingCursor = "2"
+
+	if _, latestEdit, err := newTestNotion(httpClient, spacedrepetition.ExistingPagesSkip).LearnedWords(
+		context.Background(), since,
+	); err == nil || !latestEdit.Equal(since) {
+		t.Errorf("got the latest edit %s and error %v, want the cursor kept and an error", latestEdit, err)
+	}
+
+	noLearnedProperties := spacedrepetition.NewNotion(
+		notionapi.NewClient("secret", notionapi.WithHTTPClient(httpClient)),
+		httpClient,
+		testNotionDatabaseID,
+		spacedrepetition.NewNotionProperties("Name", "", "", "", "", "", "Status", "", "", nil),
+		spacedrepetition.ExistingPagesSkip,
+		spacedrepetition.DefaultNotionLayout(),
+	)
+
+	if _, _, err := noLearnedProperties.LearnedWords(context.Background(), since); err == nil {
+		t.Error("got no error without the learned properties, want one")
+	}
+}
@@ -0,0 +1,60 @@
+package spacedrepetition
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const reviewsTarget = "Reviews"
+
+// Reviews keeps the words as cards of the built-in review, new cards are due at once.
+// Words which are already under review get the new content and keep their schedule.
+type Reviews struct {
+	cards repository.CardsInterface
+}
+
+func NewReviews(cards repository.CardsInterface) *Reviews {
+	return &Reviews{cards: cards}
+}
+
+func (r Reviews) Name() string {
+	return reviewsTarget
+}
+
+func (r Reviews) UploadWords(ctx context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	plainWords := make([]string, len(words))
+	for i := range words {
+		plainWords[i] = words[i].Word()
+	}
+
+	existingCards, err := r.cards.Cards(ctx, plainWords)
+	if err != nil {
+		return nil, fmt.Errorf("getting existing cards error: %w", err)
+	}
+
+	now := time.Now()
+	cards := make([]*domain.Card, len(words))
+	results := make([]*WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		if existingCards[i] != nil {
+			cards[i] = existingCards[i].WithWord(word)
+			results[i] = NewWordUploadResultDTO(word.Word(), UploadStatusUpdated, nil)
+
+			continue
+		}
+
+		cards[i] = domain.NewCard(word, domain.NewUnreviewedSchedule(now))
+		results[i] = NewWordUploadResultDTO(word.Word(), UploadStatusCreated, nil)
+	}
+
+	if err = r.cards.SaveCards(ctx, cards); err != nil {
+		return nil, fmt.Errorf("saving cards error: %w", err)
+	}
+
+	return []*UploadReportDTO{NewUploadReportDTO(reviewsTarget, results, "")}, nil
+}
@@ -0,0 +1,447 @@
+package spacedrepetition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	tabularTarget        = "Flashcards file"
+	tabularFilePerm      = 0o644
+	tabularFileLayout    = "20060102-150405.000000000"
+	tabularPlainListSep  = "; "
+	tabularPlainGroupSep = " / "
+	// tabularFormulaEscape makes spreadsheets take a cell as text.
+	tabularFormulaEscape = "'"
+	// tabularFormulaPrefixes start cells spreadsheets evaluate as formulas.
+	tabularFormulaPrefixes = "=+-@\t\r"
+)
+
+var (
+	errUnknownTabularColumn = errors.New("unknown column")
+	errUnknownTabularMarkup = errors.New("unknown markup")
+	errUnknownTabularPreset = errors.New("unknown preset")
+	errNoTabularColumns     = errors.New("no columns")
+)
+
+// TabularColumn is a word field a column is filled with.
+type TabularColumn string
+
+const (
+	TabularColumnWord          TabularColumn = "word"
+	TabularColumnTranslation   TabularColumn = "translation"
+	TabularColumnPartOfSpeech  TabularColumn = "partOfSpeech"
+	TabularColumnPronunciation TabularColumn = "pronunciation"
+	TabularColumnDefinitions   TabularColumn = "definitions"
+	TabularColumnExamples      TabularColumn = "examples"
+	TabularColumnContext       TabularColumn = "context"
+	TabularColumnImages        TabularColumn = "images"
+	TabularColumnAudio         TabularColumn = "audio"
+	TabularColumnFrequencyRank TabularColumn = "frequencyRank"
+	TabularColumnTags          TabularColumn = "tags"
+	TabularColumnClozes        TabularColumn = "clozes"
+)
+
+// ParseTabularColumns parses comma separated column names.
+func ParseTabularColumns(spec string) ([]TabularColumn, error) {
+	columns := make([]TabularColumn, 0)
+
+	for _, name := range strings.Split(spec, ",") {
+		column := TabularColumn(strings.TrimSpace(name))
+
+		switch column {
+		case TabularColumnWord, TabularColumnTranslation, TabularColumnPartOfSpeech, TabularColumnPronunciation,
+			TabularColumnDefinitions, TabularColumnExamples, TabularColumnContext, TabularColumnImages,
+			TabularColumnAudio, TabularColumnFrequencyRank, TabularColumnTags, TabularColumnClozes:
+			columns = append(columns, column)
+		case "":
+		default:
+			return nil, fmt.Errorf("%w `%s`", errUnknownTabularColumn, column)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, errNoTabularColumns
+	}
+
+	return columns, nil
+}
+
+// TabularMarkup is how lists, like definitions and examples, are formatted inside a cell and how text is escaped.
+type TabularMarkup string
+
+const (
+	TabularMarkupPlain    TabularMarkup = "plain"
+	TabularMarkupHTML     TabularMarkup = "html"
+	TabularMarkupMarkdown TabularMarkup = "markdown"
+)
+
+func ParseTabularMarkup(markup string) (TabularMarkup, error) {
+	switch parsed := TabularMarkup(markup); parsed {
+	case TabularMarkupPlain, TabularMarkupHTML, TabularMarkupMarkdown:
+		return parsed, nil
+	default:
+		return "", fmt.Errorf("%w `%s`", errUnknownTabularMarkup, markup)
+	}
+}
+
+// TabularFormat describes the file. Quoted cells follow RFC 4180, if quoting is off, separators and line breaks
+// inside cells are replaced by spaces, as importers like Quizlet don't support quotes.
+// If formulas are escaped, cells starting like a formula are prefixed with an apostrophe, so a spreadsheet
+// opening the file doesn't run text from dictionaries or the user's sources as formulas.
+type TabularFormat struct {
+	columns        []TabularColumn
+	fieldSeparator string
+	rowSeparator   string
+	markup         TabularMarkup
+	header         bool
+	quote          bool
+	escapeFormulas bool
+}
+
+func NewTabularFormat(
+	columns []TabularColumn,
+	fieldSeparator, rowSeparator string,
+	markup TabularMarkup,
+	header, quote, escapeFormulas bool,
+) *TabularFormat {
+	return &TabularFormat{
+		columns:        columns,
+		fieldSeparator: fieldSeparator,
+		rowSeparator:   rowSeparator,
+		markup:         markup,
+		header:         header,
+		quote:          quote,
+		escapeFormulas: escapeFormulas,
+	}
+}
+
+// TabularPresetFormat returns the format of a preset: csv, tsv or quizlet (a term and a definition per line).
+// The csv and tsv files are likely opened in spreadsheets, so their formulas are escaped.
+func TabularPresetFormat(preset string) (*TabularFormat, error) {
+	switch preset {
+	case "csv":
+		return NewTabularFormat(
+			[]TabularColumn{
+				TabularColumnWord, TabularColumnPronunciation, TabularColumnTranslation, TabularColumnDefinitions,
+				TabularColumnExamples, TabularColumnContext, TabularColumnTags,
+			},
+			",", "\n", TabularMarkupPlain, true, true, true,
+		), nil
+	case "tsv":
+		return NewTabularFormat(
+			[]TabularColumn{
+				TabularColumnWord, TabularColumnPronunciation, TabularColumnTranslation, TabularColumnDefinitions,
+				TabularColumnExamples, TabularColumnContext, TabularColumnTags,
+			},
+			"\t", "\n", TabularMarkupPlain, true, false, true,
+		), nil
+	case "quizlet":
+		return NewTabularFormat(
+			[]TabularColumn{TabularColumnWord, TabularColumnDefinitions},
+			"\t", "\n", TabularMarkupPlain, false, false, false,
+		), nil
+	default:
+		return nil, fmt.Errorf("%w `%s`", errUnknownTabularPreset, preset)
+	}
+}
+
+func (f TabularFormat) Columns() []TabularColumn {
+	return f.columns
+}
+
+func (f TabularFormat) FieldSeparator() string {
+	return f.fieldSeparator
+}
+
+func (f TabularFormat) RowSeparator() string {
+	return f.rowSeparator
+}
+
+func (f TabularFormat) Markup() TabularMarkup {
+	return f.markup
+}
+
+func (f TabularFormat) Header() bool {
+	return f.header
+}
+
+func (f TabularFormat) Quote() bool {
+	return f.quote
+}
+
+func (f TabularFormat) EscapeFormulas() bool {
+	return f.escapeFormulas
+}
+
+// extension is csv for commas and tsv for tabs, other separators get a txt file.
+func (f TabularFormat) extension() string {
+	switch f.fieldSeparator {
+	case ",":
+		return ".csv"
+	case "\t":
+		return ".tsv"
+	default:
+		return ".txt"
+	}
+}
+
+// Tabular writes the words into a delimited text file, the file is available at the download URL of the report.
+type Tabular struct {
+	format          *TabularFormat
+	exportDir       string
+	downloadBaseURL string
+}
+
+func NewTabular(format *TabularFormat, exportDir, downloadBaseURL string) (*Tabular, error) {
+	if err := os.MkdirAll(exportDir, exportDirPerm); err != nil {
+		return nil, fmt.Errorf("creating export dir `%s` error: %w", exportDir, err)
+	}
+
+	return &Tabular{
+		format:          format,
+		exportDir:       exportDir,
+		downloadBaseURL: strings.TrimRight(downloadBaseURL, "/"),
+	}, nil
+}
+
+func (t Tabular) Name() string {
+	return tabularTarget
+}
+
+func (t Tabular) UploadWords(_ context.Context, words []*domain.Word) ([]*UploadReportDTO, error) {
+	rows := make([][]string, 0, len(words)+1)
+
+	if t.format.header {
+		header := make([]string, len(t.format.columns))
+		for i, column := range t.format.columns {
+			header[i] = string(column)
+		}
+
+		rows = append(rows, header)
+	}
+
+	results := make([]*WordUploadResultDTO, len(words))
+
+	for i, word := range words {
+		row := make([]string, len(t.format.columns))
+		for j, column := range t.format.columns {
+			row[j] = t.cell(word, column)
+		}
+
+		rows = append(rows, row)
+		results[i] = NewWordUploadResultDTO(word.Word(), UploadStatusCreated, nil)
+	}
+
+	fileName := "vocaboost-" + time.Now().Format(tabularFileLayout) + t.format.extension()
+
+	if err := os.WriteFile(
+		filepath.Join(t.exportDir, fileName), []byte(t.render(rows)), tabularFilePerm,
+	); err != nil {
+		return nil, fmt.Errorf("writing file `%s` error: %w", fileName, err)
+	}
+
+	return []*UploadReportDTO{
+		NewUploadReportDTO(tabularTarget, results, t.downloadBaseURL+"/"+url.PathEscape(fileName)),
+	}, nil
+}
+
+func (t Tabular) render(rows [][]string) string {
+	var content strings.Builder
+
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				content.WriteString(t.format.fieldSeparator)
+			}
+
+			content.WriteString(t.separateCell(cell))
+		}
+
+		content.WriteString(t.format.rowSeparator)
+	}
+
+	return content.String()
+}
+
+// separateCell keeps separators inside the cell from splitting it and formulas in it from being evaluated.
+func (t Tabular) separateCell(cell string) string {
+	if t.format.escapeFormulas && cell != "" && strings.ContainsAny(cell[:1], tabularFormulaPrefixes) {
+		cell = tabularFormulaEscape + cell
+	}
+
+	specials := []string{t.format.fieldSeparator, t.format.rowSeparator, "\n", "\r"}
+
+	if t.format.quote {
+		for _, special := range append(specials, `"`) {
+			if special != "" && strings.Contains(cell, special) {
+				return `"` + strings.ReplaceAll(cell, `"`, `""`) + `"`
+			}
+		}
+
+		return cell
+	}
+
+	for _, special := range specials {
+		if special != "" {
+			cell = strings.ReplaceAll(cell, special, " ")
+		}
+	}
+
+	return cell
+}
+
+func (t Tabular) cell(word *domain.Word, column TabularColumn) string {
+	switch column {
+	case TabularColumnWord:
+		return t.escape(word.Word())
+	case TabularColumnTranslation:
+		return t.escape(word.Translation())
+	case TabularColumnPartOfSpeech:
+		return t.escape(strings.Join(word.PartsOfSpeech(), ", "))
+	case TabularColumnPronunciation:
+		if word.Pronunciation() == nil || word.Pronunciation().IPA() == "" {
+			return ""
+		}
+
+		return t.escape("/" + strings.Trim(word.Pronunciation().IPA(), "/") + "/")
+	case TabularColumnDefinitions:
+		return t.definitions(word)
+	case TabularColumnExamples:
+		return t.list(word.Examples())
+	case TabularColumnContext:
+		return t.escape(word.SourceSentence())
+	case TabularColumnImages:
+		return t.images(word.ImageURLs())
+	case TabularColumnAudio:
+		if word.Pronunciation() == nil {
+			return ""
+		}
+
+		return word.Pronunciation().AudioURL()
+	case TabularColumnFrequencyRank:
+		if word.FrequencyRank() == 0 {
+			return ""
+		}
+
+		return strconv.Itoa(word.FrequencyRank())
+	case TabularColumnTags:
+		return t.escape(strings.Join(word.Tags(), ", "))
+	case TabularColumnClozes:
+		clozes := make([]string, len(word.Clozes()))
+		for i, cloze := range word.Clozes() {
+			clozes[i] = cloze.Blanked()
+		}
+
+		return t.list(clozes)
+	default:
+		return ""
+	}
+}
+
+// definitions are grouped by part of speech, a group is headed by the part of speech.
+func (t Tabular) definitions(word *domain.Word) string {
+	groups := make([]string, 0, len(word.DefinitionsByPartOfSpeech()))
+
+	for _, group := range word.DefinitionsByPartOfSpeech() {
+		glosses := make([]string, len(group.Definitions()))
+		for i, definition := range group.Definitions() {
+			glosses[i] = definition.Gloss()
+			if definition.Translation() != "" {
+				glosses[i] += " (" + definition.Translation() + ")"
+			}
+		}
+
+		if group.PartOfSpeech() == "" {
+			groups = append(groups, t.list(glosses))
+
+			continue
+		}
+
+		switch t.format.markup {
+		case TabularMarkupHTML:
+			groups = append(groups, "<i>"+t.escape(group.PartOfSpeech())+"</i>"+t.list(glosses))
+		case TabularMarkupMarkdown:
+			groups = append(groups, "*"+t.escape(group.PartOfSpeech())+"*\n"+t.list(glosses))
+		case TabularMarkupPlain:
+			groups = append(groups, group.PartOfSpeech()+": "+t.list(glosses))
+		}
+	}
+
+	switch t.format.markup {
+	case TabularMarkupHTML:
+		return strings.Join(groups, "")
+	case TabularMarkupMarkdown:
+		return strings.Join(groups, "\n\n")
+	case TabularMarkupPlain:
+		return strings.Join(groups, tabularPlainGroupSep)
+	default:
+		return ""
+	}
+}
+
+func (t Tabular) list(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+
+	escaped := make([]string, len(items))
+	for i := range items {
+		escaped[i] = t.escape(items[i])
+	}
+
+	switch t.format.markup {
+	case TabularMarkupHTML:
+		return "<ul><li>" + strings.Join(escaped, "</li><li>") + "</li></ul>"
+	case TabularMarkupMarkdown:
+		return "- " + strings.Join(escaped, "\n- ")
+	case TabularMarkupPlain:
+		return strings.Join(escaped, tabularPlainListSep)
+	default:
+		return ""
+	}
+}
+
+func (t Tabular) images(imageURLs []string) string {
+	images := make([]string, len(imageURLs))
+
+	for i, imageURL := range imageURLs {
+		switch t.format.markup {
+		case TabularMarkupHTML:
+			images[i] = `<img src="` + html.EscapeString(imageURL) + `">`
+		case TabularMarkupMarkdown:
+			images[i] = "![](" + strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(imageURL) + ")"
+		case TabularMarkupPlain:
+			images[i] = imageURL
+		}
+	}
+
+	return strings.Join(images, " ")
+}
+
+var tabularMarkdownEscaper = strings.NewReplacer( //nolint:gochecknoglobals
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+func (t Tabular) escape(text string) string {
+	switch t.format.markup {
+	case TabularMarkupHTML:
+		return html.EscapeString(text)
+	case TabularMarkupMarkdown:
+		return tabularMarkdownEscaper.Replace(text)
+	case TabularMarkupPlain:
+		return text
+	default:
+		return text
+	}
+}
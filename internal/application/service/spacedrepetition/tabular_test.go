@@ -0,0 +1,217 @@
+package spacedrepetition_test
+
+import (
+	"context"
+	"flag"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files with the current output") //nolint:gochecknoglobals
+
+// newTabularTestWords have separators, quotes, line breaks and formula-like text in their fields.
+func newTabularTestWords() []*domain.Word {
+	return []*domain.Word{
+		domain.NewWord(
+			"cat",
+			"кошка",
+			"=HYPERLINK(\"https://evil.test\", \"cat\")",
+			[]*domain.Definition{
+				domain.NewDefinition("noun", "a small animal", "животное", nil, nil),
+				domain.NewDefinition("noun", "a jazz player", "", nil, nil),
+				domain.NewDefinition("verb", "to vomit", "", nil, nil),
+			},
+			[]string{`"Cats," she said, "purr."`, "a black\ncat"},
+			[]*domain.Image{domain.NewImage("https://img.test/cat (1).jpg", "")},
+			domain.NewPronunciation("kæt", nil, "https://audio.test/cat.mp3", ""),
+			1200, //nolint:gomnd
+			[]string{"@home", "pets"},
+		).WithClozes([]*domain.Cloze{domain.NewCloze([]string{"a black ", ""}, []string{"cat"})}),
+		domain.NewWord(
+			"dog",
+			"",
+			"",
+			[]*domain.Definition{domain.NewDefinition("", "-a loyal pet", "", nil, nil)},
+			nil,
+			nil,
+			nil,
+			0,
+			[]string{"+1"},
+		),
+	}
+}
+
+// exportTabular uploads the words and reads the file written at the download URL of the report.
+func exportTabular(t *testing.T, format *spacedrepetition.TabularFormat) (string, string) {
+	t.Helper()
+
+	exportDir := filepath.Join(t.TempDir(), "exports")
+
+	tabular, err := spacedrepetition.NewTabular(format, exportDir, "https://vocaboost.example/exports/")
+	if err != nil {
+		t.Fatalf("creating tabular error: %s", err)
+	}
+
+	reports, err := tabular.UploadWords(context.Background(), newTabularTestWords())
+	if err != nil {
+		t.Fatalf("uploading words error: %s", err)
+	}
+
+	for _, result := range reports[0].Words() {
+		if result.Status() != spacedrepetition.UploadStatusCreated {
+			t.Errorf("got word `%s` status `%s`, want created", result.Word(), result.Status())
+		}
+	}
+
+	downloadURL, err := url.Parse(reports[0].DownloadURL())
+	if err != nil || path.Dir(downloadURL.Path) != "/exports" {
+		t.Fatalf("got download URL `%s`, want a file in the exports", reports[0].DownloadURL())
+	}
+
+	content, err := os.ReadFile(filepath.Join(exportDir, path.Base(downloadURL.Path)))
+	if err != nil {
+		t.Fatalf("reading the exported file error: %s", err)
+	}
+
+	return string(content), path.Ext(downloadURL.Path)
+}
+
+// assertGolden compares the content with the golden file, `go test -update` rewrites the file.
+func assertGolden(t *testing.T, goldenPath, content string) {
+	t.Helper()
+
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing golden file error: %s", err)
+		}
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("reading golden file error: %s", err)
+	}
+
+	if content != string(golden) {
+		t.Errorf("got\n%s\nwant the content of %s\n%s", content, goldenPath, golden)
+	}
+}
+
+func TestTabularPresets(t *testing.T) {
+	t.Parallel()
+
+	for preset, wantExtension := range map[string]string{"csv": ".csv", "tsv": ".tsv", "quizlet": ".tsv"} {
+		preset, wantExtension := preset, wantExtension
+
+		t.Run(preset, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := spacedrepetition.TabularPresetFormat(preset)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, extension := exportTabular(t, format)
+			if extension != wantExtension {
+				t.Errorf("got extension %q, want %q", extension, wantExtension)
+			}
+
+			assertGolden(t, filepath.Join("testdata", "tabular", preset+wantExtension), content)
+		})
+	}
+
+	if _, err := spacedrepetition.TabularPresetFormat("xlsx"); err == nil {
+		t.Error("got no error for an unknown preset, want one")
+	}
+}
+
+func TestTabularMarkup(t *testing.T) {
+	t.Parallel()
+
+	columns := []spacedrepetition.TabularColumn{
+		spacedrepetition.TabularColumnWord,
+		spacedrepetition.TabularColumnPartOfSpeech,
+		spacedrepetition.TabularColumnDefinitions,
+		spacedrepetition.TabularColumnExamples,
+		spacedrepetition.TabularColumnImages,
+		spacedrepetition.TabularColumnAudio,
+		spacedrepetition.TabularColumnFrequencyRank,
+		spacedrepetition.TabularColumnClozes,
+	}
+
+	for _, markup := range []spacedrepetition.TabularMarkup{
+		spacedrepetition.TabularMarkupHTML, spacedrepetition.TabularMarkupMarkdown,
+	} {
+		markup := markup
+
+		t.Run(string(markup), func(t *testing.T) {
+			t.Parallel()
+
+			content, extension := exportTabular(
+				t, spacedrepetition.NewTabularFormat(columns, ";", "\n\n", markup, false, true, false),
+			)
+			if extension != ".txt" {
+				t.Errorf("got extension %q, want .txt for other separators", extension)
+			}
+
+			assertGolden(t, filepath.Join("testdata", "tabular", string(markup)+extension), content)
+		})
+	}
+}
+
+func TestTabularEscapesFormulas(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		escapeFormulas bool
+		want           string
+	}{
+		"escaped": {
+			escapeFormulas: true,
+			want:           "'=HYPERLINK(\"https://evil.test\", \"cat\")|'@home, pets\n|'+1\n",
+		},
+		"kept": {
+			escapeFormulas: false,
+			want:           "=HYPERLINK(\"https://evil.test\", \"cat\")|@home, pets\n|+1\n",
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			content, _ := exportTabular(t, spacedrepetition.NewTabularFormat(
+				[]spacedrepetition.TabularColumn{spacedrepetition.TabularColumnContext, spacedrepetition.TabularColumnTags},
+				"|", "\n", spacedrepetition.TabularMarkupPlain, false, false, testCase.escapeFormulas,
+			))
+			if content != testCase.want {
+				t.Errorf("got %q, want %q", content, testCase.want)
+			}
+		})
+	}
+}
+
+func TestParseTabularColumns(t *testing.T) {
+	t.Parallel()
+
+	columns, err := spacedrepetition.ParseTabularColumns(" word, ,definitions,clozes")
+	if err != nil || len(columns) != 3 || columns[0] != spacedrepetition.TabularColumnWord ||
+		columns[2] != spacedrepetition.TabularColumnClozes {
+		t.Errorf("got columns %q and error %v", columns, err)
+	}
+
+	for _, spec := range []string{"word,synonyms", " , "} {
+		if _, err = spacedrepetition.ParseTabularColumns(spec); err == nil {
+			t.Errorf("got no error for %q, want one", spec)
+		}
+	}
+
+	if _, err = spacedrepetition.ParseTabularMarkup("rst"); err == nil {
+		t.Error("got no error for an unknown markup, want one")
+	}
+}
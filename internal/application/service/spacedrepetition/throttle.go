@@ -0,0 +1,173 @@
+package spacedrepetition
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ThrottledTransport spaces requests out to stay under the rate limit and retries requests which were rate limited
+// with exponential backoff, `Retry-After` is honored if it asks to wait longer. Idempotent requests which failed
+// on the server side are retried as well, others like `POST /pages` aren't, the server might have processed them
+// and a retry would create a duplicate. Requests failed on the network level aren't retried for the same reason.
+type ThrottledTransport struct {
+	base       http.RoundTripper
+	interval   time.Duration
+	maxRetries int
+	backoff    time.Duration
+
+	mu          sync.Mutex
+	nextRequest time.Time
+}
+
+func NewThrottledTransport(
+	base http.RoundTripper,
+	requestsPerSecond float64,
+	maxRetries int,
+	backoff time.Duration,
+) *ThrottledTransport {
+	return &ThrottledTransport{
+		base:        base,
+		interval:    time.Duration(float64(time.Second) / requestsPerSecond),
+		maxRetries:  maxRetries,
+		backoff:     backoff,
+		mu:          sync.Mutex{},
+		nextRequest: time.Time{},
+	}
+}
+
+func (tt *ThrottledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := tt.wait(req, tt.reserve()); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := rewound(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := tt.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, fmt.Errorf("round trip error: %w", err)
+		}
+
+		if !retryable(req.Method, resp.StatusCode) || attempt >= tt.maxRetries {
+			return resp, nil
+		}
+
+		delay := tt.backoff << attempt
+		if retryAfter := retryAfterDelay(resp); retryAfter > delay {
+			delay = retryAfter
+		}
+
+		log.Printf(
+			"request `%s %s` got status %d, retrying in %s", req.Method, req.URL.Path, resp.StatusCode, delay,
+		)
+
+		drainAndClose(resp)
+
+		if err = tt.wait(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// reserve takes the next free slot and returns how long to wait for it.
+func (tt *ThrottledTransport) reserve() time.Duration {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	now := time.Now()
+	if tt.nextRequest.Before(now) {
+		tt.nextRequest = now
+	}
+
+	delay := tt.nextRequest.Sub(now)
+	tt.nextRequest = tt.nextRequest.Add(tt.interval)
+
+	return delay
+}
+
+func (tt *ThrottledTransport) wait(req *http.Request, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return fmt.Errorf("waiting for the request turn interrupted: %w", req.Context().Err())
+	}
+}
+
+// rewound returns the request with a fresh body for a retry, the body of the first attempt is the original one.
+func rewound(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errBodyNotRewindable
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("getting the request body error: %w", err)
+	}
+
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+
+	return retryReq, nil
+}
+
+// retryable is true for rate limited requests, which weren't processed, and for idempotent requests failed
+// on the server side.
+func retryable(method string, statusCode int) bool {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return true
+	case statusCode >= http.StatusInternalServerError:
+		return idempotent(method)
+	default:
+		return false
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfterDelay reads `Retry-After` in seconds, zero if it's absent or malformed.
+func retryAfterDelay(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+func drainAndClose(resp *http.Response) {
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		log.Printf("body draining error: %s", err.Error())
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("body closing error: %s", err.Error())
+	}
+}
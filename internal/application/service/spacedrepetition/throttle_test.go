@@ -0,0 +1,170 @@
+package spacedrepetition_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
+)
+
+const (
+	testRequestsPerSecond = 1000
+	testMaxRetries        = 2
+	testRetryBackoff      = time.Millisecond
+)
+
+// statusesStub responds with the statuses in order and then with 200, the bodies it's got are recorded.
+type statusesStub struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func (s *statusesStub) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bodies = append(s.bodies, string(body))
+
+	status := http.StatusOK
+	if len(s.bodies) <= len(s.statuses) {
+		status = s.statuses[len(s.bodies)-1]
+	}
+
+	w.WriteHeader(status)
+}
+
+func TestThrottledTransportRetries(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		method     string
+		statuses   []int
+		wantStatus int
+		attempts   int
+	}{
+		"rate limited POST": {
+			method:     http.MethodPost,
+			statuses:   []int{http.StatusTooManyRequests},
+			wantStatus: http.StatusOK,
+			attempts:   2,
+		},
+		"POST failed on the server": {
+			method:     http.MethodPost,
+			statuses:   []int{http.StatusInternalServerError},
+			wantStatus: http.StatusInternalServerError,
+			attempts:   1,
+		},
+		"PATCH failed on the server": {
+			method:     http.MethodPatch,
+			statuses:   []int{http.StatusBadGateway},
+			wantStatus: http.StatusBadGateway,
+			attempts:   1,
+		},
+		"GET failed on the server": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			wantStatus: http.StatusOK,
+			attempts:   3,
+		},
+		"retries run out": {
+			method: http.MethodGet,
+			statuses: []int{
+				http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests,
+			},
+			wantStatus: http.StatusTooManyRequests,
+			attempts:   3,
+		},
+		"client error": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusBadRequest},
+			wantStatus: http.StatusBadRequest,
+			attempts:   1,
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stub := &statusesStub{mu: sync.Mutex{}, statuses: testCase.statuses, bodies: nil}
+			server := httptest.NewServer(http.HandlerFunc(stub.serve))
+			defer server.Close()
+
+			client := &http.Client{ //nolint:exhaustruct
+				Transport: spacedrepetition.NewThrottledTransport(
+					http.DefaultTransport, testRequestsPerSecond, testMaxRetries, testRetryBackoff,
+				),
+			}
+
+			req, err := http.NewRequestWithContext(
+				context.Background(), testCase.method, server.URL+"/pages", strings.NewReader(`{"word":"cat"}`),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if err = resp.Body.Close(); err != nil {
+				t.Fatalf("body closing error: %s", err)
+			}
+
+			if resp.StatusCode != testCase.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, testCase.wantStatus)
+			}
+
+			if len(stub.bodies) != testCase.attempts {
+				t.Fatalf("got %d attempts, want %d", len(stub.bodies), testCase.attempts)
+			}
+
+			for i, body := range stub.bodies {
+				if body != `{"word":"cat"}` {
+					t.Errorf("attempt %d: got body %q, want the original one", i+1, body)
+				}
+			}
+		})
+	}
+}
+
+func TestThrottledTransportStopsWaitingOnCancel(t *testing.T) {
+	t.Parallel()
+
+	stub := &statusesStub{mu: sync.Mutex{}, statuses: []int{http.StatusTooManyRequests}, bodies: nil}
+	server := httptest.NewServer(http.HandlerFunc(stub.serve))
+	defer server.Close()
+
+	client := &http.Client{ //nolint:exhaustruct
+		Transport: spacedrepetition.NewThrottledTransport(http.DefaultTransport, testRequestsPerSecond, 1, time.Hour),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, http.NoBody)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	resp, err := client.Do(req)
+	if err == nil {
+		_ = resp.Body.Close()
+
+		t.Fatal("got no error, want the wait for the retry interrupted")
+	}
+
+	if len(stub.bodies) != 1 {
+		t.Errorf("got %d attempts, want 1", len(stub.bodies))
+	}
+}
@@ -2,4 +2,6 @@ package textparser
 
 type Interface interface {
 	ExtractWords(text string) ([]string, error)
+	// FindSentence returns the first sentence of the text containing the word, empty if there is none.
+	FindSentence(text, word string) string
 }
@@ -5,6 +5,12 @@ import (
 	"strings"
 )
 
+var (
+	// Sentences end with punctuation or a blank line, single line breaks are just wrapping.
+	sentenceRegexp  = regexp.MustCompile(`[^.!?]+[.!?]*`)
+	paragraphRegexp = regexp.MustCompile(`\n\s*\n`)
+)
+
 type V1 struct{}
 
 func (tp V1) ExtractWords(text string) ([]string, error) {
@@ -13,3 +19,17 @@ func (tp V1) ExtractWords(text string) ([]string, error) {
 
 	return strings.Fields(text), nil
 }
+
+func (tp V1) FindSentence(text, word string) string {
+	wordRegexp := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+
+	for _, paragraph := range paragraphRegexp.Split(text, -1) {
+		for _, sentence := range sentenceRegexp.FindAllString(paragraph, -1) {
+			if wordRegexp.MatchString(sentence) {
+				return strings.Join(strings.Fields(sentence), " ")
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,68 @@
+package translation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// BilingualDictionary translates words with a bundled tab separated file of `word<TAB>translation` lines.
+// Lines starting with `#` are ignored. Texts missing from the file, e.g. whole definitions, aren't translated.
+type BilingualDictionary struct {
+	entries map[string]string
+}
+
+func NewBilingualDictionary(path string) (*BilingualDictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file `%s` error: %w", path, err)
+	}
+
+	defer func() {
+		if err = file.Close(); err != nil {
+			log.Printf("file closing error: %s", err.Error())
+		}
+	}()
+
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		word, translation, found := strings.Cut(line, "\t")
+		if !found {
+			continue
+		}
+
+		word = strings.ToLower(strings.TrimSpace(word))
+		if existing, exists := entries[word]; exists {
+			entries[word] = existing + ", " + strings.TrimSpace(translation)
+
+			continue
+		}
+
+		entries[word] = strings.TrimSpace(translation)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning file `%s` error: %w", path, err)
+	}
+
+	return &BilingualDictionary{entries: entries}, nil
+}
+
+func (bd BilingualDictionary) Translate(_ context.Context, texts []string) ([]string, error) {
+	translations := make([]string, len(texts))
+	for i := range texts {
+		translations[i] = bd.entries[strings.ToLower(strings.TrimSpace(texts[i]))]
+	}
+
+	return translations, nil
+}
@@ -0,0 +1,39 @@
+package translation_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/translation"
+)
+
+func TestBilingualDictionaryTranslate(t *testing.T) {
+	t.Parallel()
+
+	dictionary, err := translation.NewBilingualDictionary(filepath.Join("testdata", "en-de.tsv"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	translations, err := dictionary.Translate(
+		context.Background(), []string{" CAT", "dog", "small animal", "purr", "a small animal", "no translation here"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Entries of a word are joined, commented out and malformed lines are skipped, other texts aren't translated.
+	if want := []string{"Katze, Kater", "Hund", "Kleintier", "", "", ""}; !reflect.DeepEqual(translations, want) {
+		t.Errorf("got translations %q, want %q", translations, want)
+	}
+}
+
+func TestNewBilingualDictionaryFails(t *testing.T) {
+	t.Parallel()
+
+	if _, err := translation.NewBilingualDictionary(filepath.Join("testdata", "missing.tsv")); err == nil {
+		t.Error("got no error for a missing file, want one")
+	}
+}
@@ -0,0 +1,112 @@
+package translation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	libreTranslateAPICallURLTemplate = "%s/translate"
+	libreTranslateSourceLanguage     = "en"
+	libreTranslateFormat             = "text"
+)
+
+var (
+	errUnexpectedStatus            = errors.New("unexpected response status")
+	errBadTranslatedTextsCount     = errors.New("bad translated texts count")
+	errLibreTranslateResponseError = errors.New("LibreTranslate response error")
+)
+
+// LibreTranslate works with any LibreTranslate-compatible HTTP API, including a locally running instance.
+type LibreTranslate struct {
+	httpClient *http.Client
+	baseURL,
+	apiKey,
+	targetLanguage string
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"` //nolint:tagliatelle
+	Error          string   `json:"error"`
+}
+
+func NewLibreTranslate(httpClient *http.Client, baseURL, apiKey, targetLanguage string) *LibreTranslate {
+	return &LibreTranslate{
+		httpClient:     httpClient,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		apiKey:         apiKey,
+		targetLanguage: targetLanguage,
+	}
+}
+
+func (lt LibreTranslate) Translate(ctx context.Context, texts []string) ([]string, error) {
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+
+	body, err := json.Marshal(libreTranslateRequest{
+		Q:      texts,
+		Source: libreTranslateSourceLanguage,
+		Target: lt.targetLanguage,
+		Format: libreTranslateFormat,
+		APIKey: lt.apiKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("request body encoding error: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, fmt.Sprintf(libreTranslateAPICallURLTemplate, lt.baseURL), bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creation request error: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := lt.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request execution error: %w", err)
+	}
+
+	defer func() {
+		if err = resp.Body.Close(); err != nil {
+			log.Printf("body closing error: %s", err.Error())
+		}
+	}()
+
+	translation := new(libreTranslateResponse)
+	if err = json.NewDecoder(resp.Body).Decode(translation); err != nil {
+		return nil, fmt.Errorf("response decoding to translation error: %w", err)
+	}
+
+	if translation.Error != "" {
+		return nil, fmt.Errorf("%w: %s", errLibreTranslateResponseError, translation.Error)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errUnexpectedStatus, resp.StatusCode)
+	}
+
+	if len(translation.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf(
+			"%w, expected: %d, got: %d", errBadTranslatedTextsCount, len(texts), len(translation.TranslatedText),
+		)
+	}
+
+	return translation.TranslatedText, nil
+}
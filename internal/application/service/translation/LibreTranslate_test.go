@@ -0,0 +1,142 @@
+package translation_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application/service/translation"
+)
+
+// newLibreTranslateStub answers with the status and the body, the decoded request bodies are recorded.
+func newLibreTranslateStub(t *testing.T, status int, body string) (*httptest.Server, *[]map[string]interface{}) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []map[string]interface{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := make(map[string]interface{})
+		if r.Method != http.MethodPost || r.URL.Path != "/translate" ||
+			r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"bad request"}`))
+
+			return
+		}
+
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestLibreTranslateTranslate(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		apiKey      string
+		wantRequest map[string]interface{}
+	}{
+		"with API key": {
+			apiKey: "test-key",
+			wantRequest: map[string]interface{}{
+				"q": []interface{}{"cat", "a small animal"}, "source": "en", "target": "de", "format": "text",
+				"api_key": "test-key",
+			},
+		},
+		// A local instance needs no key, it isn't sent at all.
+		"without API key": {
+			apiKey: "",
+			wantRequest: map[string]interface{}{
+				"q": []interface{}{"cat", "a small animal"}, "source": "en", "target": "de", "format": "text",
+			},
+		},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, requests := newLibreTranslateStub(
+				t, http.StatusOK, `{"translatedText":["Katze","ein kleines Tier"]}`,
+			)
+
+			translations, err := translation.NewLibreTranslate(server.Client(), server.URL+"/", testCase.apiKey, "de").
+				Translate(context.Background(), []string{"cat", "a small animal"})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if want := []string{"Katze", "ein kleines Tier"}; !reflect.DeepEqual(translations, want) {
+				t.Errorf("got translations %q, want %q", translations, want)
+			}
+
+			if len(*requests) != 1 || !reflect.DeepEqual((*requests)[0], testCase.wantRequest) {
+				t.Errorf("got requests %v, want %v", *requests, testCase.wantRequest)
+			}
+		})
+	}
+}
+
+func TestLibreTranslateTranslatesNothingWithoutCall(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newLibreTranslateStub(t, http.StatusOK, `{"translatedText":[]}`)
+
+	translations, err := translation.NewLibreTranslate(server.Client(), server.URL, "", "de").
+		Translate(context.Background(), nil)
+	if err != nil || len(translations) != 0 || len(*requests) != 0 {
+		t.Errorf("got translations %q, error %v and %d requests, want nothing", translations, err, len(*requests))
+	}
+}
+
+func TestLibreTranslateFails(t *testing.T) {
+	t.Parallel()
+
+	for name, testCase := range map[string]struct {
+		status int
+		body   string
+	}{
+		"error response":       {http.StatusForbidden, `{"error":"Invalid API key"}`},
+		"unexpected status":    {http.StatusInternalServerError, `{}`},
+		"malformed response":   {http.StatusOK, `<html>`},
+		"fewer translations":   {http.StatusOK, `{"translatedText":["Katze"]}`},
+		"single text response": {http.StatusOK, `{"translatedText":"Katze"}`},
+	} {
+		testCase := testCase
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, _ := newLibreTranslateStub(t, testCase.status, testCase.body)
+
+			if _, err := translation.NewLibreTranslate(server.Client(), server.URL, "", "de").Translate(
+				context.Background(), []string{"cat", "dog"},
+			); err == nil {
+				t.Error("got no error, want one")
+			}
+		})
+	}
+
+	server, _ := newLibreTranslateStub(t, http.StatusOK, `{}`)
+	server.Close()
+
+	if _, err := translation.NewLibreTranslate(server.Client(), server.URL, "", "de").Translate(
+		context.Background(), []string{"cat"},
+	); err == nil {
+		t.Error("got no error for an unreachable server, want one")
+	}
+}
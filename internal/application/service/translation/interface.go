@@ -0,0 +1,8 @@
+package translation
+
+import "context"
+
+type Interface interface {
+	// Translate returns translations in the same order as the texts, an empty string means no translation was found.
+	Translate(ctx context.Context, texts []string) ([]string, error)
+}
@@ -0,0 +1,60 @@
+package wordfrequency
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// List ranks words by a frequency list file, one word per line from the most frequent one.
+// Anything after the word on a line, e.g. a count, is ignored, so common `word count` lists fit as they are.
+// Lines starting with `#` are ignored.
+type List struct {
+	ranks map[string]int
+}
+
+func NewList(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file `%s` error: %w", path, err)
+	}
+
+	defer func() {
+		if err = file.Close(); err != nil {
+			log.Printf("file closing error: %s", err.Error())
+		}
+	}()
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		word := strings.ToLower(fields[0])
+		if _, exists := ranks[word]; !exists {
+			ranks[word] = len(ranks) + 1
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning file `%s` error: %w", path, err)
+	}
+
+	return &List{ranks: ranks}, nil
+}
+
+func (l List) Ranks(_ context.Context, words []string) ([]int, error) {
+	ranks := make([]int, len(words))
+	for i := range words {
+		ranks[i] = l.ranks[strings.ToLower(strings.TrimSpace(words[i]))]
+	}
+
+	return ranks, nil
+}
@@ -0,0 +1,9 @@
+package wordfrequency
+
+import "context"
+
+type Interface interface {
+	// Ranks returns positions of the words in a frequency list, the most frequent word is the first,
+	// zero means the word isn't ranked.
+	Ranks(ctx context.Context, words []string) ([]int, error)
+}
@@ -0,0 +1,47 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/application/repository"
+)
+
+// TriageWords sorts the words the user has gone through into known and ignored ones, the rest stay unknown.
+type TriageWords struct {
+	wordsRepo repository.Interface
+}
+
+func NewTriageWords(wordsRepo repository.Interface) *TriageWords {
+	return &TriageWords{wordsRepo: wordsRepo}
+}
+
+// Run saves the known and the ignored words, they're trimmed and lowercased, blank ones are dropped.
+func (t TriageWords) Run(ctx context.Context, knownWords, ignoredWords []string) error {
+	if err := t.wordsRepo.SaveAsKnown(ctx, normalizeWords(knownWords)); err != nil {
+		return fmt.Errorf("saving known words error: %w", err)
+	}
+
+	if err := t.wordsRepo.SaveAsIgnored(ctx, normalizeWords(ignoredWords)); err != nil {
+		return fmt.Errorf("saving ignored words error: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeWords trims and lowercases the words, blank ones and duplicates are dropped.
+func normalizeWords(words []string) []string {
+	normalized := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			normalized = append(normalized, word)
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,44 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/r-erema/vocaboost/internal/application"
+)
+
+func TestTriageWords(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeWordsRepo(nil, nil)
+
+	err := application.NewTriageWords(repo).Run(
+		context.Background(),
+		[]string{" Cat ", "dog", "", "cat"},
+		[]string{"Bob", "  "},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if want := map[string]bool{"cat": true, "dog": true}; !reflect.DeepEqual(repo.known, want) {
+		t.Errorf("got known words %v, want %v", repo.known, want)
+	}
+
+	if want := map[string]bool{"bob": true}; !reflect.DeepEqual(repo.ignored, want) {
+		t.Errorf("got ignored words %v, want %v", repo.ignored, want)
+	}
+}
+
+func TestTriageWordsFails(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeWordsRepo(nil, nil)
+	repo.err = errTest
+
+	if err := application.NewTriageWords(repo).Run(context.Background(), []string{"cat"}, nil); !errors.Is(err, errTest) {
+		t.Errorf("got error %v, want %v", err, errTest)
+	}
+}
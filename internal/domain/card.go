@@ -0,0 +1,118 @@
+package domain
+
+import "time"
+
+// Grade is how well a card was recalled on a review.
+type Grade int
+
+const (
+	GradeAgain Grade = iota + 1
+	GradeHard
+	GradeGood
+	GradeEasy
+)
+
+// Card is a word under review.
+type Card struct {
+	word     *Word
+	schedule *Schedule
+}
+
+// Schedule is the review state of a card. Ease factor is kept by SM-2, stability and difficulty by FSRS,
+// a card which has never been reviewed has zero repetitions, lapses and scheduler parameters.
+type Schedule struct {
+	due,
+	lastReview time.Time
+	intervalDays,
+	repetitions,
+	lapses int
+	easeFactor,
+	stability,
+	difficulty float64
+}
+
+func NewCard(word *Word, schedule *Schedule) *Card {
+	return &Card{word: word, schedule: schedule}
+}
+
+func (c *Card) Word() *Word {
+	return c.word
+}
+
+func (c *Card) Schedule() *Schedule {
+	return c.schedule
+}
+
+// WithWord returns a copy of the card with the word replaced, the schedule is kept.
+func (c *Card) WithWord(word *Word) *Card {
+	card := *c
+	card.word = word
+
+	return &card
+}
+
+// WithSchedule returns a copy of the card with the schedule replaced.
+func (c *Card) WithSchedule(schedule *Schedule) *Card {
+	card := *c
+	card.schedule = schedule
+
+	return &card
+}
+
+func NewSchedule(
+	due, lastReview time.Time,
+	intervalDays, repetitions, lapses int,
+	easeFactor, stability, difficulty float64,
+) *Schedule {
+	return &Schedule{
+		due:          due,
+		lastReview:   lastReview,
+		intervalDays: intervalDays,
+		repetitions:  repetitions,
+		lapses:       lapses,
+		easeFactor:   easeFactor,
+		stability:    stability,
+		difficulty:   difficulty,
+	}
+}
+
+// NewUnreviewedSchedule is the schedule of a new card, the card is due at once.
+func NewUnreviewedSchedule(due time.Time) *Schedule {
+	return NewSchedule(due, time.Time{}, 0, 0, 0, 0, 0, 0)
+}
+
+func (s *Schedule) Due() time.Time {
+	return s.due
+}
+
+// LastReview is zero if the card has never been reviewed.
+func (s *Schedule) LastReview() time.Time {
+	return s.lastReview
+}
+
+// IntervalDays is the number of days between the last review and the due date, zero while the card is learned.
+func (s *Schedule) IntervalDays() int {
+	return s.intervalDays
+}
+
+// Repetitions is the number of successful reviews in a row.
+func (s *Schedule) Repetitions() int {
+	return s.repetitions
+}
+
+// Lapses is the number of times the card was forgotten.
+func (s *Schedule) Lapses() int {
+	return s.lapses
+}
+
+func (s *Schedule) EaseFactor() float64 {
+	return s.easeFactor
+}
+
+func (s *Schedule) Stability() float64 {
+	return s.stability
+}
+
+func (s *Schedule) Difficulty() float64 {
+	return s.difficulty
+}
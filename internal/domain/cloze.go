@@ -0,0 +1,51 @@
+package domain
+
+import "strings"
+
+// clozeBlank replaces the answers in blanked texts.
+const clozeBlank = "_____"
+
+// Cloze is a sentence with the word blanked out. The sentence is split around the answers,
+// which are the forms of the word as they occur in the sentence, so there is one more segment than answers.
+type Cloze struct {
+	segments,
+	answers []string
+}
+
+func NewCloze(segments, answers []string) *Cloze {
+	return &Cloze{segments: segments, answers: answers}
+}
+
+// Segments are the parts of the sentence around the answers.
+func (c *Cloze) Segments() []string {
+	return c.segments
+}
+
+func (c *Cloze) Answers() []string {
+	return c.answers
+}
+
+// Text is the whole sentence.
+func (c *Cloze) Text() string {
+	return c.Format(func(answer string) string { return answer })
+}
+
+// Blanked is the sentence with the answers replaced by blanks.
+func (c *Cloze) Blanked() string {
+	return c.Format(func(string) string { return clozeBlank })
+}
+
+// Format builds the sentence with every answer rendered by the function, e.g. as an Anki cloze deletion.
+func (c *Cloze) Format(answer func(answer string) string) string {
+	var text strings.Builder
+
+	for i, segment := range c.segments {
+		text.WriteString(segment)
+
+		if i < len(c.answers) {
+			text.WriteString(answer(c.answers[i]))
+		}
+	}
+
+	return text.String()
+}
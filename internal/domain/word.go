@@ -1,28 +1,239 @@
 package domain
 
 type Word struct {
-	word string
-	definitions,
-	examples,
-	imageURLs []string
+	word,
+	translation,
+	sourceSentence string
+	definitions   []*Definition
+	examples      []string
+	images        []*Image
+	pronunciation *Pronunciation
+	frequencyRank int
+	tags          []string
+	clozes        []*Cloze
 }
 
-func (w *Word) Definitions() []string {
+type Image struct {
+	url,
+	filePath string
+}
+
+type Definition struct {
+	partOfSpeech,
+	gloss,
+	translation string
+	synonyms,
+	antonyms []string
+}
+
+type Pronunciation struct {
+	ipa       string
+	syllables []string
+	audioURL,
+	audioFilePath string
+}
+
+// PartOfSpeechDefinitions is a group of definitions sharing the same part of speech.
+type PartOfSpeechDefinitions struct {
+	partOfSpeech string
+	definitions  []*Definition
+}
+
+func (w *Word) Definitions() []*Definition {
 	return w.definitions
 }
 
+// DefinitionsByPartOfSpeech groups definitions by part of speech keeping the order of the first appearance.
+func (w *Word) DefinitionsByPartOfSpeech() []*PartOfSpeechDefinitions {
+	groups := make([]*PartOfSpeechDefinitions, 0)
+	groupsIndexes := make(map[string]int)
+
+	for _, definition := range w.definitions {
+		i, exists := groupsIndexes[definition.partOfSpeech]
+		if !exists {
+			i = len(groups)
+			groupsIndexes[definition.partOfSpeech] = i
+			groups = append(groups, &PartOfSpeechDefinitions{partOfSpeech: definition.partOfSpeech, definitions: nil})
+		}
+
+		groups[i].definitions = append(groups[i].definitions, definition)
+	}
+
+	return groups
+}
+
 func (w *Word) Examples() []string {
 	return w.examples
 }
 
+func (w *Word) Images() []*Image {
+	return w.images
+}
+
 func (w *Word) ImageURLs() []string {
-	return w.imageURLs
+	urls := make([]string, len(w.images))
+	for i := range w.images {
+		urls[i] = w.images[i].url
+	}
+
+	return urls
+}
+
+func (w *Word) Pronunciation() *Pronunciation {
+	return w.pronunciation
 }
 
-func NewWord(word string, definitions, examples, imageURLs []string) *Word {
-	return &Word{word: word, definitions: definitions, examples: examples, imageURLs: imageURLs}
+func NewWord(
+	word, translation, sourceSentence string,
+	definitions []*Definition,
+	examples []string,
+	images []*Image,
+	pronunciation *Pronunciation,
+	frequencyRank int,
+	tags []string,
+) *Word {
+	return &Word{
+		word:           word,
+		translation:    translation,
+		sourceSentence: sourceSentence,
+		definitions:    definitions,
+		examples:       examples,
+		images:         images,
+		pronunciation:  pronunciation,
+		frequencyRank:  frequencyRank,
+		tags:           tags,
+		clozes:         nil,
+	}
+}
+
+// WithImages returns a copy of the word with the images replaced.
+func (w *Word) WithImages(images []*Image) *Word {
+	word := *w
+	word.images = images
+
+	return &word
+}
+
+// WithClozes returns a copy of the word with the clozes replaced.
+func (w *Word) WithClozes(clozes []*Cloze) *Word {
+	word := *w
+	word.clozes = clozes
+
+	return &word
+}
+
+// Clozes are the examples and the source sentence with the word blanked out.
+func (w *Word) Clozes() []*Cloze {
+	return w.clozes
 }
 
 func (w *Word) Word() string {
 	return w.word
 }
+
+// Translation is the word in the learner's native language, empty if it isn't translated.
+func (w *Word) Translation() string {
+	return w.translation
+}
+
+// SourceSentence is the sentence of the source text the word was met in, empty if it's unknown.
+func (w *Word) SourceSentence() string {
+	return w.sourceSentence
+}
+
+// PartsOfSpeech lists parts of speech of the definitions without duplicates keeping the order.
+func (w *Word) PartsOfSpeech() []string {
+	partsOfSpeech := make([]string, 0)
+
+	for _, group := range w.DefinitionsByPartOfSpeech() {
+		if group.partOfSpeech != "" {
+			partsOfSpeech = append(partsOfSpeech, group.partOfSpeech)
+		}
+	}
+
+	return partsOfSpeech
+}
+
+// FrequencyRank is the position of the word in a frequency list, the most frequent word is the first.
+// Zero means the rank is unknown.
+func (w *Word) FrequencyRank() int {
+	return w.frequencyRank
+}
+
+func (w *Word) Tags() []string {
+	return w.tags
+}
+
+func NewDefinition(partOfSpeech, gloss, translation string, synonyms, antonyms []string) *Definition {
+	return &Definition{
+		partOfSpeech: partOfSpeech,
+		gloss:        gloss,
+		translation:  translation,
+		synonyms:     synonyms,
+		antonyms:     antonyms,
+	}
+}
+
+func (d *Definition) PartOfSpeech() string {
+	return d.partOfSpeech
+}
+
+func (d *Definition) Gloss() string {
+	return d.gloss
+}
+
+// Translation is the gloss in the learner's native language, empty if it isn't translated.
+func (d *Definition) Translation() string {
+	return d.translation
+}
+
+func (d *Definition) Synonyms() []string {
+	return d.synonyms
+}
+
+func (d *Definition) Antonyms() []string {
+	return d.antonyms
+}
+
+// NewImage creates an image, the file path is empty if the image isn't stored locally.
+func NewImage(url, filePath string) *Image {
+	return &Image{url: url, filePath: filePath}
+}
+
+func (i *Image) URL() string {
+	return i.url
+}
+
+func (i *Image) FilePath() string {
+	return i.filePath
+}
+
+func NewPronunciation(ipa string, syllables []string, audioURL, audioFilePath string) *Pronunciation {
+	return &Pronunciation{ipa: ipa, syllables: syllables, audioURL: audioURL, audioFilePath: audioFilePath}
+}
+
+func (p *Pronunciation) IPA() string {
+	return p.ipa
+}
+
+func (p *Pronunciation) Syllables() []string {
+	return p.syllables
+}
+
+// AudioURL is a URL of the pronunciation audio, empty if there is no audio.
+func (p *Pronunciation) AudioURL() string {
+	return p.audioURL
+}
+
+// AudioFilePath is a path of the local audio file, empty if the audio isn't stored locally.
+func (p *Pronunciation) AudioFilePath() string {
+	return p.audioFilePath
+}
+
+func (g *PartOfSpeechDefinitions) PartOfSpeech() string {
+	return g.partOfSpeech
+}
+
+func (g *PartOfSpeechDefinitions) Definitions() []*Definition {
+	return g.definitions
+}
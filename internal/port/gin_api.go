@@ -0,0 +1,409 @@
+package port
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/r-erema/vocaboost/internal/application"
+	"github.com/r-erema/vocaboost/internal/application/service/images"
+)
+
+const (
+	APIv1HTTPPath             = "/api/v1"
+	APIv1CountsHTTPPath       = APIv1HTTPPath + "/counts"
+	APIv1ExtractWordsHTTPPath = APIv1HTTPPath + "/words/extract"
+	APIv1TriageWordsHTTPPath  = APIv1HTTPPath + "/words/triage"
+	APIv1LookUpWordsHTTPPath  = APIv1HTTPPath + "/words/lookup"
+	APIv1UploadWordsHTTPPath  = APIv1HTTPPath + "/words/upload"
+	APIv1SpecHTTPPath         = APIv1HTTPPath + "/openapi.yaml"
+
+	apiErrBadRequest = "the request body is malformed"
+	apiErrNoText     = "the text is empty"
+	apiErrNoWords    = "no words are given"
+	apiErrBadMedia   = "audio and image URLs must be http or https URLs"
+)
+
+// apiError is the body of every failed API response.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type apiCountsResponse struct {
+	KnownWords   int `json:"known_words"`
+	IgnoredWords int `json:"ignored_words"`
+	DueCards     int `json:"due_cards"`
+}
+
+type apiExtractWordsRequest struct {
+	Text string `json:"text"`
+}
+
+type apiExtractWordsResponse struct {
+	Words []string `json:"words"`
+}
+
+// apiTriageWordsRequest lists the words to save as known and as ignored, the words not listed stay unknown.
+type apiTriageWordsRequest struct {
+	KnownWords   []string `json:"known_words"`
+	IgnoredWords []string `json:"ignored_words"`
+}
+
+type apiLookUpWordsRequest struct {
+	Words []string `json:"words"`
+	// SourceText is the text the words were met in, the sentences of the words are found in it.
+	SourceText string `json:"source_text"`
+}
+
+type apiLookUpWordsResponse struct {
+	Words []apiWordCandidates `json:"words"`
+	// ImagesShortages are the words whose images searches found fewer images than MaxImages.
+	ImagesShortages []apiImagesShortage `json:"images_shortages"`
+	// MaxImages is how many images a card gets at most.
+	MaxImages int `json:"max_images"`
+}
+
+// apiWordCandidates is the candidate card material of a word, the candidates which the HTML preview page
+// would check by default are selected.
+type apiWordCandidates struct {
+	Word           string                   `json:"word"`
+	IPA            string                   `json:"ipa"`
+	Syllables      []string                 `json:"syllables"`
+	AudioURL       string                   `json:"audio_url"`
+	SourceSentence string                   `json:"source_sentence"`
+	Definitions    []apiDefinitionCandidate `json:"definitions"`
+	Examples       []apiCandidate           `json:"examples"`
+	Images         []apiCandidate           `json:"images"`
+}
+
+type apiDefinitionCandidate struct {
+	apiDefinition
+	Selected bool `json:"selected"`
+}
+
+type apiCandidate struct {
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
+
+type apiDefinition struct {
+	PartOfSpeech string   `json:"part_of_speech"`
+	Gloss        string   `json:"gloss"`
+	Synonyms     []string `json:"synonyms"`
+	Antonyms     []string `json:"antonyms"`
+}
+
+type apiUploadWordsRequest struct {
+	Words []apiCard `json:"words"`
+	// Tags are added to every card.
+	Tags []string `json:"tags"`
+}
+
+// apiCard is the picked card material of a word, all of it goes to the card.
+type apiCard struct {
+	Word           string          `json:"word"`
+	IPA            string          `json:"ipa"`
+	Syllables      []string        `json:"syllables"`
+	AudioURL       string          `json:"audio_url"`
+	SourceSentence string          `json:"source_sentence"`
+	Definitions    []apiDefinition `json:"definitions"`
+	Examples       []string        `json:"examples"`
+	Images         []string        `json:"images"`
+}
+
+type apiUploadWordsResponse struct {
+	Reports []apiUploadReport `json:"reports"`
+}
+
+type apiUploadReport struct {
+	Target      string            `json:"target"`
+	DownloadURL string            `json:"download_url"`
+	Words       []apiUploadResult `json:"words"`
+	Failed      int               `json:"failed"`
+}
+
+type apiUploadResult struct {
+	Word   string `json:"word"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+type apiImagesShortage struct {
+	Word  string `json:"word"`
+	Found int    `json:"found"`
+}
+
+func (hh *HTTPHandler) APICounts(context *gin.Context) {
+	counts, err := hh.counts.Run(context.Request.Context(), time.Now())
+	if err != nil {
+		log.Printf("getting counts error: %s", err)
+		respondAPIError(context, err)
+
+		return
+	}
+
+	context.JSON(http.StatusOK, apiCountsResponse{
+		KnownWords:   counts.KnownWords,
+		IgnoredWords: counts.IgnoredWords,
+		DueCards:     counts.DueCards,
+	})
+}
+
+// APIExtractWords responds with the words of the text which are neither known nor ignored.
+func (hh *HTTPHandler) APIExtractWords(context *gin.Context) {
+	request := new(apiExtractWordsRequest)
+	if err := context.ShouldBindJSON(request); err != nil {
+		log.Printf("request binding error: %s", err)
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrBadRequest})
+
+		return
+	}
+
+	if strings.TrimSpace(request.Text) == "" {
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrNoText})
+
+		return
+	}
+
+	words, err := hh.extractUnknownWords.Run(context.Request.Context(), request.Text)
+	if err != nil {
+		log.Printf("extracting unknown words error: %s", err)
+		respondAPIError(context, err)
+
+		return
+	}
+
+	context.JSON(http.StatusOK, apiExtractWordsResponse{Words: words})
+}
+
+func (hh *HTTPHandler) APITriageWords(context *gin.Context) {
+	request := new(apiTriageWordsRequest)
+	if err := context.ShouldBindJSON(request); err != nil {
+		log.Printf("request binding error: %s", err)
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrBadRequest})
+
+		return
+	}
+
+	if err := hh.triageWords.Run(context.Request.Context(), request.KnownWords, request.IgnoredWords); err != nil {
+		log.Printf("triaging words error: %s", err)
+		respondAPIError(context, err)
+
+		return
+	}
+
+	context.Status(http.StatusNoContent)
+}
+
+// APILookUpWords responds with the card material candidates of the words.
+func (hh *HTTPHandler) APILookUpWords(context *gin.Context) {
+	request := new(apiLookUpWordsRequest)
+	if err := context.ShouldBindJSON(request); err != nil {
+		log.Printf("request binding error: %s", err)
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrBadRequest})
+
+		return
+	}
+
+	if len(request.Words) == 0 {
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrNoWords})
+
+		return
+	}
+
+	candidates, err := hh.lookUpWords.Run(context.Request.Context(), request.Words, request.SourceText)
+	if err != nil {
+		log.Printf("looking words up error: %s", err)
+		respondAPIError(context, err)
+
+		return
+	}
+
+	shortages := hh.lookUpWords.ImagesShortages(candidates)
+	response := apiLookUpWordsResponse{
+		Words:           prepareAPIWordsCandidates(candidates),
+		ImagesShortages: make([]apiImagesShortage, len(shortages)),
+		MaxImages:       hh.lookUpWords.MaxImages(),
+	}
+
+	for i, shortage := range shortages {
+		response.ImagesShortages[i] = apiImagesShortage(*shortage)
+	}
+
+	context.JSON(http.StatusOK, response)
+}
+
+// APIUploadWords builds cards of the words and uploads them to the spaced repetition service.
+func (hh *HTTPHandler) APIUploadWords(context *gin.Context) {
+	request := new(apiUploadWordsRequest)
+	if err := context.ShouldBindJSON(request); err != nil {
+		log.Printf("request binding error: %s", err)
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrBadRequest})
+
+		return
+	}
+
+	if !apiMediaURLsValid(request.Words) {
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrBadMedia})
+
+		return
+	}
+
+	materials := parseAPICards(request.Words, request.Tags)
+	if len(materials) == 0 {
+		context.JSON(http.StatusBadRequest, apiError{Error: apiErrNoWords})
+
+		return
+	}
+
+	report, err := hh.buildAndPublishCards(context.Request.Context(), materials)
+	if err != nil {
+		log.Printf("publishing cards error: %s", err)
+		respondAPIError(context, err)
+
+		return
+	}
+
+	response := apiUploadWordsResponse{Reports: make([]apiUploadReport, 0, len(report.Uploads))}
+
+	for _, uploadReport := range prepareUploadReports(report.Uploads) {
+		results := make([]apiUploadResult, len(uploadReport.Words))
+		for i := range uploadReport.Words {
+			results[i] = apiUploadResult(uploadReport.Words[i])
+		}
+
+		response.Reports = append(response.Reports, apiUploadReport{
+			Target:      uploadReport.Target,
+			DownloadURL: uploadReport.DownloadURL,
+			Words:       results,
+			Failed:      uploadReport.Failed,
+		})
+	}
+
+	context.JSON(http.StatusOK, response)
+}
+
+func respondAPIError(context *gin.Context, err error) {
+	if errors.Is(err, images.ErrQuotaExceeded) {
+		context.JSON(http.StatusTooManyRequests, apiError{Error: userErrImagesQuotaExceeded})
+
+		return
+	}
+
+	context.JSON(http.StatusInternalServerError, apiError{Error: userErrSomethingWentWrong})
+}
+
+func prepareAPIWordsCandidates(candidates []*application.WordCandidates) []apiWordCandidates {
+	words := make([]apiWordCandidates, len(candidates))
+
+	for i, candidate := range candidates {
+		words[i] = apiWordCandidates{
+			Word:           candidate.Word,
+			IPA:            candidate.IPA,
+			Syllables:      candidate.Syllables,
+			AudioURL:       candidate.AudioURL,
+			SourceSentence: candidate.SourceSentence,
+			Definitions:    make([]apiDefinitionCandidate, len(candidate.Definitions)),
+			Examples:       prepareAPICandidates(candidate.Examples),
+			Images:         prepareAPICandidates(candidate.Images),
+		}
+
+		for j, definition := range candidate.Definitions {
+			words[i].Definitions[j] = apiDefinitionCandidate{
+				apiDefinition: apiDefinition{
+					PartOfSpeech: definition.PartOfSpeech,
+					Gloss:        definition.Gloss,
+					Synonyms:     apiList(definition.Synonyms),
+					Antonyms:     apiList(definition.Antonyms),
+				},
+				Selected: definition.Selected,
+			}
+		}
+	}
+
+	return words
+}
+
+func prepareAPICandidates(candidates []*application.Candidate) []apiCandidate {
+	prepared := make([]apiCandidate, len(candidates))
+	for i, candidate := range candidates {
+		prepared[i] = apiCandidate{Value: candidate.Value, Selected: candidate.Selected}
+	}
+
+	return prepared
+}
+
+// parseAPICards turns the cards into the card material, cards without a word are dropped.
+func parseAPICards(cards []apiCard, tags []string) []*application.CardMaterial {
+	materials := make([]*application.CardMaterial, 0, len(cards))
+
+	for _, card := range cards {
+		word := strings.TrimSpace(card.Word)
+		if word == "" {
+			continue
+		}
+
+		material := &application.CardMaterial{
+			Word:           word,
+			IPA:            strings.TrimSpace(card.IPA),
+			Syllables:      apiList(card.Syllables),
+			AudioURL:       strings.TrimSpace(card.AudioURL),
+			SourceSentence: strings.TrimSpace(card.SourceSentence),
+			Tags:           apiList(tags),
+			Definitions:    make([]*application.Definition, 0, len(card.Definitions)),
+			Examples:       apiList(card.Examples),
+			Images:         apiList(card.Images),
+		}
+
+		for _, definition := range card.Definitions {
+			if strings.TrimSpace(definition.Gloss) == "" {
+				continue
+			}
+
+			material.Definitions = append(material.Definitions, &application.Definition{
+				PartOfSpeech: strings.TrimSpace(definition.PartOfSpeech),
+				Gloss:        strings.TrimSpace(definition.Gloss),
+				Synonyms:     apiList(definition.Synonyms),
+				Antonyms:     apiList(definition.Antonyms),
+			})
+		}
+
+		materials = append(materials, material)
+	}
+
+	return materials
+}
+
+// apiMediaURLsValid checks the audio and the images of the cards are absolute http(s) URLs. Whether they were
+// offered by a look up is checked when the cards are built.
+func apiMediaURLsValid(cards []apiCard) bool {
+	for _, card := range cards {
+		mediaURLs := apiList(append([]string{card.AudioURL}, card.Images...))
+
+		for _, mediaURL := range mediaURLs {
+			parsedURL, err := url.Parse(mediaURL)
+			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// apiList trims the items, blank ones are dropped, the result is never nil, so it's encoded as an array.
+func apiList(items []string) []string {
+	list := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -1,136 +1,171 @@
 package port
 
 import (
+	stdcontext "context"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
-	"strings"
+	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/r-erema/vocaboost/internal/application/repository"
-	"github.com/r-erema/vocaboost/internal/application/service/dictionary"
+	"github.com/r-erema/vocaboost/internal/application"
 	"github.com/r-erema/vocaboost/internal/application/service/images"
 	"github.com/r-erema/vocaboost/internal/application/service/spacedrepetition"
-	"github.com/r-erema/vocaboost/internal/application/service/textparser"
-	"github.com/r-erema/vocaboost/internal/domain"
-	"github.com/thoas/go-funk"
 )
 
 const (
 	userErrSomethingWentWrong  = "something went wrong"
+	userErrImagesQuotaExceeded = "the daily images search quota is exceeded, try again tomorrow"
 	saveTargetRepoKnownWords   = "known_words"
 	saveTargetRepoIgnoredWords = "ignored_words"
+	// sourceTextField carries the source text through the pages, the dot keeps it apart from words in form keys.
+	sourceTextField = "source.text"
 
 	IndexHTTPPath                  = "/"
+	AudioHTTPPath                  = "/audio"
+	ImagesHTTPPath                 = "/images"
 	SaveWordsHTTPPath              = "/save-words"
+	PreviewWordsHTTPPath           = "/preview-words"
 	UploadSpacedRepetitionHTTPPath = "/upload-spaced-repetition"
-
-	maxDefinitions = 4
-	maxImages      = 8
-	maxExamples    = 4
+	ExportsHTTPPath                = "/exports"
+	ReviewHTTPPath                 = "/review"
 )
 
-var (
-	errBadImageAndDefinitionWord = errors.New("images word and definitions word aren't equal")
-	errBadImagesCount            = errors.New("bad images count")
-)
+// uploadResult is the outcome of a word upload, the error is empty unless the upload failed.
+type uploadResult struct {
+	Word   string
+	Status string
+	Error  string
+}
+
+// uploadReport is the outcome of an upload to a target, the download URL is empty unless the target is a file.
+type uploadReport struct {
+	Target      string
+	DownloadURL string
+	Words       []uploadResult
+	Failed      int
+}
 
 type HTTPHandler struct {
-	textParser              textparser.Interface
-	wordsRepo               repository.Interface
-	spacedRepetitionService spacedrepetition.Interface
-	dictionary              dictionary.Interface
-	images                  images.Interface
+	counts              *application.Counts
+	extractUnknownWords *application.ExtractUnknownWords
+	triageWords         *application.TriageWords
+	lookUpWords         *application.LookUpWords
+	buildCards          *application.BuildCards
+	publishCards        *application.PublishCards
+	nextReview          *application.NextReview
+	gradeReview         *application.GradeReview
 }
 
 func NewHTTPHandler(
-	textParser textparser.Interface,
-	wordsTracker repository.Interface,
-	spacedRepetitionService spacedrepetition.Interface,
-	dictionaryService dictionary.Interface,
-	imagesService images.Interface,
+	counts *application.Counts,
+	extractUnknownWords *application.ExtractUnknownWords,
+	triageWords *application.TriageWords,
+	lookUpWords *application.LookUpWords,
+	buildCards *application.BuildCards,
+	publishCards *application.PublishCards,
+	nextReview *application.NextReview,
+	gradeReview *application.GradeReview,
 ) *HTTPHandler {
 	return &HTTPHandler{
-		textParser:              textParser,
-		wordsRepo:               wordsTracker,
-		spacedRepetitionService: spacedRepetitionService,
-		dictionary:              dictionaryService,
-		images:                  imagesService,
+		counts:              counts,
+		extractUnknownWords: extractUnknownWords,
+		triageWords:         triageWords,
+		lookUpWords:         lookUpWords,
+		buildCards:          buildCards,
+		publishCards:        publishCards,
+		nextReview:          nextReview,
+		gradeReview:         gradeReview,
 	}
 }
 
 func (hh *HTTPHandler) Index(context *gin.Context) {
-	knownWordsCount, err := hh.wordsRepo.KnownWordsCount(context.Request.Context())
+	counts, err := hh.counts.Run(context.Request.Context(), time.Now())
 	if err != nil {
-		log.Printf("getting known words count error: %s", err)
+		log.Printf("getting counts error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	ignoredWordsCount, err := hh.wordsRepo.IgnoredWordsCount(context.Request.Context())
+	context.HTML(http.StatusOK, "index.html", gin.H{
+		"known_words_count":   counts.KnownWords,
+		"ignored_words_count": counts.IgnoredWords,
+		"due_cards_count":     counts.DueCards,
+		"review_http_path":    ReviewHTTPPath,
+	})
+}
+
+// Review shows the longest overdue card.
+func (hh *HTTPHandler) Review(context *gin.Context) {
+	review, err := hh.nextReview.Run(context.Request.Context(), time.Now())
 	if err != nil {
-		log.Printf("getting ignored words count error: %s", err)
+		log.Printf("getting next review error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	context.HTML(http.StatusOK, "index.html", gin.H{
-		"known_words_count":   knownWordsCount,
-		"ignored_words_count": ignoredWordsCount,
+	var card *reviewCard
+	if review.Card != nil {
+		card = prepareReviewCard(review.Card, review.Intervals)
+	}
+
+	context.HTML(http.StatusOK, "review.html", gin.H{
+		"index_http_path":  IndexHTTPPath,
+		"review_http_path": ReviewHTTPPath,
+		"due_cards_count":  review.DueCards,
+		"card":             card,
+		"promoted_word":    context.Query(reviewQueryPromoted),
 	})
 }
 
-func (hh *HTTPHandler) SplitTextToWords(context *gin.Context) {
-	form := new(struct {
-		Text string `form:"text"`
-	})
+// GradeReview schedules the reviewed card, the next card is shown then, along with the word if it became known.
+func (hh *HTTPHandler) GradeReview(context *gin.Context) {
+	word := context.PostForm(reviewFieldWord)
 
-	if err := context.ShouldBind(form); err != nil {
-		log.Printf("form binding error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+	grade, err := parseReviewGrade(context.PostForm(reviewFieldGrade))
+	if err != nil {
+		log.Printf("parsing review grade error: %s", err)
+		context.String(http.StatusBadRequest, userErrSomethingWentWrong)
 
 		return
 	}
 
-	words, err := hh.textParser.ExtractWords(form.Text)
+	promoted, err := hh.gradeReview.Run(context.Request.Context(), word, grade, time.Now())
 	if err != nil {
-		log.Printf("extrating words error: %s", err)
+		log.Printf("grading review error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	words, ok := funk.Map(words, strings.ToLower).([]string)
-	if !ok {
-		log.Printf("unable to assert to string slice")
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+	if !promoted {
+		context.Redirect(http.StatusSeeOther, ReviewHTTPPath)
 
 		return
 	}
 
-	words = funk.FilterString(words, func(w string) bool {
-		if _, err = strconv.Atoi(w); err == nil {
-			return false
-		}
+	context.Redirect(http.StatusSeeOther, ReviewHTTPPath+"?"+url.Values{reviewQueryPromoted: {word}}.Encode())
+}
 
-		return len(w) > 1
+func (hh *HTTPHandler) SplitTextToWords(context *gin.Context) {
+	form := new(struct {
+		Text string `form:"text"`
 	})
 
-	words = funk.UniqString(words)
-
-	if words, err = hh.wordsRepo.FilterKnownWords(context.Request.Context(), words); err != nil {
-		log.Printf("filtering known words error: %s", err)
+	if err := context.ShouldBind(form); err != nil {
+		log.Printf("form binding error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	if words, err = hh.wordsRepo.FilterIgnoredWords(context.Request.Context(), words); err != nil {
-		log.Printf("filtering ignored words error: %s", err)
+	words, err := hh.extractUnknownWords.Run(context.Request.Context(), form.Text)
+	if err != nil {
+		log.Printf("extracting unknown words error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
@@ -139,8 +174,10 @@ func (hh *HTTPHandler) SplitTextToWords(context *gin.Context) {
 	context.HTML(http.StatusOK, "words_list.html", gin.H{
 		"save_words_http_path": SaveWordsHTTPPath,
 		"index_http_path":      IndexHTTPPath,
+		"source_text_field":    sourceTextField,
 
-		"words": words,
+		"words":       words,
+		"source_text": form.Text,
 
 		"known_words_value":   saveTargetRepoKnownWords,
 		"ignored_words_value": saveTargetRepoIgnoredWords,
@@ -159,6 +196,10 @@ func (hh *HTTPHandler) SaveWords(context *gin.Context) {
 	var knownWordsToSave, ignoredWordsToSave, unknownWords []string
 
 	for word, saveTargetValues := range context.Request.PostForm {
+		if word == sourceTextField {
+			continue
+		}
+
 		switch saveTargetValues[0] {
 		case saveTargetRepoKnownWords:
 			knownWordsToSave = append(knownWordsToSave, word)
@@ -169,31 +210,27 @@ func (hh *HTTPHandler) SaveWords(context *gin.Context) {
 		}
 	}
 
-	if err = hh.wordsRepo.SaveAsKnown(context, knownWordsToSave); err != nil {
-		log.Printf("saving known words error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
-
-		return
-	}
-
-	if err = hh.wordsRepo.SaveAsIgnored(context, ignoredWordsToSave); err != nil {
-		log.Printf("saving ignored words error: %s", err)
+	if err = hh.triageWords.Run(context.Request.Context(), knownWordsToSave, ignoredWordsToSave); err != nil {
+		log.Printf("triaging words error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
 	context.HTML(http.StatusOK, "unknown_words_list.html", gin.H{
-		"index_http_path":          IndexHTTPPath,
-		"upload_spaced_repetition": UploadSpacedRepetitionHTTPPath,
+		"index_http_path":    IndexHTTPPath,
+		"preview_words_path": PreviewWordsHTTPPath,
+		"source_text_field":  sourceTextField,
 
 		"unknown_words": unknownWords,
+		"source_text":   context.Request.PostForm.Get(sourceTextField),
 	})
 }
 
-func (hh *HTTPHandler) UploadToSpacedRepetitionService(context *gin.Context) {
+func (hh *HTTPHandler) PreviewWords(context *gin.Context) {
 	form := struct {
 		UnknownWordsText string `form:"unknown_words"`
+		SourceText       string `form:"source.text"`
 	}{}
 
 	if err := context.Bind(&form); err != nil {
@@ -203,111 +240,122 @@ func (hh *HTTPHandler) UploadToSpacedRepetitionService(context *gin.Context) {
 		return
 	}
 
-	unknownWords, err := hh.textParser.ExtractWords(form.UnknownWordsText)
+	candidates, err := hh.lookUpWords.RunText(context.Request.Context(), form.UnknownWordsText, form.SourceText)
 	if err != nil {
-		log.Printf("extracting words error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+		log.Printf("looking words up error: %s", err)
+		respondImagesSearchError(context, err)
 
 		return
 	}
 
-	wordsDefinitions, err := hh.dictionary.WordsInfo(context.Request.Context(), unknownWords)
-	if err != nil {
-		log.Printf("getting words info error: %s", err)
+	previewWords := preparePreviewWords(candidates, hh.lookUpWords.ImagesShortages(candidates))
+
+	context.HTML(http.StatusOK, "preview.html", gin.H{
+		"index_http_path":          IndexHTTPPath,
+		"upload_spaced_repetition": UploadSpacedRepetitionHTTPPath,
+
+		"words":      previewWords,
+		"max_images": hh.lookUpWords.MaxImages(),
+	})
+}
+
+func (hh *HTTPHandler) UploadToSpacedRepetitionService(context *gin.Context) {
+	if err := context.Request.ParseForm(); err != nil {
+		log.Printf("parse form error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	wordsImages, err := hh.images.Search(context.Request.Context(), unknownWords)
-	if err != nil {
-		log.Printf("getting words images error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+	materials := parsePreviewForm(context.Request.PostForm)
+	if len(materials) == 0 {
+		log.Print("preview form has no words")
+		context.String(http.StatusBadRequest, userErrSomethingWentWrong)
 
 		return
 	}
 
-	wordsImagesClarified, err := hh.images.Search(context.Request.Context(), funk.Map(unknownWords, func(word string) string {
-		return word + " meaning"
-	}).([]string))
+	report, err := hh.buildAndPublishCards(context.Request.Context(), materials)
 	if err != nil {
-		log.Printf("getting clarified words images error: %s", err)
+		log.Printf("publishing cards error: %s", err)
 		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 
 		return
 	}
 
-	words, err := prepareWords(unknownWords, wordsDefinitions, wordsImages, wordsImagesClarified)
+	context.HTML(http.StatusOK, "result.html", gin.H{
+		"index_http_path": IndexHTTPPath,
+
+		"upload_reports": prepareUploadReports(report.Uploads),
+
+		"images_shortages": parsePreviewImagesShortages(context.Request.PostForm),
+		"max_images":       hh.lookUpWords.MaxImages(),
+	})
+}
+
+func (hh *HTTPHandler) buildAndPublishCards(
+	ctx stdcontext.Context,
+	materials []*application.CardMaterial,
+) (*application.PublishReport, error) {
+	words, err := hh.buildCards.Run(ctx, materials)
 	if err != nil {
-		log.Printf("preparing words error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+		return nil, fmt.Errorf("building cards error: %w", err)
+	}
 
-		return
+	report, err := hh.publishCards.Run(ctx, words)
+	if err != nil {
+		return nil, fmt.Errorf("publishing cards error: %w", err)
 	}
 
-	if err = hh.spacedRepetitionService.UploadWords(context.Request.Context(), words); err != nil {
-		log.Printf("uploading plainWords to the spaced repetiotion service error: %s", err)
-		context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
+	return report, nil
+}
+
+func respondImagesSearchError(context *gin.Context, err error) {
+	if errors.Is(err, images.ErrQuotaExceeded) {
+		context.String(http.StatusTooManyRequests, userErrImagesQuotaExceeded)
 
 		return
 	}
 
-	context.HTML(http.StatusOK, "result.html", gin.H{
-		"index_http_path": IndexHTTPPath,
-	})
+	context.String(http.StatusInternalServerError, userErrSomethingWentWrong)
 }
 
-func prepareWords(
-	unknownWords []string,
-	wordsDefinitions []*dictionary.WordInfoDTO,
-	wordsImages,
-	wordsImagesClarified []*images.WordImagesDTO,
-) ([]*domain.Word, error) {
-	words := make([]*domain.Word, len(unknownWords))
-
-	for i := 0; i < len(unknownWords); i++ {
-		definition := wordsDefinitions[i]
-		wordImages := wordsImages[i]
-		wordImagesClarified := wordsImagesClarified[i]
-
-		definitionAndImagesCompatible := definition.Word() == wordImages.Word()
-		if !definitionAndImagesCompatible {
-			return nil, fmt.Errorf(
-				"%w, images word: %s, definitions word: %s",
-				errBadImageAndDefinitionWord,
-				wordImages.Word(),
-				definition.Word(),
-			)
-		}
+func prepareUploadReports(reports []*spacedrepetition.UploadReportDTO) []uploadReport {
+	preparedReports := make([]uploadReport, len(reports))
 
-		definitions := definition.Definitions()
-		if len(definitions) > maxDefinitions {
-			definitions = definitions[:maxDefinitions]
+	for i, report := range reports {
+		preparedReports[i] = uploadReport{
+			Target:      report.Target(),
+			DownloadURL: report.DownloadURL(),
+			Words:       prepareUploadResults(report.Words()),
+			Failed:      countFailedUploads(report.Words()),
 		}
+	}
 
-		examples := definition.Examples()
-		if len(examples) > maxExamples {
-			examples = examples[:maxExamples]
-		}
+	return preparedReports
+}
 
-		imageURLs, err := prepareImages(wordImages.Urls(), wordImagesClarified.Urls())
-		if err != nil {
-			return nil, fmt.Errorf("preparing images for word `%s` error: %w", definition.Word(), err)
-		}
+func prepareUploadResults(wordsResults []*spacedrepetition.WordUploadResultDTO) []uploadResult {
+	results := make([]uploadResult, len(wordsResults))
 
-		words[i] = domain.NewWord(definition.Word(), definitions, examples, imageURLs)
+	for i, wordResult := range wordsResults {
+		results[i] = uploadResult{Word: wordResult.Word(), Status: string(wordResult.Status()), Error: ""}
+		if wordResult.Err() != nil {
+			results[i].Error = wordResult.Err().Error()
+		}
 	}
 
-	return words, nil
+	return results
 }
 
-func prepareImages(normalImages, clarifiedImages []string) ([]string, error) {
-	requiredImagesCountPerType := maxImages / 2
-	if len(normalImages) < requiredImagesCountPerType || len(clarifiedImages) < requiredImagesCountPerType {
-		return nil, errBadImagesCount
-	}
+func countFailedUploads(wordsResults []*spacedrepetition.WordUploadResultDTO) int {
+	var failed int
 
-	result := append(normalImages[:requiredImagesCountPerType], clarifiedImages[:requiredImagesCountPerType]...)
+	for _, wordResult := range wordsResults {
+		if wordResult.Status() == spacedrepetition.UploadStatusFailed {
+			failed++
+		}
+	}
 
-	return result, nil
+	return failed
 }
@@ -0,0 +1,208 @@
+package port
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/r-erema/vocaboost/internal/application"
+)
+
+const (
+	previewFieldWord        = "w%d.word"
+	previewFieldIPA         = "w%d.ipa"
+	previewFieldSyllables   = "w%d.syllables"
+	previewFieldAudioURL    = "w%d.audio_url"
+	previewFieldSource      = "w%d.source"
+	previewFieldTags        = "tags"
+	previewFieldDefGloss    = "w%d.def%d.gloss"
+	previewFieldDefPOS      = "w%d.def%d.pos"
+	previewFieldDefSynonyms = "w%d.def%d.synonyms"
+	previewFieldDefAntonyms = "w%d.def%d.antonyms"
+	previewFieldDefSelected = "w%d.def%d.selected"
+	previewFieldExample     = "w%d.ex%d.text"
+	previewFieldExSelected  = "w%d.ex%d.selected"
+	previewFieldImage       = "w%d.img%d.url"
+	previewFieldImgSelected = "w%d.img%d.selected"
+	previewFieldImagesFound = "w%d.images_found"
+
+	previewCheckboxOn   = "on"
+	previewListSep      = ", "
+	previewSyllablesSep = " "
+)
+
+// previewWord is the candidate card material of a word rendered on the preview page, where users pick and edit it.
+type previewWord struct {
+	Index     int
+	Word      string
+	IPA       string
+	Syllables string
+	AudioURL  string
+	// SourceSentence is the sentence of the source text the word was met in.
+	SourceSentence string
+	Definitions    []*previewDefinition
+	Examples       []*previewItem
+	Images         []*previewItem
+	// ImagesShortage is set if the searches found fewer images than a card gets at most.
+	ImagesShortage *application.ImagesShortage
+}
+
+type previewDefinition struct {
+	Selected     bool
+	Gloss        string
+	PartOfSpeech string
+	Synonyms     string
+	Antonyms     string
+}
+
+type previewItem struct {
+	Selected bool
+	Value    string
+}
+
+// preparePreviewWords renders the candidates into the preview page fields.
+func preparePreviewWords(
+	candidates []*application.WordCandidates,
+	imagesShortages []*application.ImagesShortage,
+) []*previewWord {
+	shortages := make(map[string]*application.ImagesShortage, len(imagesShortages))
+	for _, shortage := range imagesShortages {
+		shortages[shortage.Word] = shortage
+	}
+
+	previewWords := make([]*previewWord, len(candidates))
+
+	for i, candidate := range candidates {
+		word := &previewWord{
+			Index:          i,
+			Word:           candidate.Word,
+			IPA:            candidate.IPA,
+			Syllables:      strings.Join(candidate.Syllables, previewSyllablesSep),
+			AudioURL:       candidate.AudioURL,
+			SourceSentence: candidate.SourceSentence,
+			Definitions:    make([]*previewDefinition, len(candidate.Definitions)),
+			Examples:       preparePreviewItems(candidate.Examples),
+			Images:         preparePreviewItems(candidate.Images),
+			ImagesShortage: shortages[candidate.Word],
+		}
+
+		for j, definition := range candidate.Definitions {
+			word.Definitions[j] = &previewDefinition{
+				Selected:     definition.Selected,
+				Gloss:        definition.Gloss,
+				PartOfSpeech: definition.PartOfSpeech,
+				Synonyms:     strings.Join(definition.Synonyms, previewListSep),
+				Antonyms:     strings.Join(definition.Antonyms, previewListSep),
+			}
+		}
+
+		previewWords[i] = word
+	}
+
+	return previewWords
+}
+
+func preparePreviewItems(candidates []*application.Candidate) []*previewItem {
+	items := make([]*previewItem, len(candidates))
+	for i, candidate := range candidates {
+		items[i] = &previewItem{Selected: candidate.Selected, Value: candidate.Value}
+	}
+
+	return items
+}
+
+// parsePreviewForm reads the preview page form back, only the picked definitions, examples and images are kept.
+// Words are numbered from zero, so the first missing number ends the words.
+func parsePreviewForm(form url.Values) []*application.CardMaterial {
+	materials := make([]*application.CardMaterial, 0)
+
+	for i := 0; form.Has(fmt.Sprintf(previewFieldWord, i)); i++ {
+		word := strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldWord, i)))
+		if word == "" {
+			continue
+		}
+
+		materials = append(materials, &application.CardMaterial{
+			Word:           word,
+			IPA:            strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldIPA, i))),
+			Syllables:      strings.Fields(form.Get(fmt.Sprintf(previewFieldSyllables, i))),
+			AudioURL:       strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldAudioURL, i))),
+			SourceSentence: strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldSource, i))),
+			Tags:           splitPreviewList(form.Get(previewFieldTags)),
+			Definitions:    parsePreviewDefinitions(form, i),
+			Examples:       parsePreviewItems(form, i, previewFieldExample, previewFieldExSelected),
+			Images:         parsePreviewItems(form, i, previewFieldImage, previewFieldImgSelected),
+		})
+	}
+
+	return materials
+}
+
+// parsePreviewImagesShortages reads back the images shortages the look up found, the preview page passes them on.
+func parsePreviewImagesShortages(form url.Values) []*application.ImagesShortage {
+	shortages := make([]*application.ImagesShortage, 0)
+
+	for i := 0; form.Has(fmt.Sprintf(previewFieldWord, i)); i++ {
+		found, err := strconv.Atoi(form.Get(fmt.Sprintf(previewFieldImagesFound, i)))
+		if err != nil {
+			continue
+		}
+
+		shortages = append(shortages, &application.ImagesShortage{
+			Word:  strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldWord, i))),
+			Found: found,
+		})
+	}
+
+	return shortages
+}
+
+func parsePreviewDefinitions(form url.Values, wordIndex int) []*application.Definition {
+	definitions := make([]*application.Definition, 0)
+
+	for j := 0; form.Has(fmt.Sprintf(previewFieldDefGloss, wordIndex, j)); j++ {
+		gloss := strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldDefGloss, wordIndex, j)))
+		if form.Get(fmt.Sprintf(previewFieldDefSelected, wordIndex, j)) != previewCheckboxOn || gloss == "" {
+			continue
+		}
+
+		definitions = append(definitions, &application.Definition{
+			PartOfSpeech: strings.TrimSpace(form.Get(fmt.Sprintf(previewFieldDefPOS, wordIndex, j))),
+			Gloss:        gloss,
+			Synonyms:     splitPreviewList(form.Get(fmt.Sprintf(previewFieldDefSynonyms, wordIndex, j))),
+			Antonyms:     splitPreviewList(form.Get(fmt.Sprintf(previewFieldDefAntonyms, wordIndex, j))),
+		})
+	}
+
+	return definitions
+}
+
+// parsePreviewItems returns the values of the picked items.
+func parsePreviewItems(form url.Values, wordIndex int, valueField, selectedField string) []string {
+	values := make([]string, 0)
+
+	for j := 0; form.Has(fmt.Sprintf(valueField, wordIndex, j)); j++ {
+		value := strings.TrimSpace(form.Get(fmt.Sprintf(valueField, wordIndex, j)))
+		if form.Get(fmt.Sprintf(selectedField, wordIndex, j)) != previewCheckboxOn || value == "" {
+			continue
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// splitPreviewList splits comma separated lists users edit on the preview page.
+func splitPreviewList(list string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
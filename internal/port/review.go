@@ -0,0 +1,144 @@
+package port
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r-erema/vocaboost/internal/domain"
+)
+
+const (
+	reviewFieldWord     = "word"
+	reviewFieldGrade    = "grade"
+	reviewQueryPromoted = "promoted"
+)
+
+var errBadGrade = errors.New("bad grade")
+
+// reviewGrades are the grades offered on the review page in the buttons order.
+var reviewGrades = []struct { //nolint:gochecknoglobals
+	grade domain.Grade
+	label string
+}{
+	{grade: domain.GradeAgain, label: "Again"},
+	{grade: domain.GradeHard, label: "Hard"},
+	{grade: domain.GradeGood, label: "Good"},
+	{grade: domain.GradeEasy, label: "Easy"},
+}
+
+// reviewCard is a due card on the review page. The word, the pronunciation, the images and the clozes are
+// the question, the rest is hidden until the answer is revealed.
+type reviewCard struct {
+	Word           string
+	IPA            string
+	AudioURL       string
+	Images         []string
+	Clozes         []*reviewCloze
+	Translation    string
+	Definitions    []*reviewDefinitions
+	Examples       []string
+	SourceSentence string
+	Grades         []*reviewGrade
+}
+
+type reviewDefinitions struct {
+	PartOfSpeech string
+	Glosses      []string
+}
+
+// reviewCloze is a sentence with the word blanked out, the answers are revealed with the rest of the card.
+type reviewCloze struct {
+	Blanked string
+	Answers string
+}
+
+// reviewGrade is a grade button, the interval is when the card is due again if the grade is picked.
+type reviewGrade struct {
+	Grade    int
+	Label    string
+	Interval string
+}
+
+func prepareReviewCard(card *domain.Card, intervals map[domain.Grade]time.Duration) *reviewCard {
+	word := card.Word()
+
+	preparedCard := &reviewCard{
+		Word:           word.Word(),
+		IPA:            "",
+		AudioURL:       "",
+		Images:         word.ImageURLs(),
+		Clozes:         make([]*reviewCloze, len(word.Clozes())),
+		Translation:    word.Translation(),
+		Definitions:    make([]*reviewDefinitions, 0, len(word.DefinitionsByPartOfSpeech())),
+		Examples:       word.Examples(),
+		SourceSentence: word.SourceSentence(),
+		Grades:         make([]*reviewGrade, len(reviewGrades)),
+	}
+
+	if word.Pronunciation() != nil {
+		preparedCard.IPA = strings.Trim(word.Pronunciation().IPA(), "/")
+		preparedCard.AudioURL = word.Pronunciation().AudioURL()
+	}
+
+	for i, cloze := range word.Clozes() {
+		preparedCard.Clozes[i] = &reviewCloze{Blanked: cloze.Blanked(), Answers: strings.Join(cloze.Answers(), ", ")}
+	}
+
+	for _, group := range word.DefinitionsByPartOfSpeech() {
+		definitions := &reviewDefinitions{
+			PartOfSpeech: group.PartOfSpeech(),
+			Glosses:      make([]string, len(group.Definitions())),
+		}
+
+		for i, definition := range group.Definitions() {
+			definitions.Glosses[i] = definition.Gloss()
+			if definition.Translation() != "" {
+				definitions.Glosses[i] += " — " + definition.Translation()
+			}
+		}
+
+		preparedCard.Definitions = append(preparedCard.Definitions, definitions)
+	}
+
+	for i, grade := range reviewGrades {
+		preparedCard.Grades[i] = &reviewGrade{
+			Grade:    int(grade.grade),
+			Label:    grade.label,
+			Interval: formatReviewInterval(intervals[grade.grade]),
+		}
+	}
+
+	return preparedCard
+}
+
+// formatReviewInterval rounds the interval to minutes, days, months or years.
+func formatReviewInterval(interval time.Duration) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	switch {
+	case interval < day:
+		return fmt.Sprintf("%dm", int(interval.Round(time.Minute).Minutes()))
+	case interval < month:
+		return fmt.Sprintf("%dd", int(interval.Round(day)/day))
+	case interval < year:
+		return fmt.Sprintf("%.1fmo", float64(interval)/float64(month))
+	default:
+		return fmt.Sprintf("%.1fy", float64(interval)/float64(year))
+	}
+}
+
+func parseReviewGrade(value string) (domain.Grade, error) {
+	grade, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || grade < int(domain.GradeAgain) || grade > int(domain.GradeEasy) {
+		return 0, fmt.Errorf("%w `%s`", errBadGrade, value)
+	}
+
+	return domain.Grade(grade), nil
+}